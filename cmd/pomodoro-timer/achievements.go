@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/lutischan-ferenc/systray"
+)
+
+// achievementStats summarizes the history for the achievements.
+type achievementStats struct {
+	pomodoros     int           // Completed Pomodoros of all time
+	bestDay       int           // Most Pomodoros completed in a single day
+	longestStreak int           // Most consecutive days with a completed Pomodoro
+	focus         time.Duration // Time spent in completed Pomodoros of all time
+}
+
+// achievement is a milestone earned from the session history.
+type achievement struct {
+	id          string
+	title       string
+	description string
+	earned      func(achievementStats) bool
+}
+
+var achievements = []achievement{
+	{"first_pomodoro", "First Pomodoro", "Complete your first Pomodoro", func(s achievementStats) bool { return s.pomodoros >= 1 }},
+	{"ten_in_a_day", "10 in a Day", "Complete 10 Pomodoros in a single day", func(s achievementStats) bool { return s.bestDay >= 10 }},
+	{"five_day_streak", "5-Day Streak", "Complete a Pomodoro on 5 days in a row", func(s achievementStats) bool { return s.longestStreak >= 5 }},
+	{"hundred_hours", "100 Hours", "Focus for 100 hours in total", func(s achievementStats) bool { return s.focus >= 100*time.Hour }},
+}
+
+var (
+	achievementsMu     sync.Mutex
+	achievementsEarned map[string]bool // Earned achievements by ID, nil until the history is read; guarded by achievementsMu
+
+	mAchievements     *systray.MenuItem   // Submenu listing the achievements
+	mAchievementItems []*systray.MenuItem // Menu items of the achievements, in the order of achievements
+)
+
+// computeAchievementStats summarizes the completed Pomodoros of the history.
+func computeAchievementStats(records []SessionRecord) achievementStats {
+	var stats achievementStats
+	perDay := map[time.Time]int{}
+	for _, record := range records {
+		if record.Type != sessionPomodoro || !record.Completed {
+			continue
+		}
+		stats.pomodoros++
+		stats.focus += record.End.Sub(record.Start)
+		start := record.Start.Local()
+		day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+		perDay[day]++
+		if perDay[day] > stats.bestDay {
+			stats.bestDay = perDay[day]
+		}
+	}
+	for day := range perDay {
+		if perDay[day.AddDate(0, 0, -1)] > 0 {
+			continue // Not the first day of a streak
+		}
+		streak := 1
+		for perDay[day.AddDate(0, 0, streak)] > 0 {
+			streak++
+		}
+		if streak > stats.longestStreak {
+			stats.longestStreak = streak
+		}
+	}
+	return stats
+}
+
+// earnedAchievements returns the IDs of the achievements earned by the history.
+func earnedAchievements(records []SessionRecord) map[string]bool {
+	stats := computeAchievementStats(records)
+	earned := map[string]bool{}
+	for _, a := range achievements {
+		if a.earned(stats) {
+			earned[a.id] = true
+		}
+	}
+	return earned
+}
+
+// initAchievements reads the achievements already earned from the history,
+// without announcing them.
+func initAchievements() {
+	records, err := loadHistory()
+	if err != nil {
+		slog.Error("Failed to load history", "err", err)
+		return
+	}
+	achievementsMu.Lock()
+	achievementsEarned = earnedAchievements(records)
+	achievementsMu.Unlock()
+	updateAchievementsMenu()
+}
+
+// achievementsPomodoroCompleted checks for new achievements once a completed
+// Pomodoro is in the history. The caller must hold mu, so the history is
+// read in the background.
+func achievementsPomodoroCompleted(sessionInfo) {
+	if !settings.Achievements {
+		return
+	}
+	go checkAchievements()
+}
+
+// checkAchievements announces the achievements earned since the last check.
+func checkAchievements() {
+	records, err := loadHistory()
+	if err != nil {
+		slog.Error("Failed to load history", "err", err)
+		return
+	}
+	earned := earnedAchievements(records)
+
+	achievementsMu.Lock()
+	previous := achievementsEarned
+	achievementsEarned = earned
+	achievementsMu.Unlock()
+	updateAchievementsMenu()
+	if previous == nil {
+		return // Not read at startup, so there is nothing to compare with
+	}
+	for _, a := range achievements {
+		if earned[a.id] && !previous[a.id] {
+			slog.Info("Achievement unlocked", "achievement", a.id)
+			sendNotification(fmt.Sprintf(tr("Achievement unlocked: %s"), tr(a.title)), tr(a.description))
+		}
+	}
+}
+
+// addAchievementsMenu adds the Achievements submenu, listing every
+// achievement with the earned ones checked.
+func addAchievementsMenu() {
+	mAchievements = systray.AddMenuItem(tr("Achievements"), tr("Milestones earned from your Pomodoros"))
+	for _, a := range achievements {
+		item := mAchievements.AddSubMenuItemCheckbox(tr(a.title), tr(a.description), false)
+		item.Disable()
+		mAchievementItems = append(mAchievementItems, item)
+	}
+	updateAchievementsMenu()
+}
+
+// updateAchievementsMenu checks the earned achievements and shows the
+// submenu only while achievements are enabled.
+func updateAchievementsMenu() {
+	if mAchievements == nil {
+		return
+	}
+	if !settings.Achievements {
+		mAchievements.Hide()
+		return
+	}
+	achievementsMu.Lock()
+	defer achievementsMu.Unlock()
+	count := 0
+	for i, a := range achievements {
+		if achievementsEarned[a.id] {
+			count++
+			mAchievementItems[i].Check()
+		} else {
+			mAchievementItems[i].Uncheck()
+		}
+	}
+	mAchievements.SetTitle(fmt.Sprintf(tr("Achievements (%d/%d)"), count, len(achievements)))
+	mAchievements.Show()
+}
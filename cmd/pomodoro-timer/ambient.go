@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand"
+
+	"github.com/lutischan-ferenc/systray"
+	"pomodoro-timer/internal/audio"
+)
+
+// Background sounds played during Pomodoros.
+const (
+	backgroundClock      = "clock"
+	backgroundWhiteNoise = "white_noise"
+	backgroundRain       = "rain"
+	backgroundCafe       = "cafe"
+	backgroundBinaural   = "binaural"
+)
+
+// ambientLoopSeconds is the length of the generated ambient loops.
+const ambientLoopSeconds = 12
+
+// backgroundSound is an entry of the Background Sound submenu.
+type backgroundSound struct {
+	id       string
+	title    string
+	generate func(sampleRate int) []byte       // Generates a loopable track, nil for the clock sound
+	stream   func(sampleRate int) audio.Source // Generates the sound while it plays, instead of generate
+}
+
+var backgroundSounds = []backgroundSound{
+	{backgroundClock, "Clock", nil, nil},
+	{backgroundWhiteNoise, "White Noise", generateWhiteNoise, nil},
+	{backgroundRain, "Rain", generateRain, nil},
+	{backgroundCafe, "Café", generateCafe, nil},
+	{backgroundBinaural, "Binaural Beat", nil, binauralSource},
+}
+
+var (
+	ambientCache = map[string]audio.Sound{} // Generated ambient tracks, guarded by soundsMu
+
+	mBackground      *systray.MenuItem   // Submenu for choosing the background sound
+	mBackgroundOff   *systray.MenuItem   // Menu item for disabling the background sound
+	mBackgroundItems []*systray.MenuItem // Menu items for the background sounds
+)
+
+// backgroundSource returns the selected background sound. Ambient tracks are
+// generated on first use. The caller must hold soundsMu.
+func backgroundSource() audio.Source {
+	for _, sound := range backgroundSounds {
+		if sound.id == settings.BackgroundSound && sound.stream != nil {
+			return sound.stream(audioPlayer.SampleRate())
+		}
+		if sound.id != settings.BackgroundSound || sound.generate == nil {
+			continue
+		}
+		if pcm, ok := ambientCache[sound.id]; ok {
+			return pcm
+		}
+		pcm := audio.Sound(sound.generate(audioPlayer.SampleRate()))
+		ambientCache[sound.id] = pcm
+		return pcm
+	}
+	return clockSound
+}
+
+// addBackgroundSoundMenu adds the submenu for choosing the sound played during Pomodoros.
+func addBackgroundSoundMenu() {
+	mBackground = systray.AddMenuItem(tr("Background Sound"), tr("Sound played during Pomodoros"))
+	mBackgroundOff = mBackground.AddSubMenuItemCheckbox(tr("Off"), tr("No sound during Pomodoros"), false)
+	mBackgroundOff.Click(func() {
+		mu.Lock()
+		settings.EnableClockSound = false
+		saveSettings()
+		mu.Unlock()
+		updateBackgroundSoundMenu()
+	})
+	for _, sound := range backgroundSounds {
+		id := sound.id
+		item := mBackground.AddSubMenuItemCheckbox(tr(sound.title), tr("Play this sound during Pomodoros"), false)
+		item.Click(func() {
+			mu.Lock()
+			settings.EnableClockSound = true
+			settings.BackgroundSound = id
+			saveSettings()
+			mu.Unlock()
+			updateBackgroundSoundMenu()
+		})
+		mBackgroundItems = append(mBackgroundItems, item)
+	}
+	updateBackgroundSoundMenu()
+}
+
+// updateBackgroundSoundMenu refreshes the background sound submenu from the settings.
+func updateBackgroundSoundMenu() {
+	if mBackground == nil {
+		return
+	}
+	selected := ""
+	if settings.EnableClockSound {
+		selected = settings.BackgroundSound
+		if selected == "" {
+			selected = backgroundClock
+		}
+	}
+
+	if selected == "" {
+		mBackgroundOff.Check()
+	} else {
+		mBackgroundOff.Uncheck()
+	}
+	for i, sound := range backgroundSounds {
+		if sound.id == selected {
+			mBackgroundItems[i].Check()
+		} else {
+			mBackgroundItems[i].Uncheck()
+		}
+	}
+}
+
+// noiseTrack builds a stereo track from a per-channel sample generator and
+// crossfades its end into its start so it loops without a seam.
+func noiseTrack(sampleRate int, next func(ch int) float64) []byte {
+	frames := sampleRate * ambientLoopSeconds
+	fade := sampleRate / 2
+	samples := make([][2]float64, frames+fade)
+	for i := range samples {
+		samples[i] = [2]float64{next(0), next(1)}
+	}
+
+	pcm := make([]byte, frames*4)
+	for i := 0; i < frames; i++ {
+		for ch := 0; ch < 2; ch++ {
+			v := samples[i][ch]
+			if i < fade {
+				w := float64(i) / float64(fade)
+				v = v*w + samples[frames+i][ch]*(1-w)
+			}
+			v = math.Max(-1, math.Min(1, v))
+			binary.LittleEndian.PutUint16(pcm[i*4+ch*2:], uint16(int16(v*math.MaxInt16)))
+		}
+	}
+	return pcm
+}
+
+// generateWhiteNoise generates a loop of soft white noise.
+func generateWhiteNoise(sampleRate int) []byte {
+	rng := rand.New(rand.NewSource(1))
+	return noiseTrack(sampleRate, func(ch int) float64 {
+		return (rng.Float64()*2 - 1) * 0.12
+	})
+}
+
+// generateRain generates a loop of pink noise with random droplets, resembling steady rain.
+func generateRain(sampleRate int) []byte {
+	rng := rand.New(rand.NewSource(2))
+	var b [2][3]float64 // Pink noise filter state per channel
+	var drop [2]float64 // Envelope of the current droplet per channel
+	return noiseTrack(sampleRate, func(ch int) float64 {
+		white := rng.Float64()*2 - 1
+		f := &b[ch]
+		f[0] = 0.99765*f[0] + white*0.0990460
+		f[1] = 0.96300*f[1] + white*0.2965164
+		f[2] = 0.57000*f[2] + white*1.0526913
+		pink := (f[0] + f[1] + f[2] + white*0.1848) * 0.05
+
+		if rng.Float64() < 40.0/float64(sampleRate) {
+			drop[ch] = 0.2 + rng.Float64()*0.3
+		}
+		drop[ch] *= 0.995
+		return pink + white*drop[ch]*0.5
+	})
+}
+
+// generateCafe generates a loop of low, slowly swelling murmur with occasional
+// cup clinks, resembling the background of a café.
+func generateCafe(sampleRate int) []byte {
+	rng := rand.New(rand.NewSource(3))
+	var brown [2]float64
+	var clinkAmp [2]float64
+	var clinkFreq [2]float64
+	var t [2]int
+	return noiseTrack(sampleRate, func(ch int) float64 {
+		t[ch]++
+		seconds := float64(t[ch]) / float64(sampleRate)
+
+		// Brown noise with a slow swell sounds like distant chatter
+		brown[ch] = (brown[ch] + (rng.Float64()*2-1)*0.02) * 0.998
+		swell := 0.7 + 0.3*math.Sin(2*math.Pi*seconds/3.7+float64(ch))
+		murmur := brown[ch] * 1.2 * swell
+
+		if rng.Float64() < 0.4/float64(sampleRate) {
+			clinkAmp[ch] = 0.08 + rng.Float64()*0.08
+			clinkFreq[ch] = 2500 + rng.Float64()*1500
+		}
+		clinkAmp[ch] *= 0.9995
+		clink := clinkAmp[ch] * math.Sin(2*math.Pi*clinkFreq[ch]*seconds)
+		return murmur + clink
+	})
+}
+
+// binauralSource returns the binaural beat with the configured carrier and
+// beat frequencies, for listening through headphones.
+func binauralSource(sampleRate int) audio.Source {
+	return audio.BinauralBeat(settings.BinauralCarrierFrequency, settings.BinauralBeatFrequency, 0.3, sampleRate)
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// APISettings configures the local REST API.
+type APISettings struct {
+	Enabled bool   `json:"enabled"` // Serve the REST API and web dashboard
+	Port    int    `json:"port"`    // Port of the REST API
+	Token   string `json:"token"`   // Secret sent as "Authorization: Bearer <token>", generated when empty
+	LAN     bool   `json:"lan"`     // Listen on all network interfaces instead of only 127.0.0.1
+}
+
+var (
+	apiMu     sync.Mutex
+	apiServer *http.Server // Running API server, nil if disabled, guarded by apiMu
+	apiConfig APISettings  // Settings the running server was started with, guarded by apiMu
+)
+
+// updateAPIServer starts, restarts or stops the REST API to match the settings.
+func updateAPIServer() {
+	mu.Lock()
+	if settings.API.Enabled && settings.API.Token == "" {
+		secret := make([]byte, 16)
+		if _, err := rand.Read(secret); err != nil {
+			mu.Unlock()
+			slog.Error("Failed to create API token", "err", err)
+			return
+		}
+		settings.API.Token = hex.EncodeToString(secret)
+		saveSettings()
+	}
+	config := settings.API
+	mu.Unlock()
+
+	apiMu.Lock()
+	defer apiMu.Unlock()
+	if config == apiConfig {
+		return
+	}
+	if apiServer != nil {
+		apiServer.Close()
+		apiServer = nil
+	}
+	apiConfig = config
+	if !apiConfig.Enabled {
+		return
+	}
+
+	host := "127.0.0.1"
+	if apiConfig.LAN {
+		host = ""
+	}
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", host, apiConfig.Port))
+	if err != nil {
+		slog.Error("Failed to start the API server", "err", err)
+		go sendNotification(tr("API not available"), err.Error())
+		return
+	}
+	apiServer = &http.Server{Handler: apiHandler(apiConfig.Token)}
+	go func(server *http.Server) {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			slog.Error("API server stopped", "err", err)
+		}
+	}(apiServer)
+	slog.Info("API listening", "addr", listener.Addr())
+}
+
+// apiHandler returns the handler of the REST API, which requires token.
+func apiHandler(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		writeAPIStatus(w, runControlCommand([]string{"status"}))
+	})
+	for pattern, command := range map[string][]string{
+		"POST /pomodoro/start": {"start", sessionPomodoro},
+		"POST /break/start":    {"start", sessionBreak},
+		"POST /stop":           {"stop"},
+		"POST /pause":          {"pause"},
+		"POST /resume":         {"resume"},
+		"POST /skip":           {"skip"},
+	} {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			writeAPIStatus(w, runControlCommand(command))
+		})
+	}
+	mux.HandleFunc("GET /history", handleAPIHistory)
+	mux.HandleFunc("GET /events", handleAPIEvents)
+	mux.HandleFunc("GET /stats/today", handleAPIStatsToday)
+	mux.HandleFunc("GET /metrics", handleAPIMetrics)
+	mux.HandleFunc("GET /image.png", handleAPIImage)
+	mux.HandleFunc("POST /toggle", handleAPIToggle)
+	mux.HandleFunc("POST /pause/toggle", handleAPIPauseToggle)
+	mux.HandleFunc("GET /{$}", handleDashboard)
+	mux.HandleFunc("/settings", func(w http.ResponseWriter, r *http.Request) {
+		serveSettingsForm(w, r, token)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if given == "" {
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == "/":
+				// The dashboard is opened as a link, which cannot carry a header;
+				// its calls send the token as one.
+				given = r.URL.Query().Get("token")
+			case r.Method == http.MethodPost && r.URL.Path == "/settings":
+				// The settings form sends the token as a field.
+				given = r.PostFormValue("token")
+			}
+		}
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			writeAPIJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// writeAPIJSON writes v as a JSON response.
+func writeAPIJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeAPIStatus writes the timer status, with 409 Conflict if the command failed.
+func writeAPIStatus(w http.ResponseWriter, status timerStatus) {
+	code := http.StatusOK
+	if status.Error != "" {
+		code = http.StatusConflict
+	}
+	writeAPIJSON(w, code, status)
+}
+
+// handleAPIHistory returns the session history, oldest first. The optional
+// "since" parameter (RFC 3339) skips older sessions and "limit" returns only
+// the most recent ones.
+func handleAPIHistory(w http.ResponseWriter, r *http.Request) {
+	records, err := loadHistory()
+	if err != nil {
+		writeAPIJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	if since := r.URL.Query().Get("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			writeAPIJSON(w, http.StatusBadRequest, map[string]string{"error": "since must be an RFC 3339 time"})
+			return
+		}
+		var recent []SessionRecord
+		for _, record := range records {
+			if !record.Start.Before(t) {
+				recent = append(recent, record)
+			}
+		}
+		records = recent
+	}
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			writeAPIJSON(w, http.StatusBadRequest, map[string]string{"error": "limit must be a positive number"})
+			return
+		}
+		if n < len(records) {
+			records = records[len(records)-n:]
+		}
+	}
+	if records == nil {
+		records = []SessionRecord{}
+	}
+	writeAPIJSON(w, http.StatusOK, records)
+}
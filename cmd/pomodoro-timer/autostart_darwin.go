@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	autoStartSupported = true
+	launchAgentLabel   = "org.pomodorotimer" // Label of the LaunchAgent starting the app on login
+)
+
+// getAutoStartPath returns the path of the LaunchAgent starting the app on login.
+func getAutoStartPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", launchAgentLabel+".plist"), nil
+}
+
+// launchAgentPlist returns the LaunchAgent property list running exePath on login.
+func launchAgentPlist(exePath string) string {
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(exePath))
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>%s</string>
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>ProcessType</key>
+	<string>Interactive</string>
+</dict>
+</plist>
+`, launchAgentLabel, escaped.String())
+}
+
+// setAutoStart writes or removes the LaunchAgent. launchd reads it at the
+// next login, so there is no need to load it now and start a second instance.
+func setAutoStart(enable bool) error {
+	path, err := getAutoStartPath()
+	if err != nil {
+		return err
+	}
+	if !enable {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(launchAgentPlist(exePath)), 0644)
+}
+
+// isAutoStartEnabled checks if the LaunchAgent starts the current executable.
+func isAutoStartEnabled() bool {
+	path, err := getAutoStartPath()
+	if err != nil {
+		return false
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	exePath, err := os.Executable()
+	if err != nil {
+		slog.Error("Failed to get executable path", "err", err)
+		return false
+	}
+	return string(data) == launchAgentPlist(exePath)
+}
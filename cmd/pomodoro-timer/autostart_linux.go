@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const autoStartSupported = true
+
+// getAutoStartPath returns the path of the desktop entry starting the app on login.
+func getAutoStartPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "autostart", "pomodoro-timer.desktop"), nil
+}
+
+// autoStartExec returns the Exec line of the auto-start desktop entry.
+func autoStartExec(exePath string) string {
+	return fmt.Sprintf("Exec=\"%s\"", exePath)
+}
+
+// setAutoStart writes or removes the auto-start desktop entry.
+func setAutoStart(enable bool) error {
+	path, err := getAutoStartPath()
+	if err != nil {
+		return err
+	}
+	if !enable {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %v", err)
+	}
+	entry := fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name=Pomodoro Timer
+Comment=Pomodoro timer in the system tray
+%s
+Terminal=false
+X-GNOME-Autostart-enabled=true
+`, autoStartExec(exePath))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(entry), 0644)
+}
+
+// isAutoStartEnabled checks if the auto-start desktop entry starts the current executable.
+func isAutoStartEnabled() bool {
+	path, err := getAutoStartPath()
+	if err != nil {
+		return false
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	exePath, err := os.Executable()
+	if err != nil {
+		slog.Error("Failed to get executable path", "err", err)
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == autoStartExec(exePath) {
+			return true
+		}
+	}
+	return false
+}
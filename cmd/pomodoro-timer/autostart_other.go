@@ -0,0 +1,17 @@
+//go:build !windows && !darwin && !linux
+
+package main
+
+import "errors"
+
+const autoStartSupported = false
+
+// setAutoStart is not supported on this platform.
+func setAutoStart(enable bool) error {
+	return errors.New("auto-start is not supported on this platform")
+}
+
+// isAutoStartEnabled reports false, as auto-start is not supported on this platform.
+func isAutoStartEnabled() bool {
+	return false
+}
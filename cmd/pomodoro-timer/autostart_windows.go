@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	AUTO_START_NAME    = "PomodoroTimer"
+	autoStartSupported = true
+)
+
+// setAutoStart sets or removes the application from the Windows startup registry.
+func setAutoStart(enable bool) error {
+	// Get the path to the current executable
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %v", err)
+	}
+
+	// Open the registry key for auto-start programs
+	key, err := registry.OpenKey(registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Run`, registry.SET_VALUE|registry.QUERY_VALUE)
+	if err != nil {
+		return fmt.Errorf("failed to open registry key: %v", err)
+	}
+	defer key.Close()
+
+	// Set or remove the auto-start entry
+	if enable {
+		if err := key.SetStringValue(AUTO_START_NAME, exePath); err != nil {
+			return fmt.Errorf("failed to set registry value: %v", err)
+		}
+	} else {
+		if err := key.DeleteValue(AUTO_START_NAME); err != nil && err != registry.ErrNotExist {
+			return fmt.Errorf("failed to delete registry value: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// isAutoStartEnabled checks if the application is set to auto-start in the Windows registry.
+func isAutoStartEnabled() bool {
+	// Get the path to the current executable
+	exePath, err := os.Executable()
+	if err != nil {
+		slog.Error("Failed to get executable path", "err", err)
+		return false
+	}
+
+	// Open the registry key for auto-start programs
+	key, err := registry.OpenKey(registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Run`, registry.QUERY_VALUE)
+	if err != nil {
+		slog.Error("Failed to open registry key", "err", err)
+		return false
+	}
+	defer key.Close()
+
+	// Check if the registry value exists and matches the current executable path
+	value, _, err := key.GetStringValue(AUTO_START_NAME)
+	if err != nil {
+		if err == registry.ErrNotExist {
+			return false
+		}
+		slog.Error("Failed to read registry value", "err", err)
+		return false
+	}
+
+	return value == exePath
+}
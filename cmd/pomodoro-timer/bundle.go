@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const bundleVersion = 1 // Version of the settings bundle format
+
+// settingsBundle is an exported configuration: the settings including the
+// profiles, and the task list.
+type settingsBundle struct {
+	Version  int             `json:"version"`  // Format version, see bundleVersion
+	Exported time.Time       `json:"exported"` // When the bundle was written
+	Settings json.RawMessage `json:"settings"` // Contents of the settings file
+	Tasks    *TaskList       `json:"tasks"`    // Task list, nil if not exported
+}
+
+// exportPath returns the path the settings bundle is exported to.
+func exportPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+	return filepath.Join(home, "pomodoro-timer-settings-"+time.Now().Format("2006-01-02")+".json")
+}
+
+// exportSettings writes the settings and tasks to a bundle in the home
+// directory and notifies where it is.
+func exportSettings() {
+	mu.Lock()
+	saved := settingsToSave()
+	mu.Unlock()
+	settingsData, err := json.Marshal(saved)
+	if err != nil {
+		slog.Error("Failed to export settings", "err", err)
+		return
+	}
+	tasksMu.Lock()
+	taskList := tasks
+	tasksMu.Unlock()
+
+	data, err := json.MarshalIndent(settingsBundle{
+		Version:  bundleVersion,
+		Exported: time.Now(),
+		Settings: settingsData,
+		Tasks:    &taskList,
+	}, "", "  ")
+	if err != nil {
+		slog.Error("Failed to export settings", "err", err)
+		return
+	}
+
+	path := exportPath()
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		slog.Error("Failed to write settings bundle", "err", err)
+		sendNotification(tr("Export failed"), err.Error())
+		return
+	}
+	slog.Info("Exported settings", "path", path)
+	sendNotification(tr("Settings exported"), path)
+}
+
+// openImportSettings asks for the path of a bundle in the default text editor
+// and imports it.
+func openImportSettings() {
+	prompt := "# Path of the settings bundle to import. The current settings and tasks\n" +
+		"# are replaced.\n" + exportPath() + "\n"
+	data, err := editInEditor("pomodoro_import_*.txt", []byte(prompt))
+	if err != nil {
+		slog.Error("Failed to import settings", "err", err)
+		return
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.Trim(strings.TrimSpace(line), `"`)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			if err := importSettings(line); err != nil {
+				slog.Error("Failed to import settings", "err", err)
+				sendNotification(tr("Import failed"), err.Error())
+				return
+			}
+			sendNotification(tr("Settings imported"), line)
+			return
+		}
+	}
+}
+
+// importSettings replaces the settings and tasks with the ones in the bundle
+// at path. Invalid settings are replaced with defaults and reported.
+func importSettings(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var bundle settingsBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return fmt.Errorf("%s is not a settings bundle: %v", path, err)
+	}
+	if bundle.Version < 1 || bundle.Version > bundleVersion || bundle.Settings == nil {
+		return fmt.Errorf("%s is not a supported settings bundle", path)
+	}
+	newSettings, problems, err := parseSettings(bundle.Settings)
+	if err != nil {
+		return err
+	}
+
+	if bundle.Tasks != nil {
+		tasksMu.Lock()
+		tasks = *bundle.Tasks
+		saveTasks()
+		tasksMu.Unlock()
+		updateTaskMenu()
+	}
+	applyLaunchFlags(&newSettings)
+	applySettings(newSettings)
+	reportSettingsProblems(problems)
+	return nil
+}
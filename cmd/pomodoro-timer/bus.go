@@ -0,0 +1,162 @@
+package main
+
+import (
+	"time"
+
+	"pomodoro-timer/pkg/pomodoro"
+)
+
+// sessionEvent is a session lifecycle event. Unlike the timer events streamed
+// to other apps (see events.go), these are delivered synchronously to the
+// parts of the app reacting to sessions.
+type sessionEvent int
+
+// Session lifecycle events.
+const (
+	sessionStarted   sessionEvent = iota // A Pomodoro or break started
+	sessionTick                          // A second of the running session passed, unless paused
+	sessionCompleted                     // The running session ran out
+	sessionAborted                       // The running session was stopped early
+)
+
+// sessionInfo describes the session of a lifecycle event.
+type sessionInfo struct {
+	timer    pomodoro.Session // Kind, length and time left of the session
+	record   *SessionRecord   // History record of the session; ended for completed and aborted sessions
+	previous time.Duration    // Time left at the previous tick; ticks may skip seconds when late
+}
+
+// crossed reports whether the time left passed the given threshold since
+// the previous tick.
+func (s sessionInfo) crossed(threshold time.Duration) bool {
+	return s.previous > threshold && s.timer.Remaining <= threshold
+}
+
+// pomodoro reports whether the session is a Pomodoro.
+func (s sessionInfo) pomodoro() bool {
+	return s.timer.Kind == pomodoro.Pomodoro
+}
+
+var sessionHandlers = map[sessionEvent][]func(sessionInfo){} // Handlers by event, in subscription order
+
+// subscribe calls handler on every event of the given type.
+func subscribe(event sessionEvent, handler func(sessionInfo)) {
+	sessionHandlers[event] = append(sessionHandlers[event], handler)
+}
+
+// publish calls the handlers of the event in the order they subscribed.
+// The caller must hold mu, which the handlers run with.
+func publish(event sessionEvent, s sessionInfo) {
+	for _, handler := range sessionHandlers[event] {
+		handler(s)
+	}
+}
+
+// onPomodoro wraps handler to only be called for Pomodoros.
+func onPomodoro(handler func(sessionInfo)) func(sessionInfo) {
+	return func(s sessionInfo) {
+		if s.pomodoro() {
+			handler(s)
+		}
+	}
+}
+
+// onEnd subscribes handler to both completed and aborted sessions.
+func onEnd(handler func(sessionInfo)) {
+	subscribe(sessionCompleted, handler)
+	subscribe(sessionAborted, handler)
+}
+
+// init subscribes the parts of the app to the session lifecycle. The order
+// matters: the tray and sounds react first, then the integrations, and the
+// hooks and streams reporting to other apps last, so they see the final state.
+func init() {
+	// Tray, sounds and notifications
+	subscribe(sessionStarted, meetingSessionStarted)
+	subscribe(sessionStarted, meetingModeSessionChanged)
+	subscribe(sessionAborted, meetingModeSessionChanged)
+	subscribe(sessionStarted, func(sessionInfo) { stopBreakReminders() })
+	subscribe(sessionStarted, func(sessionInfo) { acknowledgeAlarm() })
+	subscribe(sessionStarted, func(sessionInfo) { stopIconFlash() })
+	subscribe(sessionStarted, traySessionStarted)
+	subscribe(sessionStarted, clockSoundSessionStarted)
+	subscribe(sessionStarted, eyeRestSessionStarted)
+	subscribe(sessionStarted, onPomodoro(recentSessionStarted))
+	subscribe(sessionTick, meetingTick)
+	subscribe(sessionTick, onPomodoro(distractionTick))
+	subscribe(sessionTick, finalCountdown)
+	subscribe(sessionTick, onPomodoro(preEndWarningTick))
+	subscribe(sessionTick, onPomodoro(intervalChimeTick))
+	subscribe(sessionTick, onPomodoro(eyeRestTick))
+	subscribe(sessionTick, trayTick)
+	onEnd(func(sessionInfo) { stopClockSound() })
+	subscribe(sessionCompleted, onPomodoro(tasksPomodoroCompleted))
+	subscribe(sessionCompleted, onPomodoro(todayPomodoroCompleted))
+	subscribe(sessionCompleted, onPomodoro(planPomodoroCompleted))
+	subscribe(sessionCompleted, onPomodoro(achievementsPomodoroCompleted))
+	subscribe(sessionCompleted, func(s sessionInfo) {
+		if !s.pomodoro() {
+			startBreakReminders()
+		}
+	})
+	onEnd(traySessionEnded)
+	subscribe(sessionCompleted, func(sessionInfo) { startIconFlash() })
+	subscribe(sessionCompleted, func(s sessionInfo) {
+		notifySessionFinished(s.pomodoro())
+		go playEndSound(s.pomodoro())
+	})
+
+	// Integrations
+	subscribe(sessionStarted, func(s sessionInfo) { mediaSessionStarted(s.record.Type) })
+	subscribe(sessionStarted, onPomodoro(func(s sessionInfo) {
+		slackFocusStarted(s.timer.Duration)
+		focusAssistStarted()
+		desktopDNDStarted()
+		teamsFocusStarted(s.timer.Duration)
+		googleCalendarStarted(*s.record)
+		togglStarted(*s.record)
+	}))
+	subscribe(sessionStarted, onPomodoro(jiraSessionStarted))
+	subscribe(sessionCompleted, onPomodoro(jiraSessionCompleted))
+	subscribe(sessionCompleted, func(s sessionInfo) { influxSessionEnded(*s.record) })
+	onEnd(onPomodoro(func(s sessionInfo) {
+		slackFocusEnded()
+		focusAssistEnded()
+		desktopDNDEnded()
+		teamsFocusEnded()
+		googleCalendarEnded(*s.record)
+		togglEnded()
+		clockifyLogged(*s.record)
+		logDailyNote(*s.record)
+		logOrgClock(*s.record)
+		githubPomodoroCompleted(*s.record)
+	}))
+
+	// Hooks and streams
+	for _, event := range []sessionEvent{sessionStarted, sessionCompleted, sessionAborted} {
+		subscribe(event, func(s sessionInfo) {
+			name := hookEventName(event, s)
+			triggerWebhooks(name, s.record)
+			runHook(name, s.record)
+			telegramSessionEvent(name, s.record)
+			publishTimerEvent(name)
+		})
+	}
+	subscribe(sessionTick, func(sessionInfo) { publishTimerEvent(eventTick) })
+}
+
+// hookEventName returns the name of a lifecycle event in the webhooks, hooks
+// and event streams.
+func hookEventName(event sessionEvent, s sessionInfo) string {
+	switch {
+	case event == sessionAborted:
+		return eventStop
+	case event == sessionStarted && s.pomodoro():
+		return eventPomodoroStart
+	case event == sessionStarted:
+		return eventBreakStart
+	case s.pomodoro():
+		return eventPomodoroEnd
+	}
+	return eventBreakEnd
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"pomodoro-timer/pkg/pomodoro"
+)
+
+func TestSessionInfoCrossed(t *testing.T) {
+	tests := []struct {
+		previous, remaining time.Duration
+		want                bool
+	}{
+		{61 * time.Second, 60 * time.Second, true},
+		{62 * time.Second, 59 * time.Second, true}, // A late tick skipped the minute
+		{60 * time.Second, 59 * time.Second, false},
+		{63 * time.Second, 61 * time.Second, false},
+	}
+	for _, test := range tests {
+		s := sessionInfo{timer: pomodoro.Session{Remaining: test.remaining}, previous: test.previous}
+		if got := s.crossed(time.Minute); got != test.want {
+			t.Errorf("crossed(1m) from %v to %v = %v, want %v", test.previous, test.remaining, got, test.want)
+		}
+	}
+}
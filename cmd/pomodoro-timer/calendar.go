@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apognu/gocal"
+)
+
+// CalendarSettings configures the warnings about meetings a Pomodoro would run into.
+type CalendarSettings struct {
+	ICSURLs    []string `json:"ics_urls"`    // iCalendar feeds, e.g. the published address of an Outlook calendar or the secret address of a Google one
+	Google     bool     `json:"google"`      // Also read the connected Google Calendar
+	AutoFit    bool     `json:"auto_fit"`    // Shorten a Pomodoro to end when the next meeting starts
+	MinMinutes int      `json:"min_minutes"` // Shortest Pomodoro auto_fit starts, 1-60
+}
+
+// calendarEvent is an upcoming meeting.
+type calendarEvent struct {
+	title string
+	start time.Time
+}
+
+const (
+	calendarRefreshInterval = 5 * time.Minute // How often the calendars are read
+	calendarLookahead       = 12 * time.Hour  // How far ahead meetings are read
+)
+
+var (
+	calendarMu       sync.Mutex
+	upcomingMeetings []calendarEvent // Meetings starting in the lookahead, sorted by start, guarded by calendarMu
+
+	calendarClient = &http.Client{Timeout: 30 * time.Second}
+)
+
+// validateCalendar checks the calendar settings, returning the problems found.
+func validateCalendar(c *CalendarSettings) []string {
+	if c.MinMinutes < 1 || c.MinMinutes > 60 {
+		def := defaultSettings().Calendar.MinMinutes
+		problem := fmt.Sprintf("calendar.min_minutes: %d is not between 1 and 60; using %d", c.MinMinutes, def)
+		c.MinMinutes = def
+		return []string{problem}
+	}
+	return nil
+}
+
+// watchCalendar keeps the upcoming meetings up to date.
+func watchCalendar() {
+	for {
+		refreshMeetings()
+		time.Sleep(calendarRefreshInterval)
+	}
+}
+
+// refreshMeetings reads the upcoming meetings from the configured calendars.
+// A calendar that cannot be read keeps no meetings rather than stale ones.
+func refreshMeetings() {
+	config := settings.Calendar
+	now := time.Now()
+	until := now.Add(calendarLookahead)
+
+	var meetings []calendarEvent
+	for _, feed := range config.ICSURLs {
+		events, err := readICSFeed(feed, now, until)
+		if err != nil {
+			slog.Error("Failed to read calendar", "err", err)
+			continue
+		}
+		meetings = append(meetings, events...)
+	}
+	if config.Google {
+		events, err := readGoogleCalendar(now, until)
+		if err != nil {
+			slog.Error("Failed to read Google Calendar", "err", err)
+		}
+		meetings = append(meetings, events...)
+	}
+	sort.Slice(meetings, func(i, j int) bool { return meetings[i].start.Before(meetings[j].start) })
+
+	calendarMu.Lock()
+	upcomingMeetings = meetings
+	calendarMu.Unlock()
+}
+
+// readICSFeed returns the timed events of an iCalendar feed starting between
+// from and until, expanding recurring events. All-day and cancelled events
+// are skipped.
+func readICSFeed(feed string, from, until time.Time) ([]calendarEvent, error) {
+	// Calendar apps share feeds as webcal:// links, which are plain HTTPS.
+	if strings.HasPrefix(feed, "webcal://") {
+		feed = "https://" + strings.TrimPrefix(feed, "webcal://")
+	}
+	resp, err := calendarClient.Get(feed)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", feed, resp.Status)
+	}
+
+	parser := gocal.NewParser(resp.Body)
+	parser.Start, parser.End = &from, &until
+	// Skip malformed events instead of the whole feed, as calendar apps are not strict.
+	parser.Strict.Mode = gocal.StrictModeFailEvent
+	if err := parser.Parse(); err != nil {
+		return nil, err
+	}
+	var events []calendarEvent
+	for _, e := range parser.Events {
+		if e.Start == nil || e.RawStart.Params["VALUE"] == "DATE" || strings.EqualFold(e.Status, "CANCELLED") {
+			continue
+		}
+		if e.Start.After(from) && e.Start.Before(until) {
+			events = append(events, calendarEvent{title: e.Summary, start: *e.Start})
+		}
+	}
+	return events, nil
+}
+
+// readGoogleCalendar returns the timed events of the connected Google
+// Calendar starting between from and until.
+func readGoogleCalendar(from, until time.Time) ([]calendarEvent, error) {
+	client := googleClient()
+	if client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	query := url.Values{
+		"timeMin":      {from.Format(time.RFC3339)},
+		"timeMax":      {until.Format(time.RFC3339)},
+		"singleEvents": {"true"},
+		"orderBy":      {"startTime"},
+	}
+	var list struct {
+		Items []struct {
+			Summary      string `json:"summary"`
+			Transparency string `json:"transparency"`
+			Start        struct {
+				DateTime time.Time `json:"dateTime"`
+			} `json:"start"`
+		} `json:"items"`
+	}
+	if err := googleCall(client, http.MethodGet, "/events?"+query.Encode(), nil, &list); err != nil {
+		return nil, err
+	}
+	var events []calendarEvent
+	for _, item := range list.Items {
+		// All-day events have a date instead of a dateTime; free time does not block.
+		if item.Start.DateTime.IsZero() || item.Transparency == "transparent" || item.Start.DateTime.Before(from) {
+			continue
+		}
+		events = append(events, calendarEvent{title: item.Summary, start: item.Start.DateTime})
+	}
+	return events, nil
+}
+
+// fitToCalendar returns the duration of a Pomodoro starting now, warning if
+// a meeting starts before it ends. With auto_fit the Pomodoro is shortened
+// to end when the meeting starts, unless that leaves less than min_minutes.
+// The caller must hold mu.
+func fitToCalendar(duration time.Duration) time.Duration {
+	now := time.Now()
+	calendarMu.Lock()
+	var next *calendarEvent
+	for i := range upcomingMeetings {
+		if upcomingMeetings[i].start.After(now) {
+			next = &upcomingMeetings[i]
+			break
+		}
+	}
+	calendarMu.Unlock()
+	if next == nil || next.start.Sub(now) >= duration {
+		return duration
+	}
+
+	left := next.start.Sub(now).Truncate(time.Minute)
+	title := fmt.Sprintf(tr("Meeting in %d minutes"), int(left.Minutes()))
+	if next.title != "" {
+		title += ": " + next.title
+	}
+	config := settings.Calendar
+	if config.AutoFit && left >= time.Duration(config.MinMinutes)*time.Minute {
+		go sendNotification(title, fmt.Sprintf(tr("Pomodoro shortened to %d minutes to end before it"), int(left.Minutes())))
+		return left
+	}
+	go sendNotification(title, tr("This Pomodoro runs into it, consider a shorter session"))
+	return duration
+}
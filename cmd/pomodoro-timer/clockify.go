@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// ClockifySettings configures the Clockify time entries.
+type ClockifySettings struct {
+	APIKey      string            `json:"api_key"`      // API key from the Clockify profile settings
+	WorkspaceID string            `json:"workspace_id"` // Workspace the entries are created in
+	ProjectID   string            `json:"project_id"`   // Project of the entries, empty for none
+	TaskID      string            `json:"task_id"`      // Task of the project the entries are logged against, empty for none
+	Projects    map[string]string `json:"projects"`     // Project of the entries by task or tag, overriding project_id and task_id
+	Description string            `json:"description"`  // Description of entries without a task
+	Billable    bool              `json:"billable"`     // Mark the entries as billable
+}
+
+// clockifyEntry is a time entry waiting to be sent to Clockify.
+type clockifyEntry struct {
+	WorkspaceID string    `json:"workspace_id"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Description string    `json:"description"`
+	ProjectID   string    `json:"project_id,omitempty"`
+	TaskID      string    `json:"task_id,omitempty"`
+	Billable    bool      `json:"billable"`
+}
+
+const (
+	clockifyAPIURL        = "https://api.clockify.me/api/v1"
+	clockifyRetryInterval = 5 * time.Minute // How often unsent entries are retried
+)
+
+var (
+	clockifyClient  = &http.Client{Timeout: 15 * time.Second}
+	clockifyMu      sync.Mutex      // Guards clockifyPending and its file
+	clockifyPending []clockifyEntry // Entries not sent yet, oldest first
+	clockifySendMu  sync.Mutex      // Makes sure entries are sent by one goroutine at a time
+)
+
+// getClockifyQueuePath returns the path to the entries waiting to be sent to Clockify.
+func getClockifyQueuePath() string {
+	return getDataFilePath("clockify_queue.json")
+}
+
+// loadClockifyQueue loads the entries left unsent when the app last exited.
+func loadClockifyQueue() {
+	data, err := ioutil.ReadFile(getClockifyQueuePath())
+	if err != nil {
+		return
+	}
+	clockifyMu.Lock()
+	defer clockifyMu.Unlock()
+	if err := json.Unmarshal(data, &clockifyPending); err != nil {
+		slog.Error("Failed to load Clockify queue", "err", err)
+	}
+}
+
+// saveClockifyQueue stores the unsent entries, removing the file when there
+// are none. The caller must hold clockifyMu.
+func saveClockifyQueue() {
+	if len(clockifyPending) == 0 {
+		os.Remove(getClockifyQueuePath())
+		return
+	}
+	data, err := json.MarshalIndent(clockifyPending, "", "  ")
+	if err != nil {
+		slog.Error("Failed to save Clockify queue", "err", err)
+		return
+	}
+	if err := ioutil.WriteFile(getClockifyQueuePath(), data, 0644); err != nil {
+		slog.Error("Failed to write Clockify queue", "err", err)
+	}
+}
+
+// retryClockify sends the queued entries at startup and then periodically,
+// so Pomodoros completed while offline reach Clockify once back online.
+func retryClockify() {
+	loadClockifyQueue()
+	for {
+		sendClockifyEntries()
+		time.Sleep(clockifyRetryInterval)
+	}
+}
+
+// clockifyLogged queues a completed Pomodoro as a Clockify time entry and sends it.
+func clockifyLogged(record SessionRecord) {
+	clockify := settings.Clockify
+	if clockify.APIKey == "" || clockify.WorkspaceID == "" || !record.Completed {
+		return
+	}
+	entry := clockifyEntry{
+		WorkspaceID: clockify.WorkspaceID,
+		Start:       record.Start,
+		End:         record.End,
+		Description: record.Task,
+		ProjectID:   clockify.ProjectID,
+		TaskID:      clockify.TaskID,
+		Billable:    clockify.Billable,
+	}
+	if entry.Description == "" {
+		entry.Description = clockify.Description
+	}
+	for _, key := range []string{record.Task, record.Tag} {
+		if project, ok := clockify.Projects[key]; ok && key != "" {
+			entry.ProjectID, entry.TaskID = project, ""
+			break
+		}
+	}
+
+	clockifyMu.Lock()
+	clockifyPending = append(clockifyPending, entry)
+	saveClockifyQueue()
+	clockifyMu.Unlock()
+	go sendClockifyEntries()
+}
+
+// sendClockifyEntries sends the queued entries in order. It stops at the
+// first entry that fails to send, keeping it and the rest for the next try;
+// entries Clockify rejects are dropped, as resending would not help.
+func sendClockifyEntries() {
+	clockifySendMu.Lock()
+	defer clockifySendMu.Unlock()
+	for {
+		apiKey := settings.Clockify.APIKey
+		clockifyMu.Lock()
+		if len(clockifyPending) == 0 || apiKey == "" {
+			clockifyMu.Unlock()
+			return
+		}
+		entry := clockifyPending[0]
+		clockifyMu.Unlock()
+
+		status, err := clockifyCreateEntry(apiKey, entry)
+		if err != nil && (status == 0 || status == http.StatusTooManyRequests || status >= 500) {
+			slog.Error("Failed to send Clockify time entry, retrying later", "err", err)
+			return
+		}
+		if err != nil {
+			slog.Error("Clockify rejected time entry", "err", err)
+		}
+
+		clockifyMu.Lock()
+		clockifyPending = clockifyPending[1:]
+		saveClockifyQueue()
+		clockifyMu.Unlock()
+	}
+}
+
+// clockifyCreateEntry creates a time entry in Clockify, returning the HTTP
+// status, or 0 if Clockify could not be reached.
+func clockifyCreateEntry(apiKey string, entry clockifyEntry) (int, error) {
+	payload := map[string]interface{}{
+		"start":       entry.Start.UTC().Format(time.RFC3339),
+		"end":         entry.End.UTC().Format(time.RFC3339),
+		"description": entry.Description,
+		"billable":    entry.Billable,
+	}
+	if entry.ProjectID != "" {
+		payload["projectId"] = entry.ProjectID
+	}
+	if entry.TaskID != "" {
+		payload["taskId"] = entry.TaskID
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequest(http.MethodPost, clockifyAPIURL+"/workspaces/"+entry.WorkspaceID+"/time-entries", bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("X-Api-Key", apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := clockifyClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+	return resp.StatusCode, nil
+}
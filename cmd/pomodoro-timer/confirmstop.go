@@ -0,0 +1,53 @@
+package main
+
+import "time"
+
+// confirmStopWindow is how long a second click on the tray icon confirms
+// stopping the running Pomodoro.
+const confirmStopWindow = 3 * time.Second
+
+// Actions offered on the notification asking to confirm stopping a Pomodoro.
+const (
+	actionConfirmStop = "confirm_stop"
+	actionCancelStop  = "cancel_stop"
+)
+
+var (
+	stopRequested    time.Time // Time of the click asking to stop the running Pomodoro, guarded by mu
+	stopRequestStart time.Time // Start of the Pomodoro the click asked to stop, guarded by mu
+)
+
+// confirmTrayStop reports whether a click on the tray icon may stop the
+// running session. With confirm_stop, the first click on a running Pomodoro
+// only asks for confirmation: a second click within confirmStopWindow, or
+// the Stop button of the notification, stops it.
+func confirmTrayStop() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	if !settings.ConfirmStop || !engine.Running() || !engine.InPomodoro() {
+		return true
+	}
+	now := time.Now()
+	start := engine.Session().Start
+	if stopRequestStart.Equal(start) && now.Sub(stopRequested) <= confirmStopWindow {
+		stopRequested = time.Time{}
+		return true
+	}
+	stopRequested, stopRequestStart = now, start
+	go playTickSound()
+	go sendActionNotification(tr("Stop the Pomodoro?"), tr("Click the icon again within 3 seconds, or choose Stop"), []notificationAction{
+		{actionConfirmStop, tr("Stop")},
+		{actionCancelStop, tr("Keep Going")},
+	})
+	return false
+}
+
+// confirmStop stops the Pomodoro confirmed on the notification, unless
+// another session has started since.
+func confirmStop() {
+	mu.Lock()
+	defer mu.Unlock()
+	if engine.Running() && engine.Session().Start.Equal(stopRequestStart) {
+		stopTimer()
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !windows
+
+package main
+
+// attachParentConsole is only needed for the Windows GUI executable.
+func attachParentConsole() {}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"time"
+)
+
+// controlCommands are the subcommands sent to the running app.
+var controlCommands = map[string]bool{
+	"start": true, "stop": true, "pause": true, "resume": true, "skip": true, "status": true, "quit": true,
+}
+
+// timerStatus is the machine-readable state of the timer.
+type timerStatus struct {
+	State            string `json:"state"`                    // "running", "paused" or "stopped"
+	Phase            string `json:"phase"`                    // "pomodoro" or "break": the running one, or the last one while stopped
+	RemainingSeconds int    `json:"remaining_seconds"`        // Time left in the running session
+	DurationSeconds  int    `json:"duration_seconds"`         // Length of the running session
+	StartedAt        string `json:"started_at,omitempty"`     // Start of the running session, RFC 3339
+	EndsAt           string `json:"ends_at,omitempty"`        // Expected end of the running session unless paused, RFC 3339
+	PausedSeconds    int    `json:"paused_seconds,omitempty"` // Time the running session was paused
+	PomodoroCount    int    `json:"pomodoro_count"`           // Pomodoros completed in the current cycle of four
+	Task             string `json:"task,omitempty"`           // Task of the running session
+	Tag              string `json:"tag,omitempty"`            // Tag of the running session
+	Profile          string `json:"profile,omitempty"`        // Active settings profile
+	Error            string `json:"error,omitempty"`          // Why the command failed
+}
+
+// currentTimerStatus returns the state of the timer. The caller must hold mu.
+func currentTimerStatus() timerStatus {
+	status := timerStatus{
+		State:         "stopped",
+		Phase:         sessionBreak,
+		PomodoroCount: engine.Count(),
+		Profile:       settings.Profile,
+	}
+	if engine.InPomodoro() {
+		status.Phase = sessionPomodoro
+	}
+	if !engine.Running() {
+		return status
+	}
+	status.State = "running"
+	if engine.Paused() {
+		status.State = "paused"
+	}
+	status.RemainingSeconds = int(engine.Remaining().Seconds())
+	status.DurationSeconds = int(engine.Duration().Seconds())
+	if currentSession != nil {
+		status.Task = currentSession.Task
+		status.Tag = currentSession.Tag
+		status.StartedAt = currentSession.Start.Format(time.RFC3339)
+		status.PausedSeconds = currentSession.PausedSeconds
+	}
+	if !engine.Paused() {
+		status.EndsAt = time.Now().Add(engine.Remaining()).Format(time.RFC3339)
+	}
+	return status
+}
+
+// getControlSocketPath returns the path of the socket the running app
+// listens on for commands.
+func getControlSocketPath() string {
+	return getCacheFilePath("control.sock")
+}
+
+// serveControl accepts commands from the command line on a Unix socket.
+// Windows supports Unix sockets since Windows 10 version 1803.
+func serveControl() {
+	path := getControlSocketPath()
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		slog.Warn("Another instance is listening", "path", path)
+		return
+	}
+	os.Remove(path) // Left over from a previous run
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		slog.Error("Failed to listen for commands", "err", err)
+		return
+	}
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			slog.Error("Failed to accept command", "err", err)
+			return
+		}
+		go handleControlConn(conn)
+	}
+}
+
+// handleControlConn runs the command sent on conn, one line with a JSON array
+// like ["start", "break"], and replies with the timer status as JSON.
+func handleControlConn(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		return
+	}
+	var args []string
+	var status timerStatus
+	if err := json.Unmarshal(line, &args); err != nil {
+		status.Error = "invalid request: " + err.Error()
+	} else {
+		status = runControlCommand(args)
+	}
+	data, _ := json.Marshal(status)
+	conn.Write(append(data, '\n'))
+}
+
+// runControlCommand runs a command with its arguments and returns the
+// resulting status.
+func runControlCommand(args []string) timerStatus {
+	command := ""
+	if len(args) > 0 {
+		command = args[0]
+	}
+	var err error
+	switch command {
+	case "start":
+		switch {
+		case len(args) < 2 || args[1] == sessionPomodoro:
+			startPomodoro()
+		case args[1] == sessionBreak:
+			startBreak()
+		case args[1] == "until" && len(args) > 2:
+			err = focusUntil(args[2])
+		default:
+			err = fmt.Errorf("unknown session %q, use pomodoro, break or until <time>", args[1])
+		}
+	case "stop":
+		mu.Lock()
+		if engine.Running() {
+			stopTimer()
+		}
+		mu.Unlock()
+	case "pause", "resume":
+		mu.Lock()
+		switch {
+		case !engine.Running():
+			err = fmt.Errorf("no session is running")
+		case command == "pause" && !engine.Paused():
+			pauseTimer()
+		case command == "resume" && engine.Paused():
+			resumeTimer()
+		}
+		mu.Unlock()
+	case "skip":
+		skipSession()
+	case "launch":
+		err = applyForwardedFlags(args[1:])
+	case "quit":
+		// Quit once the reply has been sent.
+		time.AfterFunc(500*time.Millisecond, quitApp)
+	case "status":
+	default:
+		err = fmt.Errorf("unknown command %q", command)
+	}
+
+	mu.Lock()
+	status := currentTimerStatus()
+	mu.Unlock()
+	if err != nil {
+		status.Error = err.Error()
+	}
+	return status
+}
+
+// errNotRunning is returned by sendControlCommand if no instance listens.
+var errNotRunning = errors.New("no running instance")
+
+// sendControlCommand sends a command to the running app and returns its
+// reply. timeout is how long to wait for the app to accept the connection.
+func sendControlCommand(args []string, timeout time.Duration) (timerStatus, []byte, error) {
+	var status timerStatus
+	var conn net.Conn
+	var err error
+	for deadline := time.Now().Add(timeout); ; time.Sleep(200 * time.Millisecond) {
+		conn, err = net.DialTimeout("unix", getControlSocketPath(), 2*time.Second)
+		if err == nil || time.Now().After(deadline) {
+			break
+		}
+	}
+	if err != nil {
+		return status, nil, errNotRunning
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	request, _ := json.Marshal(args)
+	if _, err := conn.Write(append(request, '\n')); err != nil {
+		return status, nil, fmt.Errorf("failed to send command: %v", err)
+	}
+	reply, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		return status, nil, fmt.Errorf("failed to read reply: %v", err)
+	}
+	if err := json.Unmarshal(reply, &status); err != nil {
+		return status, reply, fmt.Errorf("invalid reply: %v", err)
+	}
+	return status, reply, nil
+}
+
+// runControlClient sends a command to the running app, prints its reply and
+// returns the exit code: 0 on success, 1 if the command failed and 2 if the
+// app is not running.
+func runControlClient(args []string) int {
+	attachParentConsole()
+	status, reply, err := sendControlCommand(args, 0)
+	if err == errNotRunning {
+		fmt.Fprintln(os.Stderr, "Pomodoro Timer is not running")
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Print(string(reply))
+	if status.Error != "" {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lutischan-ferenc/systray"
+	"pomodoro-timer/pkg/pomodoro"
+)
+
+const extendDuration = 5 * time.Minute // Time added to the running session by extendSession
+
+var mPause *systray.MenuItem // Menu item for pausing and resuming the running session
+
+// addPauseMenu adds the pause and resume menu item to the system tray.
+func addPauseMenu() {
+	mPause = systray.AddMenuItem(tr("Pause"), tr("Pause or resume the running session"))
+	mPause.Click(func() {
+		togglePause()
+	})
+	updatePauseMenu()
+}
+
+// updatePauseMenu enables the pause menu item while a session runs. The
+// caller must hold mu.
+func updatePauseMenu() {
+	if mPause == nil {
+		return
+	}
+	if engine.Paused() {
+		mPause.SetTitle(tr("Resume"))
+	} else {
+		mPause.SetTitle(tr("Pause"))
+	}
+	if engine.Running() {
+		mPause.Enable()
+	} else {
+		mPause.Disable()
+	}
+}
+
+// togglePause pauses the running session or resumes the paused one.
+func togglePause() {
+	mu.Lock()
+	defer mu.Unlock()
+	if !engine.Running() {
+		return
+	}
+	if engine.Paused() {
+		resumeTimer()
+	} else {
+		pauseTimer()
+	}
+}
+
+// pauseTimer stops the countdown and the background sound of the running
+// session. The caller must hold mu.
+func pauseTimer() {
+	engine.Pause()
+	stopClockSound()
+	redrawIcon()
+	updatePauseMenu()
+	publishTimerEvent(eventPause)
+	setTooltip(fmt.Sprintf(tr("Paused, %02d:%02d left - Click to stop"), int(engine.Remaining().Minutes()), int(engine.Remaining().Seconds())%60))
+}
+
+// resumeTimer continues the paused session. The caller must hold mu.
+func resumeTimer() {
+	engine.Resume()
+	meetingInterrupted = false
+	hideInterruptedMenu()
+	if engine.InPomodoro() && currentSession != nil && scheduledSettings(currentSession.Start).EnableClockSound {
+		playClockSound()
+	}
+	redrawIcon()
+	updatePauseMenu()
+	publishTimerEvent(eventResume)
+}
+
+// skipSession ends the running session early and starts the next one: a
+// break after a Pomodoro and a Pomodoro after a break. If no session runs,
+// the next one is started.
+func skipSession() {
+	mu.Lock()
+	defer mu.Unlock()
+	if engine.Running() {
+		stopTimer()
+	}
+	if engine.Next() == pomodoro.Break {
+		startTimer(pomodoro.Break, nextBreakDuration())
+	} else {
+		startTimer(pomodoro.Pomodoro, pomodoroDuration())
+	}
+}
+
+// extendSession adds time to the running session.
+func extendSession(d time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	if !engine.Running() {
+		return
+	}
+	extendRunning(d)
+}
+
+// extendRunning adds time to the running session, or takes it away if d is
+// negative, and shows the new time left. The caller must hold mu.
+func extendRunning(d time.Duration) {
+	engine.Extend(d)
+	if currentSession != nil {
+		currentSession.Duration = int(engine.Duration().Minutes())
+	}
+	oldDisplayText = iconText(engine.Remaining())
+	setTrayIcon(oldDisplayText, engine.Count())
+	updateTaskbarProgress()
+}
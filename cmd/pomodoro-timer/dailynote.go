@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DailyNotesSettings configures the focus journal kept in Markdown daily notes.
+type DailyNotesSettings struct {
+	Folder   string `json:"folder"`    // Folder of the daily notes, e.g. the Daily Notes folder of an Obsidian vault; empty to disable
+	FileName string `json:"file_name"` // Name of a note as an Obsidian date format, e.g. "YYYY-MM-DD", without ".md"
+	Heading  string `json:"heading"`   // Heading the entries are added under, e.g. "## Focus"; empty to add them at the end
+}
+
+// momentTokens maps the Obsidian (Moment.js) date tokens to Go layouts, longest first.
+var momentTokens = []struct{ moment, layout string }{
+	{"YYYY", "2006"},
+	{"YY", "06"},
+	{"MMMM", "January"},
+	{"MMM", "Jan"},
+	{"MM", "01"},
+	{"M", "1"},
+	{"dddd", "Monday"},
+	{"ddd", "Mon"},
+	{"DD", "02"},
+	{"D", "2"},
+}
+
+// momentLayout converts an Obsidian date format to a Go time layout. Text in
+// square brackets is copied literally.
+func momentLayout(format string) string {
+	var layout strings.Builder
+	for len(format) > 0 {
+		if format[0] == '[' {
+			if end := strings.IndexByte(format, ']'); end > 0 {
+				layout.WriteString(format[1:end])
+				format = format[end+1:]
+				continue
+			}
+		}
+		matched := false
+		for _, token := range momentTokens {
+			if strings.HasPrefix(format, token.moment) {
+				layout.WriteString(token.layout)
+				format = format[len(token.moment):]
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			layout.WriteByte(format[0])
+			format = format[1:]
+		}
+	}
+	return layout.String()
+}
+
+// dailyNoteEntry returns the journal line of a completed Pomodoro, e.g.
+// "- 🍅 14:00–14:25 Write report #writing".
+func dailyNoteEntry(record SessionRecord) string {
+	entry := fmt.Sprintf("- 🍅 %s–%s", record.Start.Format("15:04"), record.End.Format("15:04"))
+	if record.Task != "" {
+		entry += " " + record.Task
+	}
+	if record.Tag != "" {
+		entry += " #" + record.Tag
+	}
+	return entry
+}
+
+// logDailyNote adds a completed Pomodoro to the daily note of the day it started.
+func logDailyNote(record SessionRecord) {
+	notes := settings.DailyNotes
+	if notes.Folder == "" || !record.Completed {
+		return
+	}
+	path := filepath.Join(notes.Folder, record.Start.Format(momentLayout(notes.FileName))+".md")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		slog.Error("Failed to create daily notes folder", "err", err)
+		return
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		slog.Error("Failed to read daily note", "err", err)
+		return
+	}
+	note := insertUnderHeading(string(data), notes.Heading, dailyNoteEntry(record))
+	if err := ioutil.WriteFile(path, []byte(note), 0644); err != nil {
+		slog.Error("Failed to write daily note", "err", err)
+	}
+}
+
+// insertUnderHeading adds a line to the end of the section of a Markdown
+// document under heading, adding the heading if missing. With no heading the
+// line is added to the end of the document.
+func insertUnderHeading(doc, heading, line string) string {
+	lines := strings.Split(strings.TrimRight(doc, "\n"), "\n")
+	if doc == "" {
+		lines = nil
+	}
+	heading = strings.TrimSpace(heading)
+	start := -1
+	if heading != "" {
+		for i, l := range lines {
+			if strings.TrimSpace(l) == heading {
+				start = i
+				break
+			}
+		}
+	}
+	if start < 0 {
+		if heading != "" {
+			if len(lines) > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, heading)
+		}
+		lines = append(lines, line)
+		return strings.Join(lines, "\n") + "\n"
+	}
+
+	// The section ends at the next heading of the same or a higher level.
+	level := len(heading) - len(strings.TrimLeft(heading, "#"))
+	end := len(lines)
+	for i := start + 1; i < len(lines); i++ {
+		l := strings.TrimSpace(lines[i])
+		depth := len(l) - len(strings.TrimLeft(l, "#"))
+		if depth > 0 && depth <= level && strings.HasPrefix(l[depth:], " ") {
+			end = i
+			break
+		}
+	}
+	for end > start+1 && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	lines = append(lines[:end], append([]string{line}, lines[end:]...)...)
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// systemUsesDarkTheme reports whether the menu bar uses the dark appearance.
+func systemUsesDarkTheme() bool {
+	// The key only exists in dark mode
+	out, err := exec.Command("defaults", "read", "-g", "AppleInterfaceStyle").Output()
+	return err == nil && strings.Contains(strings.ToLower(string(out)), "dark")
+}
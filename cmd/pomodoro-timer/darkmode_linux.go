@@ -0,0 +1,16 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// systemUsesDarkTheme reports whether the desktop uses a dark GTK theme.
+func systemUsesDarkTheme() bool {
+	out, err := exec.Command("gsettings", "get", "org.gnome.desktop.interface", "color-scheme").Output()
+	if err == nil && strings.Contains(string(out), "dark") {
+		return true
+	}
+	out, err = exec.Command("gsettings", "get", "org.gnome.desktop.interface", "gtk-theme").Output()
+	return err == nil && strings.Contains(strings.ToLower(string(out)), "dark")
+}
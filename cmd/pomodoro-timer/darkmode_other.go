@@ -0,0 +1,9 @@
+//go:build !windows && !darwin && !linux
+
+package main
+
+// systemUsesDarkTheme reports whether the desktop uses a dark theme.
+// Detection is not supported on this platform.
+func systemUsesDarkTheme() bool {
+	return false
+}
@@ -0,0 +1,22 @@
+package main
+
+import "golang.org/x/sys/windows/registry"
+
+// systemUsesDarkTheme reports whether the taskbar uses the dark theme.
+func systemUsesDarkTheme() bool {
+	key, err := registry.OpenKey(registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Themes\Personalize`, registry.QUERY_VALUE)
+	if err != nil {
+		return false
+	}
+	defer key.Close()
+
+	// SystemUsesLightTheme applies to the taskbar, AppsUseLightTheme to windows
+	light, _, err := key.GetIntegerValue("SystemUsesLightTheme")
+	if err != nil {
+		light, _, err = key.GetIntegerValue("AppsUseLightTheme")
+		if err != nil {
+			return false
+		}
+	}
+	return light == 0
+}
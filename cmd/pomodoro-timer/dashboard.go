@@ -0,0 +1,63 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+//go:embed assets/dashboard.html
+var dashboardHTML []byte
+
+// todayStats is the JSON summary of today's Pomodoros.
+type todayStats struct {
+	Pomodoros             int `json:"pomodoros"`     // Completed Pomodoros
+	FocusMinutes          int `json:"focus_minutes"` // Time spent in completed Pomodoros
+	Stopped               int `json:"stopped"`       // Pomodoros stopped early
+	InternalInterruptions int `json:"internal_interruptions"`
+	ExternalInterruptions int `json:"external_interruptions"`
+}
+
+// handleDashboard serves the web dashboard. The page reads the token from
+// its own URL and uses the API for everything else.
+func handleDashboard(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(dashboardHTML)
+}
+
+// handleAPIStatsToday returns the statistics of today's sessions.
+func handleAPIStatsToday(w http.ResponseWriter, r *http.Request) {
+	records, err := loadHistory()
+	if err != nil {
+		writeAPIJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var stats sessionStats
+	for _, record := range records {
+		if !record.Start.Before(today) {
+			stats.add(record)
+		}
+	}
+	writeAPIJSON(w, http.StatusOK, todayStats{
+		Pomodoros:             stats.pomodoros,
+		FocusMinutes:          int(stats.focus.Round(time.Minute).Minutes()),
+		Stopped:               stats.stopped,
+		InternalInterruptions: stats.internalInterruptions,
+		ExternalInterruptions: stats.externalInterruptions,
+	})
+}
+
+// openDashboard opens the web dashboard in the browser, enabling the REST
+// API that serves it if needed.
+func openDashboard() {
+	if newSettings := currentSettings(); !newSettings.API.Enabled {
+		newSettings.API.Enabled = true
+		applySettings(newSettings)
+	}
+	// Read again, as enabling the API generates its token
+	api := currentSettings().API
+	openBrowser(fmt.Sprintf("http://127.0.0.1:%d/?token=%s", api.Port, api.Token))
+}
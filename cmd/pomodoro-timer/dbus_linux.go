@@ -0,0 +1,125 @@
+package main
+
+import (
+	"log/slog"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+)
+
+const (
+	dbusName      = "org.pomodorotimer"                   // Bus name and interface of the service
+	dbusPath      = dbus.ObjectPath("/org/pomodorotimer") // Path of the timer object
+	dbusInterface = "org.pomodorotimer"
+)
+
+// dbusIntrospection describes the timer object to D-Bus tools.
+const dbusIntrospection = `
+<node>
+	<interface name="` + dbusInterface + `">
+		<method name="Start">
+			<arg name="session" direction="in" type="s"/>
+		</method>
+		<method name="Stop"/>
+		<method name="Pause"/>
+		<method name="Resume"/>
+		<method name="Skip"/>
+		<method name="Status">
+			<arg name="status" direction="out" type="a{sv}"/>
+		</method>
+		<signal name="SessionStarted">
+			<arg name="session" type="s"/>
+		</signal>
+		<signal name="SessionFinished">
+			<arg name="session" type="s"/>
+			<arg name="completed" type="b"/>
+		</signal>
+	</interface>` + introspect.IntrospectDeclarationString + `
+</node>`
+
+// dbusTimer implements the D-Bus methods of the timer.
+type dbusTimer struct{}
+
+// run runs a control command and converts a failure into a D-Bus error.
+func (dbusTimer) run(args ...string) *dbus.Error {
+	if status := runControlCommand(args); status.Error != "" {
+		return dbus.NewError(dbusInterface+".Error", []interface{}{status.Error})
+	}
+	return nil
+}
+
+// Start starts a "pomodoro" or "break", or a Pomodoro if session is empty.
+func (t dbusTimer) Start(session string) *dbus.Error {
+	if session == "" {
+		session = sessionPomodoro
+	}
+	return t.run("start", session)
+}
+
+// Stop stops the running session.
+func (t dbusTimer) Stop() *dbus.Error { return t.run("stop") }
+
+// Pause pauses the running session.
+func (t dbusTimer) Pause() *dbus.Error { return t.run("pause") }
+
+// Resume resumes the paused session.
+func (t dbusTimer) Resume() *dbus.Error { return t.run("resume") }
+
+// Skip ends the running session and starts the next one.
+func (t dbusTimer) Skip() *dbus.Error { return t.run("skip") }
+
+// Status returns the timer status with the keys of the JSON status.
+func (dbusTimer) Status() (map[string]dbus.Variant, *dbus.Error) {
+	s := runControlCommand([]string{"status"})
+	return map[string]dbus.Variant{
+		"state":             dbus.MakeVariant(s.State),
+		"phase":             dbus.MakeVariant(s.Phase),
+		"remaining_seconds": dbus.MakeVariant(int32(s.RemainingSeconds)),
+		"duration_seconds":  dbus.MakeVariant(int32(s.DurationSeconds)),
+		"paused_seconds":    dbus.MakeVariant(int32(s.PausedSeconds)),
+		"pomodoro_count":    dbus.MakeVariant(int32(s.PomodoroCount)),
+		"started_at":        dbus.MakeVariant(s.StartedAt),
+		"ends_at":           dbus.MakeVariant(s.EndsAt),
+		"task":              dbus.MakeVariant(s.Task),
+		"tag":               dbus.MakeVariant(s.Tag),
+		"profile":           dbus.MakeVariant(s.Profile),
+	}, nil
+}
+
+// startDBusService exports the timer on the session bus and emits signals
+// when sessions start and finish.
+func startDBusService() {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		slog.Error("Failed to connect to D-Bus", "err", err)
+		return
+	}
+	conn.Export(dbusTimer{}, dbusPath, dbusInterface)
+	conn.Export(introspect.Introspectable(dbusIntrospection), dbusPath, "org.freedesktop.DBus.Introspectable")
+	reply, err := conn.RequestName(dbusName, dbus.NameFlagDoNotQueue)
+	if err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		slog.Error("Failed to claim the D-Bus name", "name", dbusName, "err", err)
+		conn.Close()
+		return
+	}
+
+	ch := subscribeEvents()
+	for event := range ch {
+		var err error
+		switch event.name {
+		case eventPomodoroStart:
+			err = conn.Emit(dbusPath, dbusInterface+".SessionStarted", sessionPomodoro)
+		case eventBreakStart:
+			err = conn.Emit(dbusPath, dbusInterface+".SessionStarted", sessionBreak)
+		case eventPomodoroEnd:
+			err = conn.Emit(dbusPath, dbusInterface+".SessionFinished", sessionPomodoro, true)
+		case eventBreakEnd:
+			err = conn.Emit(dbusPath, dbusInterface+".SessionFinished", sessionBreak, true)
+		case eventStop:
+			err = conn.Emit(dbusPath, dbusInterface+".SessionFinished", event.status.Phase, false)
+		}
+		if err != nil {
+			slog.Error("Failed to emit D-Bus signal", "err", err)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !linux
+
+package main
+
+// startDBusService does nothing, as D-Bus is only used on Linux.
+func startDBusService() {}
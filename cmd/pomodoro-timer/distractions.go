@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Actions taken when a distracting process runs during a Pomodoro.
+const (
+	distractionWarn     = "warn"     // Show a notification
+	distractionMinimize = "minimize" // Minimize its windows (Windows), warn elsewhere
+	distractionKill     = "kill"     // End the process
+)
+
+// distractionCheckSeconds is how often a running Pomodoro checks for distracting processes.
+const distractionCheckSeconds = 10
+
+// DistractionSettings configures watching for distracting processes.
+type DistractionSettings struct {
+	Processes []string `json:"processes"` // Executable names, e.g. "steam.exe", compared case-insensitively
+	Action    string   `json:"action"`    // "warn", "minimize" or "kill"
+}
+
+// Distraction records a distracting process found running during a Pomodoro.
+type Distraction struct {
+	Time    time.Time `json:"time"`    // When the process was first found
+	Process string    `json:"process"` // Executable name
+	Action  string    `json:"action"`  // Action taken
+}
+
+// process is a running process.
+type process struct {
+	pid  int
+	name string // Executable name, e.g. "steam.exe"
+}
+
+var distractionChecking atomic.Bool // A distraction check is running
+
+// validateDistractions checks the distraction action, returning the problems found.
+func validateDistractions(d *DistractionSettings) []string {
+	switch d.Action {
+	case distractionWarn, distractionMinimize, distractionKill:
+		return nil
+	}
+	problem := fmt.Sprintf("distractions.action: %q is not one of warn, minimize, kill; using warn", d.Action)
+	d.Action = distractionWarn
+	return []string{problem}
+}
+
+// isDistraction reports whether the executable is in the list of distracting processes.
+func isDistraction(name string, distractions []string) bool {
+	for _, d := range distractions {
+		if strings.EqualFold(strings.TrimSpace(d), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// distractionTick checks for distracting processes every
+// distractionCheckSeconds of the running Pomodoro.
+func distractionTick(s sessionInfo) {
+	if int(s.timer.Remaining.Seconds())%distractionCheckSeconds == 0 {
+		go checkDistractions()
+	}
+}
+
+// checkDistractions looks for distracting processes during a Pomodoro and
+// takes the configured action. Each process is logged in the session record
+// and announced once per session; minimizing and killing repeat on every check.
+func checkDistractions() {
+	config := settings.Distractions
+	if len(config.Processes) == 0 || !distractionChecking.CompareAndSwap(false, true) {
+		return
+	}
+	defer distractionChecking.Store(false)
+
+	processes, err := runningProcesses()
+	if err != nil {
+		slog.Error("Failed to list processes", "err", err)
+		return
+	}
+	for _, p := range processes {
+		if p.pid == os.Getpid() || !isDistraction(p.name, config.Processes) {
+			continue
+		}
+		action := config.Action
+		switch action {
+		case distractionMinimize:
+			if err := minimizeProcessWindows(p.pid); err != nil {
+				action = distractionWarn
+			}
+		case distractionKill:
+			if proc, err := os.FindProcess(p.pid); err == nil {
+				if err := proc.Kill(); err != nil {
+					slog.Error("Failed to end process", "process", p.name, "err", err)
+					action = distractionWarn
+				}
+			}
+		}
+
+		if logDistraction(p.name, action) {
+			message := fmt.Sprintf(tr("%s is running during your Pomodoro"), p.name)
+			switch action {
+			case distractionMinimize:
+				message = fmt.Sprintf(tr("%s was minimized"), p.name)
+			case distractionKill:
+				message = fmt.Sprintf(tr("%s was closed"), p.name)
+			}
+			go sendNotification(tr("Stay focused"), message)
+		}
+	}
+}
+
+// logDistraction adds the process to the running Pomodoro's record and
+// reports whether it was not logged before.
+func logDistraction(name, action string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	if currentSession == nil || currentSession.Type != sessionPomodoro {
+		return false
+	}
+	for _, d := range currentSession.Distractions {
+		if strings.EqualFold(d.Process, name) {
+			return false
+		}
+	}
+	currentSession.Distractions = append(currentSession.Distractions, Distraction{Time: time.Now(), Process: name, Action: action})
+	return true
+}
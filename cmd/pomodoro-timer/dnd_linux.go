@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+var desktopDNDRestore func() error // Undoes the desktop's Do Not Disturb, nil if not enabled; used by the focus worker only
+
+// desktopDNDStarted turns on the desktop's Do Not Disturb for a Pomodoro.
+func desktopDNDStarted() {
+	if !settings.DesktopDND {
+		return
+	}
+	focusQueue.enqueue(func() {
+		if desktopDNDRestore != nil {
+			return
+		}
+		restore, err := enableDesktopDND()
+		if err != nil {
+			slog.Error("Failed to enable Do Not Disturb", "err", err)
+			return
+		}
+		desktopDNDRestore = restore
+	})
+}
+
+// desktopDNDEnded restores the desktop's Do Not Disturb from before the Pomodoro.
+func desktopDNDEnded() {
+	focusQueue.enqueueRestore(func() {
+		if desktopDNDRestore == nil {
+			return
+		}
+		if err := desktopDNDRestore(); err != nil {
+			slog.Error("Failed to restore Do Not Disturb", "err", err)
+		}
+		desktopDNDRestore = nil
+	})
+}
+
+// enableDesktopDND turns on Do Not Disturb on GNOME, by hiding notification
+// banners, or on KDE Plasma, by inhibiting notifications. It returns a
+// function restoring the previous state.
+func enableDesktopDND() (func() error, error) {
+	desktop := strings.ToLower(os.Getenv("XDG_CURRENT_DESKTOP"))
+	switch {
+	case strings.Contains(desktop, "kde"):
+		conn, err := dbus.SessionBus()
+		if err != nil {
+			return nil, err
+		}
+		// The inhibition lasts until UnInhibit or until the connection closes.
+		notifications := conn.Object("org.freedesktop.Notifications", "/org/freedesktop/Notifications")
+		var cookie uint32
+		err = notifications.Call("org.freedesktop.Notifications.Inhibit", 0, appDirName, "Pomodoro", map[string]dbus.Variant{}).Store(&cookie)
+		if err != nil {
+			return nil, err
+		}
+		return func() error {
+			return notifications.Call("org.freedesktop.Notifications.UnInhibit", 0, cookie).Err
+		}, nil
+	case strings.Contains(desktop, "gnome"), strings.Contains(desktop, "unity"), strings.Contains(desktop, "budgie"):
+		out, err := exec.Command("gsettings", "get", "org.gnome.desktop.notifications", "show-banners").Output()
+		if err != nil {
+			return nil, err
+		}
+		previous := strings.TrimSpace(string(out))
+		if err := setShowBanners("false"); err != nil {
+			return nil, err
+		}
+		return func() error { return setShowBanners(previous) }, nil
+	default:
+		return nil, fmt.Errorf("not supported on the %q desktop, only on GNOME and KDE Plasma", os.Getenv("XDG_CURRENT_DESKTOP"))
+	}
+}
+
+// setShowBanners shows or hides GNOME's notification banners.
+func setShowBanners(value string) error {
+	return exec.Command("gsettings", "set", "org.gnome.desktop.notifications", "show-banners", value).Run()
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package main
+
+// desktopDNDStarted does nothing: Do Not Disturb is set through Focus Assist
+// on Windows.
+func desktopDNDStarted() {}
+
+// desktopDNDEnded does nothing, see desktopDNDStarted.
+func desktopDNDEnded() {}
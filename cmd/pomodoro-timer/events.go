@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Timer events published in addition to the webhook events.
+const (
+	eventTick   = "tick"   // Every second of a running session
+	eventPause  = "pause"  // The running session was paused
+	eventResume = "resume" // The paused session was resumed
+	eventStatus = "status" // The current status, sent when a client connects
+)
+
+// timerEvent is a timer event with the status after it.
+type timerEvent struct {
+	name   string
+	status timerStatus
+}
+
+var (
+	eventMu          sync.Mutex
+	eventSubscribers = map[chan timerEvent]bool{} // Channels of the connected event streams, guarded by eventMu
+)
+
+// publishEvent sends an event to the connected event streams. Streams that
+// are too slow to keep up miss events rather than blocking the timer.
+func publishEvent(name string, status timerStatus) {
+	eventMu.Lock()
+	defer eventMu.Unlock()
+	for ch := range eventSubscribers {
+		select {
+		case ch <- timerEvent{name, status}:
+		default:
+		}
+	}
+}
+
+// subscribeEvents returns a channel receiving the published events until
+// unsubscribeEvents is called.
+func subscribeEvents() chan timerEvent {
+	ch := make(chan timerEvent, 16)
+	eventMu.Lock()
+	eventSubscribers[ch] = true
+	eventMu.Unlock()
+	return ch
+}
+
+// unsubscribeEvents stops sending events to ch.
+func unsubscribeEvents(ch chan timerEvent) {
+	eventMu.Lock()
+	delete(eventSubscribers, ch)
+	eventMu.Unlock()
+}
+
+// publishTimerEvent publishes an event with the current status. The caller
+// must hold mu.
+func publishTimerEvent(name string) {
+	publishEvent(name, currentTimerStatus())
+}
+
+// handleAPIEvents streams the timer events as Server-Sent Events. Each event
+// has the event name and the timer status as JSON data.
+func handleAPIEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	ch := subscribeEvents()
+	defer unsubscribeEvents(ch)
+
+	mu.Lock()
+	status := currentTimerStatus()
+	mu.Unlock()
+	writeEvent(w, timerEvent{eventStatus, status})
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(30 * time.Second)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case event := <-ch:
+			writeEvent(w, event)
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes an event in the Server-Sent Events format.
+func writeEvent(w http.ResponseWriter, event timerEvent) {
+	data, _ := json.Marshal(event.status)
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.name, data)
+}
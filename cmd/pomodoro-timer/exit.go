@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"pomodoro-timer/pkg/pomodoro"
+)
+
+const (
+	exitFlushTimeout = 5 * time.Second // How long quitting waits for the integrations to be restored
+	timerStateMaxAge = 12 * time.Hour  // Saved sessions older than this are not restored
+)
+
+// timerState is the timer saved on exit and restored on the next start.
+type timerState struct {
+	SavedAt       time.Time     `json:"saved_at"`
+	Running       bool          `json:"running"`        // A session was running or paused
+	Pomodoro      bool          `json:"pomodoro"`       // The session was a Pomodoro rather than a break
+	Remaining     time.Duration `json:"remaining"`      // Time left of the session
+	PomodoroCount int           `json:"pomodoro_count"` // Pomodoros completed in the cycle
+}
+
+// relaunch is the command started after quitting, set when an update restarts the app.
+var relaunch *exec.Cmd
+
+// getTimerStatePath returns the path of the file holding the timer saved on exit.
+func getTimerStatePath() string {
+	return getDataFilePath("timer_state.json")
+}
+
+// handleSignals quits cleanly on Ctrl+C and termination requests, so they
+// go through onExit like the Exit menu item.
+func handleSignals() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	sig := <-signals
+	slog.Info("Quitting", "signal", sig.String())
+	quitApp()
+}
+
+// onExit saves the timer, ends the running session and waits for the
+// integrations to restore the Slack status, Do Not Disturb and paused music.
+func onExit() {
+	mu.Lock()
+	saveTimerState()
+	if engine.Running() {
+		engine.Stop()
+		endSession(false)
+	}
+	stopEngine()
+	stopBreakReminders()
+	mu.Unlock()
+	stopClockSound()
+	flushQueues(exitFlushTimeout)
+
+	if relaunch != nil {
+		// The new instance must not find this one running and forward to it.
+		if instanceLock != nil {
+			instanceLock.Close()
+		}
+		if err := relaunch.Start(); err != nil {
+			slog.Error("Failed to restart", "err", err)
+		}
+	}
+	slog.Info("Exited")
+}
+
+// flushQueues waits until the background workers have run the calls queued
+// so far, or the timeout elapses.
+func flushQueues(timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for _, q := range callQueues {
+		if !q.flush(ctx) {
+			slog.Warn("Quitting before the integrations were restored", "integration", q.name)
+			return
+		}
+	}
+}
+
+// saveTimerState writes the timer to a file, to be restored on the next
+// start. The caller must hold mu.
+func saveTimerState() {
+	state := timerState{
+		SavedAt:       time.Now(),
+		Running:       engine.Running(),
+		Pomodoro:      engine.InPomodoro(),
+		Remaining:     engine.Remaining(),
+		PomodoroCount: engine.Count(),
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		slog.Error("Failed to encode timer state", "err", err)
+		return
+	}
+	if err := ioutil.WriteFile(getTimerStatePath(), data, 0644); err != nil {
+		slog.Error("Failed to save timer state", "err", err)
+	}
+}
+
+// restoreTimerState restores the timer saved on exit. A session that was
+// running is restored paused, so the time away does not count; its first
+// part is already in the history as a stopped session.
+func restoreTimerState() {
+	data, err := ioutil.ReadFile(getTimerStatePath())
+	if err != nil {
+		return
+	}
+	os.Remove(getTimerStatePath())
+	var state timerState
+	if err := json.Unmarshal(data, &state); err != nil {
+		slog.Error("Failed to load timer state", "err", err)
+		return
+	}
+	if time.Since(state.SavedAt) > timerStateMaxAge {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	engine.SetCount(state.PomodoroCount)
+	kind := pomodoro.Break
+	if state.Pomodoro {
+		kind = pomodoro.Pomodoro
+	}
+	if !state.Running || state.Remaining <= 0 {
+		engine.SetKind(kind)
+		setTrayIcon("▶", engine.Count())
+		return
+	}
+	startTimer(kind, state.Remaining)
+	pauseTimer()
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"pomodoro-timer/internal/audio"
+)
+
+// Eye-rest melodies: a soft falling pair asks you to look away, a single
+// higher note tells you the rest is over.
+var (
+	eyeRestStartMelody = []audio.Note{
+		{Freq: 880, Duration: 120 * time.Millisecond},
+		{Freq: 660, Duration: 200 * time.Millisecond},
+	}
+	eyeRestEndMelody = []audio.Note{
+		{Freq: 880, Duration: 150 * time.Millisecond},
+	}
+)
+
+var (
+	eyeRestFocused time.Duration // Pomodoro time since the last eye rest or break, guarded by mu
+	eyeRestTimer   *time.Timer   // Ends the eye rest in progress, guarded by mu
+)
+
+// eyeRestSessionStarted starts counting anew after a break, which rests the
+// eyes anyway. Consecutive Pomodoros keep counting, so the reminders follow
+// the time spent at the screen rather than the Pomodoro cycle.
+func eyeRestSessionStarted(s sessionInfo) {
+	if !s.pomodoro() {
+		eyeRestFocused = 0
+	}
+}
+
+// eyeRestTick reminds to rest the eyes every eye_rest_minutes of running
+// Pomodoros. No reminder is given in the last seconds of a Pomodoro, when the
+// break is about to start.
+func eyeRestTick(s sessionInfo) {
+	if !settings.EyeRest || settings.EyeRestMinutes <= 0 {
+		return
+	}
+	eyeRestFocused += time.Second
+	rest := time.Duration(settings.EyeRestSeconds) * time.Second
+	if eyeRestFocused < time.Duration(settings.EyeRestMinutes)*time.Minute || s.timer.Remaining <= rest {
+		return
+	}
+	eyeRestFocused = 0
+	startEyeRest(rest)
+}
+
+// startEyeRest asks to look away for the given time and chimes when it is
+// over. The caller must hold mu.
+func startEyeRest(rest time.Duration) {
+	go audioPlayer.Play(audio.Melody(eyeRestStartMelody, 0.2, audioPlayer.SampleRate()))
+	go sendNotification(tr("Rest your eyes"), fmt.Sprintf(tr("Look at something 20 feet (6 m) away for %d seconds"), int(rest.Seconds())))
+
+	if eyeRestTimer != nil {
+		eyeRestTimer.Stop()
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(rest, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if eyeRestTimer != timer || !engine.Running() || !engine.InPomodoro() {
+			return // Replaced, or the Pomodoro ended in the meantime
+		}
+		eyeRestTimer = nil
+		go audioPlayer.Play(audio.Melody(eyeRestEndMelody, 0.2, audioPlayer.SampleRate()))
+	})
+	eyeRestTimer = timer
+}
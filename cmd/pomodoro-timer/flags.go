@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// launchFlags holds the command-line flags. Settings given as flags override
+// the settings file for this run only and are never saved; the profile is
+// switched to as if chosen from the menu.
+type launchFlags struct {
+	pomodoro   int    // Pomodoro duration in minutes, 0 to use the settings
+	shortBreak int    // Short break duration in minutes, 0 to use the settings
+	longBreak  int    // Long break duration in minutes, 0 to use the settings
+	start      bool   // Start a Pomodoro right away
+	noSound    bool   // Mute all sounds
+	profile    string // Profile to switch to
+	url        string // pomodoro:// link to open once started
+	portable   bool   // Keep all files next to the executable, see initPortableMode
+	headless   bool   // Run without a tray icon, see runHeadless
+}
+
+var (
+	launch      launchFlags
+	launchSaved TimerSettings // Settings from the file before the flags were applied
+)
+
+// parseLaunchFlags parses command-line arguments into l, writing the usage
+// to output on errors.
+func parseLaunchFlags(l *launchFlags, args []string, output io.Writer) error {
+	fs := flag.NewFlagSet("pomodoro-timer", flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.IntVar(&l.pomodoro, "pomodoro", 0, "Pomodoro duration in `minutes` for this run")
+	fs.IntVar(&l.shortBreak, "break", 0, "short break duration in `minutes` for this run")
+	fs.IntVar(&l.longBreak, "long-break", 0, "long break duration in `minutes` for this run")
+	fs.BoolVar(&l.start, "start", false, "start a Pomodoro right away")
+	fs.BoolVar(&l.noSound, "no-sound", false, "mute all sounds for this run")
+	fs.StringVar(&l.profile, "profile", "", "switch to the settings profile with this `name`")
+	fs.BoolVar(&l.portable, "portable", false, "keep the settings and history next to the executable")
+	fs.BoolVar(&l.headless, "headless", false, "run without a tray icon, controlled by the command line and REST API")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var err error
+	for name, minutes := range map[string]int{"pomodoro": l.pomodoro, "break": l.shortBreak, "long-break": l.longBreak} {
+		if minutes < 0 || minutes > 600 {
+			err = fmt.Errorf("-%s must be from 0 (use the settings) to 600 minutes", name)
+		}
+	}
+	if fs.NArg() > 0 {
+		err = fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if err != nil {
+		fmt.Fprintln(output, err)
+		fs.Usage()
+	}
+	return err
+}
+
+// parseFlags parses the command line and exits on invalid flags. A single
+// pomodoro:// link is kept to be opened once the app has started.
+func parseFlags() {
+	if len(os.Args) == 2 && isAppURL(os.Args[1]) {
+		launch.url = os.Args[1]
+		return
+	}
+	var usage bytes.Buffer
+	err := parseLaunchFlags(&launch, os.Args[1:], &usage)
+	if err == nil {
+		return
+	}
+	attachParentConsole()
+	if err == flag.ErrHelp {
+		fmt.Print(usage.String())
+		os.Exit(0)
+	}
+	fmt.Fprint(os.Stderr, usage.String())
+	os.Exit(2)
+}
+
+// applyForwardedFlags applies the flags of a second launch of the app to this
+// instance, as if it had been started with them, or opens its link.
+func applyForwardedFlags(args []string) error {
+	if len(args) == 1 && isAppURL(args[0]) {
+		return handleAppURL(args[0])
+	}
+	if len(args) == 0 {
+		go sendNotification(tr("Pomodoro Timer is already running"), tr("Use the tray icon to control the timer"))
+		return nil
+	}
+	var l launchFlags
+	if err := parseLaunchFlags(&l, args, io.Discard); err != nil {
+		return err
+	}
+
+	mu.Lock()
+	newSettings := settingsToSave()
+	mu.Unlock()
+	if l.profile != "" {
+		var err error
+		if newSettings, err = withProfile(newSettings, l.profile); err != nil {
+			return err
+		}
+	}
+	if l.pomodoro > 0 {
+		launch.pomodoro = l.pomodoro
+	}
+	if l.shortBreak > 0 {
+		launch.shortBreak = l.shortBreak
+	}
+	if l.longBreak > 0 {
+		launch.longBreak = l.longBreak
+	}
+	launch.noSound = launch.noSound || l.noSound
+	applyLaunchFlags(&newSettings)
+	applySettings(newSettings)
+
+	if l.start {
+		startPomodoro()
+	}
+	return nil
+}
+
+// applyLaunchFlags overrides settings read from the file with the flags,
+// remembering the file's values for settingsToSave.
+func applyLaunchFlags(s *TimerSettings) {
+	launchSaved = *s
+	if launch.pomodoro > 0 {
+		s.PomodoroDuration = launch.pomodoro
+	}
+	if launch.shortBreak > 0 {
+		s.ShortBreakDuration = launch.shortBreak
+	}
+	if launch.longBreak > 0 {
+		s.LongBreakDuration = launch.longBreak
+	}
+	if launch.noSound {
+		s.Muted = true
+	}
+}
+
+// settingsToSave returns the current settings with the values that still
+// come from the flags replaced by the file's values. Values changed since,
+// e.g. in the settings form, are saved. The caller must hold mu.
+func settingsToSave() TimerSettings {
+	s := settings
+	if launch.pomodoro > 0 && s.PomodoroDuration == launch.pomodoro {
+		s.PomodoroDuration = launchSaved.PomodoroDuration
+	}
+	if launch.shortBreak > 0 && s.ShortBreakDuration == launch.shortBreak {
+		s.ShortBreakDuration = launchSaved.ShortBreakDuration
+	}
+	if launch.longBreak > 0 && s.LongBreakDuration == launch.longBreak {
+		s.LongBreakDuration = launchSaved.LongBreakDuration
+	}
+	if launch.noSound && s.Muted {
+		s.Muted = launchSaved.Muted
+	}
+	return s
+}
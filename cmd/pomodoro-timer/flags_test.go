@@ -0,0 +1,28 @@
+package main
+
+import (
+	"io"
+	"testing"
+)
+
+func TestParseLaunchFlagsDurations(t *testing.T) {
+	for _, test := range []struct {
+		args    []string
+		want    int
+		wantErr bool
+	}{
+		{[]string{"-pomodoro", "0"}, 0, false}, // Uses the settings
+		{[]string{"-pomodoro", "50"}, 50, false},
+		{[]string{"-pomodoro", "600"}, 600, false},
+		{[]string{"-pomodoro", "601"}, 0, true},
+		{[]string{"-pomodoro", "-1"}, 0, true},
+	} {
+		var l launchFlags
+		err := parseLaunchFlags(&l, test.args, io.Discard)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseLaunchFlags(%q) error = %v, want error %v", test.args, err, test.wantErr)
+		} else if err == nil && l.pomodoro != test.want {
+			t.Errorf("parseLaunchFlags(%q) pomodoro = %d, want %d", test.args, l.pomodoro, test.want)
+		}
+	}
+}
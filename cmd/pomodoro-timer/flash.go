@@ -0,0 +1,56 @@
+package main
+
+import "time"
+
+// iconFlashInterval is how often the icon alternates while flashing.
+const iconFlashInterval = 600 * time.Millisecond
+
+var (
+	iconFlashStop chan struct{} // Closed to stop the flashing, nil if not flashing; guarded by mu
+	iconFlashOn   bool          // The icon shows its highlighted state, guarded by mu
+)
+
+// startIconFlash makes the icon alternate between its normal and a highlighted
+// background until the user starts a session or picks a notification action.
+// The caller must hold mu.
+func startIconFlash() {
+	if !settings.FlashIconWhenFinished {
+		return
+	}
+	stopIconFlash()
+	stop := make(chan struct{})
+	iconFlashStop = stop
+
+	go func() {
+		ticker := time.NewTicker(iconFlashInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				mu.Lock()
+				if iconFlashStop == stop {
+					iconFlashOn = !iconFlashOn
+					setTrayIcon("▶", engine.Count())
+				}
+				mu.Unlock()
+			}
+		}
+	}()
+}
+
+// stopIconFlash stops the flashing and restores the normal icon. The caller must hold mu.
+func stopIconFlash() {
+	if iconFlashStop == nil {
+		return
+	}
+	close(iconFlashStop)
+	iconFlashStop = nil
+	if iconFlashOn {
+		iconFlashOn = false
+		if !engine.Running() {
+			setTrayIcon("▶", engine.Count())
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"log/slog"
+)
+
+// Focus Assist levels, the values of the focus_assist setting.
+const (
+	focusAssistOff      = "off"      // Leave Focus Assist alone
+	focusAssistPriority = "priority" // Priority only
+	focusAssistAlarms   = "alarms"   // Alarms only
+)
+
+var (
+	focusQueue = newCallQueue("Focus Assist") // Focus Assist and Do Not Disturb changes, so restoring never overtakes enabling
+	focusSaved = -1                           // Focus Assist level before the Pomodoro, -1 if not changed; used by the worker only
+)
+
+// focusAssistStarted enables Focus Assist for a Pomodoro, remembering the
+// previous level.
+func focusAssistStarted() {
+	level := 0
+	switch settings.FocusAssist {
+	case focusAssistPriority:
+		level = 1
+	case focusAssistAlarms:
+		level = 2
+	default:
+		return
+	}
+	focusQueue.enqueue(func() {
+		if focusSaved < 0 {
+			current, err := getFocusAssist()
+			if err != nil {
+				slog.Error("Failed to read Focus Assist", "err", err)
+				return
+			}
+			focusSaved = current
+		}
+		if err := setFocusAssist(level); err != nil {
+			slog.Error("Failed to enable Focus Assist", "err", err)
+		}
+	})
+}
+
+// focusAssistEnded restores the Focus Assist level from before the Pomodoro.
+func focusAssistEnded() {
+	focusQueue.enqueueRestore(func() {
+		if focusSaved < 0 {
+			return
+		}
+		if err := setFocusAssist(focusSaved); err != nil {
+			slog.Error("Failed to restore Focus Assist", "err", err)
+		}
+		focusSaved = -1
+	})
+}
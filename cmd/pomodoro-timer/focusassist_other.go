@@ -0,0 +1,15 @@
+//go:build !windows
+
+package main
+
+import "fmt"
+
+// getFocusAssist reports that Focus Assist only exists on Windows.
+func getFocusAssist() (int, error) {
+	return 0, fmt.Errorf("Focus Assist is only supported on Windows")
+}
+
+// setFocusAssist reports that Focus Assist only exists on Windows.
+func setFocusAssist(level int) error {
+	return fmt.Errorf("Focus Assist is only supported on Windows")
+}
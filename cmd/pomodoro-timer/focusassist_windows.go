@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	ntdll                    = windows.NewLazySystemDLL("ntdll.dll")
+	procNtQueryWnfStateData  = ntdll.NewProc("NtQueryWnfStateData")
+	procNtUpdateWnfStateData = ntdll.NewProc("NtUpdateWnfStateData")
+)
+
+// wnfQuietHoursProfile is the undocumented WNF state holding the Focus Assist
+// level: 0 off, 1 priority only, 2 alarms only. Windows has no public API
+// for Focus Assist; the Action Center toggles it the same way.
+const wnfQuietHoursProfile uint64 = 0x0D83063EA3BF1C75
+
+// getFocusAssist returns the current Focus Assist level.
+func getFocusAssist() (int, error) {
+	name := wnfQuietHoursProfile
+	var stamp, value uint32
+	size := uint32(unsafe.Sizeof(value))
+	status, _, _ := procNtQueryWnfStateData.Call(uintptr(unsafe.Pointer(&name)), 0, 0,
+		uintptr(unsafe.Pointer(&stamp)), uintptr(unsafe.Pointer(&value)), uintptr(unsafe.Pointer(&size)))
+	if status != 0 {
+		return 0, fmt.Errorf("NtQueryWnfStateData failed: 0x%X", status)
+	}
+	return int(value), nil
+}
+
+// setFocusAssist sets the Focus Assist level.
+func setFocusAssist(level int) error {
+	name := wnfQuietHoursProfile
+	value := uint32(level)
+	status, _, _ := procNtUpdateWnfStateData.Call(uintptr(unsafe.Pointer(&name)),
+		uintptr(unsafe.Pointer(&value)), unsafe.Sizeof(value), 0, 0, 0, 0)
+	if status != 0 {
+		return fmt.Errorf("NtUpdateWnfStateData failed: 0x%X", status)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"pomodoro-timer/pkg/pomodoro"
+)
+
+const maxFocusUntil = 12 * time.Hour // Times further ahead are taken as typos
+
+// timeOfDayLayouts are the accepted formats of a time to focus until.
+var timeOfDayLayouts = []string{"15:04", "15.04", "3:04pm", "3:04 pm", "3pm", "3 pm"}
+
+// parseTimeOfDay returns the next time the clock shows the given time of day,
+// e.g. "11:00" or "2:30pm", after now.
+func parseTimeOfDay(s string, now time.Time) (time.Time, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, layout := range timeOfDayLayouts {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		at := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+		if !at.After(now) {
+			at = at.AddDate(0, 0, 1)
+		}
+		if at.Sub(now) > maxFocusUntil {
+			return time.Time{}, fmt.Errorf("%s is more than %d hours away", at.Format("15:04"), int(maxFocusUntil.Hours()))
+		}
+		return at, nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not a time like 11:00 or 2:30pm", s)
+}
+
+// focusUntil starts a Pomodoro ending at the given time of day, whatever the
+// configured length, e.g. to work up to a meeting.
+func focusUntil(s string) error {
+	at, err := parseTimeOfDay(s, time.Now())
+	if err != nil {
+		return err
+	}
+	duration := time.Until(at).Round(time.Second)
+	if duration < time.Minute {
+		return fmt.Errorf("%s is less than a minute away", at.Format("15:04"))
+	}
+	slog.Info("Focusing until", "time", at.Format("15:04"))
+	handleTimerClick(pomodoro.Pomodoro, duration)
+	return nil
+}
+
+// openFocusUntil asks for the time to focus until in the text editor,
+// suggesting the next full hour, and starts the Pomodoro.
+func openFocusUntil() {
+	suggestion := time.Now().Truncate(time.Hour).Add(time.Hour)
+	text := "# Focus until what time? E.g. 11:00 or 2:30pm. Lines starting with # are ignored.\n" + suggestion.Format("15:04") + "\n"
+	data, err := editInEditor("pomodoro_focus_until_*.txt", []byte(text))
+	if err != nil {
+		slog.Error("Failed to edit the time to focus until", "err", err)
+		return
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := focusUntil(line); err != nil {
+			slog.Error("Failed to focus until", "err", err)
+			go sendNotification(tr("Focus not started"), err.Error())
+		}
+		return
+	}
+}
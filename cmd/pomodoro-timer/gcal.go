@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lutischan-ferenc/systray"
+	"golang.org/x/oauth2"
+)
+
+// GoogleCalendarSettings configures the Google Calendar integration.
+type GoogleCalendarSettings struct {
+	ClientID     string `json:"client_id"`     // OAuth client ID of a Google Cloud "Desktop app" with the Calendar API enabled
+	ClientSecret string `json:"client_secret"` // Client secret of the OAuth client
+	CalendarID   string `json:"calendar_id"`   // Calendar to write to, "primary" if empty
+	LogPomodoros bool   `json:"log_pomodoros"` // Create an event for each completed Pomodoro
+	BusyBlocks   bool   `json:"busy_blocks"`   // Block the time as busy while a Pomodoro runs
+	EventTitle   string `json:"event_title"`   // Title of the events, followed by the task if any
+}
+
+const googleCalendarAPIURL = "https://www.googleapis.com/calendar/v3"
+
+var (
+	googleToken   *oauth2.Token // Token of the connected account, nil if not connected
+	googleTokenMu sync.Mutex    // Mutex for googleToken
+	googleBusyID  string        // ID of the busy block of the running Pomodoro, used by the queue worker only
+
+	googleQueue = newCallQueue("Google Calendar") // Calendar calls, run in order
+
+	mGoogleCalendar *systray.MenuItem // Menu item for connecting or disconnecting Google Calendar
+)
+
+// getGoogleTokenPath returns the path to the stored Google token.
+func getGoogleTokenPath() string {
+	return getCacheFilePath("google_token.json")
+}
+
+// googleConfig returns the OAuth configuration for Google.
+func googleConfig() *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     settings.GoogleCalendar.ClientID,
+		ClientSecret: settings.GoogleCalendar.ClientSecret,
+		Scopes:       []string{"https://www.googleapis.com/auth/calendar.events"},
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  "https://accounts.google.com/o/oauth2/auth",
+			TokenURL: "https://oauth2.googleapis.com/token",
+		},
+	}
+}
+
+// loadGoogleToken loads the stored Google token.
+func loadGoogleToken() {
+	data, err := ioutil.ReadFile(getGoogleTokenPath())
+	if err != nil {
+		return
+	}
+	var token oauth2.Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		slog.Error("Failed to load Google token", "err", err)
+		return
+	}
+	googleTokenMu.Lock()
+	googleToken = &token
+	googleTokenMu.Unlock()
+}
+
+// saveGoogleToken stores the Google token, or removes it if token is nil.
+func saveGoogleToken(token *oauth2.Token) {
+	googleTokenMu.Lock()
+	googleToken = token
+	googleTokenMu.Unlock()
+
+	if token == nil {
+		os.Remove(getGoogleTokenPath())
+		return
+	}
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		slog.Error("Failed to save Google token", "err", err)
+		return
+	}
+	if err := ioutil.WriteFile(getGoogleTokenPath(), data, 0600); err != nil {
+		slog.Error("Failed to write Google token", "err", err)
+	}
+}
+
+// addGoogleCalendarMenu adds the Google Calendar connect/disconnect menu item.
+func addGoogleCalendarMenu() {
+	loadGoogleToken()
+	mGoogleCalendar = systray.AddMenuItem("", tr("Log Pomodoros and block focus time in Google Calendar"))
+	mGoogleCalendar.Click(func() {
+		googleTokenMu.Lock()
+		connected := googleToken != nil
+		googleTokenMu.Unlock()
+		if connected {
+			saveGoogleToken(nil)
+			updateGoogleCalendarMenu()
+		} else {
+			go connectGoogleCalendar()
+		}
+	})
+	updateGoogleCalendarMenu()
+}
+
+// updateGoogleCalendarMenu updates the Google Calendar menu item to the connection state.
+func updateGoogleCalendarMenu() {
+	if mGoogleCalendar == nil {
+		return
+	}
+	googleTokenMu.Lock()
+	connected := googleToken != nil
+	googleTokenMu.Unlock()
+	if connected {
+		mGoogleCalendar.SetTitle(tr("Disconnect Google Calendar"))
+	} else {
+		mGoogleCalendar.SetTitle(tr("Connect Google Calendar..."))
+	}
+}
+
+// connectGoogleCalendar signs in to Google in the browser. Google redirects
+// back to a temporary local server, as desktop apps cannot use the device
+// code flow for calendars.
+func connectGoogleCalendar() {
+	if settings.GoogleCalendar.ClientID == "" {
+		slog.Warn("Google Calendar is not configured, set google_calendar.client_id in the settings")
+		sendNotification(tr("Google Calendar"), tr("Set google_calendar.client_id and client_secret in the settings first"))
+		return
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		slog.Error("Failed to start Google sign-in", "err", err)
+		return
+	}
+	secret := make([]byte, 16)
+	if _, err := rand.Read(secret); err != nil {
+		listener.Close()
+		slog.Error("Failed to start Google sign-in", "err", err)
+		return
+	}
+	state := hex.EncodeToString(secret)
+	config := googleConfig()
+	config.RedirectURL = "http://" + listener.Addr().String() + "/"
+	verifier := oauth2.GenerateVerifier()
+
+	codes := make(chan string, 1)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("state") != state {
+			http.Error(w, "invalid state", http.StatusBadRequest)
+			return
+		}
+		if e := query.Get("error"); e != "" {
+			fmt.Fprintf(w, "Google Calendar was not connected: %s. You can close this tab.", e)
+			codes <- ""
+			return
+		}
+		fmt.Fprint(w, "Google Calendar is connected. You can close this tab.")
+		codes <- query.Get("code")
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	openBrowser(config.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.S256ChallengeOption(verifier),
+		oauth2.SetAuthURLParam("prompt", "consent")))
+
+	var code string
+	select {
+	case code = <-codes:
+	case <-time.After(5 * time.Minute):
+	}
+	if code == "" {
+		slog.Info("Google sign-in was cancelled or timed out")
+		return
+	}
+	token, err := config.Exchange(context.Background(), code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		slog.Error("Google sign-in failed", "err", err)
+		sendNotification(tr("Google Calendar not connected"), err.Error())
+		return
+	}
+	saveGoogleToken(token)
+	updateGoogleCalendarMenu()
+	sendNotification(tr("Google Calendar"), tr("Connected, your Pomodoros are added to your calendar"))
+}
+
+// googleClient returns an HTTP client authorized for Google, or nil if not connected.
+// Refreshed tokens are stored for the next start.
+func googleClient() *http.Client {
+	googleTokenMu.Lock()
+	token := googleToken
+	googleTokenMu.Unlock()
+	if token == nil || settings.GoogleCalendar.ClientID == "" {
+		return nil
+	}
+
+	ctx := context.Background()
+	fresh, err := googleConfig().TokenSource(ctx, token).Token()
+	if err != nil {
+		slog.Error("Failed to refresh Google token", "err", err)
+		return nil
+	}
+	if fresh.AccessToken != token.AccessToken {
+		saveGoogleToken(fresh)
+	}
+	return oauth2.NewClient(ctx, oauth2.StaticTokenSource(fresh))
+}
+
+// googleEventTitle returns the title of a calendar event for the session.
+func googleEventTitle(record SessionRecord) string {
+	title := settings.GoogleCalendar.EventTitle
+	if title == "" {
+		title = "Focus"
+	}
+	if record.Task != "" {
+		title += ": " + record.Task
+	}
+	return title
+}
+
+// googleCalendarStarted blocks the time of a Pomodoro in Google Calendar.
+func googleCalendarStarted(record SessionRecord) {
+	if !settings.GoogleCalendar.BusyBlocks {
+		return
+	}
+	end := record.Start.Add(time.Duration(record.Duration) * time.Minute)
+	event := googleEvent(googleEventTitle(record), record.Start, end, "tentative")
+	googleQueue.enqueue(func() {
+		client := googleClient()
+		if client == nil {
+			return
+		}
+		var created struct {
+			ID string `json:"id"`
+		}
+		if err := googleCall(client, http.MethodPost, "/events", event, &created); err != nil {
+			slog.Error("Failed to create Google Calendar busy block", "err", err)
+			return
+		}
+		googleBusyID = created.ID
+	})
+}
+
+// googleCalendarEnded logs a completed Pomodoro in Google Calendar, turning
+// its busy block into the log entry, and removes the busy block of a stopped one.
+func googleCalendarEnded(record SessionRecord) {
+	gcal := settings.GoogleCalendar
+	if !gcal.BusyBlocks && !gcal.LogPomodoros {
+		return
+	}
+	event := googleEvent(googleEventTitle(record), record.Start, record.End, "confirmed")
+	googleQueue.enqueueRestore(func() {
+		busyID := googleBusyID
+		googleBusyID = ""
+		client := googleClient()
+		if client == nil {
+			return
+		}
+		var err error
+		switch {
+		case busyID != "" && (!record.Completed || !gcal.LogPomodoros):
+			err = googleCall(client, http.MethodDelete, "/events/"+url.PathEscape(busyID), nil, nil)
+		case busyID != "":
+			err = googleCall(client, http.MethodPatch, "/events/"+url.PathEscape(busyID), event, nil)
+		case record.Completed && gcal.LogPomodoros:
+			err = googleCall(client, http.MethodPost, "/events", event, nil)
+		}
+		if err != nil {
+			slog.Error("Failed to update Google Calendar", "err", err)
+		}
+	})
+}
+
+// googleEvent returns a busy calendar event.
+func googleEvent(title string, start, end time.Time, status string) map[string]interface{} {
+	return map[string]interface{}{
+		"summary":      title,
+		"start":        map[string]string{"dateTime": start.Format(time.RFC3339)},
+		"end":          map[string]string{"dateTime": end.Format(time.RFC3339)},
+		"status":       status,
+		"transparency": "opaque",
+	}
+}
+
+// googleCall calls the Calendar API on the configured calendar and decodes
+// the JSON reply into result, if not nil.
+func googleCall(client *http.Client, method, path string, payload, result interface{}) error {
+	calendar := settings.GoogleCalendar.CalendarID
+	if calendar == "" {
+		calendar = "primary"
+	}
+	var body []byte
+	if payload != nil {
+		var err error
+		if body, err = json.Marshal(payload); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, googleCalendarAPIURL+"/calendars/"+url.PathEscape(calendar)+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+	if result != nil {
+		return json.Unmarshal(data, result)
+	}
+	return nil
+}
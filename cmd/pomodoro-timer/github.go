@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// GitHubSettings configures the GitHub issue and pull request tasks.
+type GitHubSettings struct {
+	Token            string `json:"token"`             // Personal access token, needed for private repositories and comments
+	CommentPomodoros bool   `json:"comment_pomodoros"` // Comment on the issue or pull request when a Pomodoro on it completes
+}
+
+const githubAPIURL = "https://api.github.com"
+
+var (
+	githubIssuePattern = regexp.MustCompile(`^https://github\.com/([\w.-]+)/([\w.-]+)/(?:issues|pull)/(\d+)`)
+	githubClient       = &http.Client{Timeout: 15 * time.Second}
+)
+
+// githubIssue is an issue or pull request on GitHub.
+type githubIssue struct {
+	owner, repo, number string
+}
+
+// parseGitHubIssue returns the issue or pull request a task URL points to.
+func parseGitHubIssue(task string) (githubIssue, bool) {
+	m := githubIssuePattern.FindStringSubmatch(task)
+	if m == nil {
+		return githubIssue{}, false
+	}
+	return githubIssue{owner: m[1], repo: m[2], number: m[3]}, true
+}
+
+// String returns the short reference of the issue, e.g. "owner/repo#123".
+func (i githubIssue) String() string {
+	return i.owner + "/" + i.repo + "#" + i.number
+}
+
+// taskLabel returns how a task is shown in the menu: GitHub URLs as their
+// short reference followed by the title.
+func taskLabel(task Task) string {
+	issue, ok := parseGitHubIssue(task.Name)
+	if !ok {
+		return task.Name
+	}
+	if task.Title != "" {
+		return issue.String() + " " + task.Title
+	}
+	return issue.String()
+}
+
+// promptGitHubIssue lets the user paste the URL of a GitHub issue or pull
+// request in the default text editor and makes it the current task.
+func promptGitHubIssue() {
+	text := "# Paste the URL of a GitHub issue or pull request, e.g.\n# https://github.com/owner/repo/issues/123\n"
+	data, err := editInEditor("pomodoro_github_issue_*.txt", []byte(text))
+	if err != nil {
+		slog.Error("Failed to edit GitHub issue", "err", err)
+		return
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		issue, ok := parseGitHubIssue(line)
+		if !ok {
+			slog.Warn("Not a GitHub issue or pull request URL", "url", line)
+			go sendNotification(tr("Not a GitHub issue"), line)
+			return
+		}
+		// Links to a comment or the files of a pull request belong to the same task.
+		url := githubIssuePattern.FindString(line)
+		useTask(url)
+		updateGitHubTitle(url, issue)
+		return
+	}
+}
+
+// updateGitHubTitle stores the title of a GitHub issue in its task.
+func updateGitHubTitle(task string, issue githubIssue) {
+	var result struct {
+		Title string `json:"title"`
+	}
+	path := fmt.Sprintf("/repos/%s/%s/issues/%s", issue.owner, issue.repo, issue.number)
+	if err := githubCall(http.MethodGet, path, nil, &result); err != nil {
+		slog.Error("Failed to read GitHub issue", "err", err)
+		return
+	}
+	tasksMu.Lock()
+	if t := findTask(task); t != nil {
+		t.Title = result.Title
+		saveTasks()
+	}
+	tasksMu.Unlock()
+	updateTaskMenu()
+}
+
+// githubPomodoroCompleted comments on the GitHub issue a completed Pomodoro
+// was spent on, with the time spent on it so far.
+func githubPomodoroCompleted(record SessionRecord) {
+	issue, ok := parseGitHubIssue(record.Task)
+	if !ok || !record.Completed || !settings.GitHub.CommentPomodoros || settings.GitHub.Token == "" {
+		return
+	}
+	go func() {
+		records, err := loadHistory()
+		if err != nil {
+			slog.Error("Failed to load history", "err", err)
+			return
+		}
+		var total sessionStats
+		for _, r := range records {
+			if r.Type == sessionPomodoro && r.Task == record.Task {
+				total.add(r)
+			}
+		}
+		minutes := int(record.End.Sub(record.Start).Minutes())
+		comment := fmt.Sprintf("🍅 Spent a %d-minute Pomodoro on this (%d Pomodoros, %s so far).",
+			minutes, total.pomodoros, formatFocusTime(total.focus))
+		path := fmt.Sprintf("/repos/%s/%s/issues/%s/comments", issue.owner, issue.repo, issue.number)
+		if err := githubCall(http.MethodPost, path, map[string]string{"body": comment}, nil); err != nil {
+			slog.Error("Failed to comment on GitHub issue", "err", err)
+		}
+	}()
+}
+
+// githubCall calls the GitHub REST API and decodes the JSON reply into result, if not nil.
+func githubCall(method, path string, payload, result interface{}) error {
+	var body []byte
+	if payload != nil {
+		var err error
+		if body, err = json.Marshal(payload); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, githubAPIURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+	if token := settings.GitHub.Token; token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := githubClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+	if result != nil {
+		return json.Unmarshal(data, result)
+	}
+	return nil
+}
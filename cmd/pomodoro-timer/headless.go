@@ -0,0 +1,54 @@
+package main
+
+import (
+	"log/slog"
+	"sync"
+
+	"github.com/lutischan-ferenc/systray"
+)
+
+var (
+	headlessQuit     = make(chan struct{}) // Closed to end a headless run
+	headlessQuitOnce sync.Once
+
+	tooltipMu   sync.Mutex
+	lastTooltip string // Tooltip shown on the tray icon, guarded by tooltipMu
+)
+
+// runHeadless runs the timer without a tray icon, for servers, WSL and
+// desktops without a tray. It is controlled through the command line, the
+// REST API, MQTT and D-Bus, and runs until quitApp is called.
+func runHeadless() {
+	slog.Info("Running headless")
+	startServices()
+	startFirstSession()
+	<-headlessQuit
+	onExit()
+}
+
+// quitApp quits the app: the tray, or the headless run.
+func quitApp() {
+	if !launch.headless {
+		systray.Quit()
+		return
+	}
+	headlessQuitOnce.Do(func() {
+		close(headlessQuit)
+	})
+}
+
+// setTooltip sets the tooltip of the tray icon, if there is one. An unchanged
+// tooltip is not pushed to the tray again, which some shells redraw with a
+// flicker while it is shown.
+func setTooltip(text string) {
+	if launch.headless {
+		return
+	}
+	tooltipMu.Lock()
+	defer tooltipMu.Unlock()
+	if text == lastTooltip {
+		return
+	}
+	lastTooltip = text
+	systray.SetTooltip(text)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"image/color"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"pomodoro-timer/internal/icon"
+)
+
+// heatmapPalette colors the heatmap like the GitHub contribution graph.
+var heatmapPalette = icon.Palette{
+	Background: color.RGBA{0xFF, 0xFF, 0xFF, 0xFF},
+	Text:       color.RGBA{0x24, 0x29, 0x2F, 0xFF},
+	Dots:       color.RGBA{0x21, 0x6E, 0x39, 0xFF},
+}
+
+// heatmapPath returns the path of the heatmap exported today, in the home directory.
+func heatmapPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+	return filepath.Join(home, "pomodoro-timer-heatmap-"+time.Now().Format("2006-01-02")+".png")
+}
+
+// exportHeatmap draws the completed Pomodoros per day of the last year as a
+// PNG in the home directory and opens it.
+func exportHeatmap() {
+	records, err := loadHistory()
+	if err != nil {
+		slog.Error("Failed to load history", "err", err)
+		return
+	}
+	counts := map[string]int{}
+	for _, record := range records {
+		if record.Type == sessionPomodoro && record.Completed {
+			counts[record.Start.Local().Format(icon.HeatmapDateLayout)]++
+		}
+	}
+
+	path := heatmapPath()
+	if err := ioutil.WriteFile(path, icon.Heatmap(counts, time.Now(), heatmapPalette), 0644); err != nil {
+		slog.Error("Failed to write heatmap", "err", err)
+		sendNotification(tr("Export failed"), err.Error())
+		return
+	}
+	slog.Info("Exported heatmap", "path", path)
+	sendNotification(tr("Heatmap exported"), path)
+	openBrowser(path)
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lutischan-ferenc/systray"
+	"pomodoro-timer/pkg/pomodoro"
+)
+
+// Session types stored in the history.
+const (
+	sessionPomodoro = "pomodoro"
+	sessionBreak    = "break"
+)
+
+// Interruption kinds, following the Pomodoro Technique.
+const (
+	interruptionInternal = "internal"
+	interruptionExternal = "external"
+)
+
+// Interruption records a distraction during a Pomodoro.
+type Interruption struct {
+	Time time.Time `json:"time"` // When the interruption was logged
+	Kind string    `json:"kind"` // "internal" or "external"
+}
+
+// SessionRecord is an entry of the session history.
+type SessionRecord struct {
+	Type          string         `json:"type"`                     // "pomodoro" or "break"
+	Start         time.Time      `json:"start"`                    // Start time of the session
+	End           time.Time      `json:"end"`                      // Time the session completed or was stopped
+	Duration      int            `json:"duration"`                 // Planned duration in minutes
+	Completed     bool           `json:"completed"`                // False if the session was stopped early
+	Task          string         `json:"task,omitempty"`           // Task the session was spent on
+	Tag           string         `json:"tag,omitempty"`            // Tag of the session, without the leading '#'
+	Interruptions []Interruption `json:"interruptions,omitempty"`  // Interruptions logged during the session
+	Note          string         `json:"note,omitempty"`           // Free-form note added after the session
+	MeetingMuted  bool           `json:"meeting_muted,omitempty"`  // Sounds were silenced because a meeting was detected
+	PausedSeconds int            `json:"paused_seconds,omitempty"` // Time the session was paused
+	Distractions  []Distraction  `json:"distractions,omitempty"`   // Distracting processes found running during the session
+}
+
+var (
+	currentSession *SessionRecord // Record of the running session, guarded by mu
+
+	mInternalInterruption *systray.MenuItem // Menu item for logging an internal interruption
+	mExternalInterruption *systray.MenuItem // Menu item for logging an external interruption
+)
+
+// getHistoryPath returns the path to the session history file.
+func getHistoryPath() string {
+	return getDataFilePath("history.jsonl")
+}
+
+// appendHistory appends a session record to the history file, one JSON object per line.
+func appendHistory(record SessionRecord) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		slog.Error("Failed to encode session record", "err", err)
+		return
+	}
+
+	f, err := os.OpenFile(getHistoryPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		slog.Error("Failed to open history file", "err", err)
+		return
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		slog.Error("Failed to write history file", "err", err)
+	}
+}
+
+// loadHistory reads all session records from the history file.
+func loadHistory() ([]SessionRecord, error) {
+	f, err := os.Open(getHistoryPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []SessionRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record SessionRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			slog.Error("Skipping invalid history record", "err", err)
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// writeHistory replaces the history file with the given records.
+func writeHistory(records []SessionRecord) error {
+	var buf bytes.Buffer
+	for _, record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	tempPath := getHistoryPath() + ".tmp"
+	if err := ioutil.WriteFile(tempPath, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, getHistoryPath())
+}
+
+// beginSession starts recording the session the engine started and
+// announces it. The caller must hold mu.
+func beginSession() {
+	timer := engine.Session()
+	sessionType := sessionBreak
+	task := ""
+	if timer.Kind == pomodoro.Pomodoro {
+		sessionType = sessionPomodoro
+		task = currentTaskName()
+	}
+	currentSession = &SessionRecord{
+		Type:     sessionType,
+		Start:    timer.Start,
+		Duration: int(timer.Duration.Minutes()),
+		Task:     task,
+		Tag:      settings.CurrentTag,
+	}
+	publish(sessionStarted, sessionInfo{timer: timer, record: currentSession})
+}
+
+// endSession writes the running session to the history and announces its
+// end. The caller must hold mu.
+func endSession(completed bool) {
+	if currentSession == nil {
+		return
+	}
+	record := *currentSession
+	currentSession = nil
+	record.End = time.Now()
+	record.Completed = completed
+	appendHistory(record)
+
+	event := sessionAborted
+	if completed {
+		event = sessionCompleted
+	}
+	publish(event, sessionInfo{timer: engine.Session(), record: &record})
+}
+
+// addInterruptionMenu adds the interruption logging actions to the system tray.
+func addInterruptionMenu() {
+	mInternalInterruption = systray.AddMenuItem(tr("Log Internal Interruption"), tr("Record a distraction that came from yourself"))
+	mInternalInterruption.Click(func() {
+		logInterruption(interruptionInternal)
+	})
+	mExternalInterruption = systray.AddMenuItem(tr("Log External Interruption"), tr("Record a distraction caused by someone else"))
+	mExternalInterruption.Click(func() {
+		logInterruption(interruptionExternal)
+	})
+	updateInterruptionMenu()
+}
+
+// updateInterruptionMenu enables the interruption actions only while a Pomodoro is running.
+func updateInterruptionMenu() {
+	if mInternalInterruption == nil {
+		return
+	}
+	if currentSession != nil && currentSession.Type == sessionPomodoro {
+		mInternalInterruption.Enable()
+		mExternalInterruption.Enable()
+	} else {
+		mInternalInterruption.Disable()
+		mExternalInterruption.Disable()
+	}
+}
+
+// logInterruption records an interruption of the given kind in the running Pomodoro.
+func logInterruption(kind string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if currentSession == nil || currentSession.Type != sessionPomodoro {
+		return
+	}
+	currentSession.Interruptions = append(currentSession.Interruptions, Interruption{
+		Time: time.Now(),
+		Kind: kind,
+	})
+}
+
+// addNoteToLastPomodoro lets the user write a note for the most recent
+// completed Pomodoro in the default text editor and stores it in the history.
+func addNoteToLastPomodoro() {
+	records, err := loadHistory()
+	if err != nil {
+		slog.Error("Failed to load history", "err", err)
+		return
+	}
+
+	last := -1
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].Type == sessionPomodoro && records[i].Completed {
+			last = i
+			break
+		}
+	}
+	if last < 0 {
+		slog.Info("No completed Pomodoro to add a note to")
+		return
+	}
+
+	record := records[last]
+	header := fmt.Sprintf("# Note for the Pomodoro of %s - %s, written below this line.",
+		record.Start.Format("2006-01-02 15:04"), record.End.Format("15:04"))
+	data, err := editInEditor("pomodoro_note_*.txt", []byte(header+"\n"+record.Note+"\n"))
+	if err != nil {
+		slog.Error("Failed to edit note", "err", err)
+		return
+	}
+	note := editedNote(header, string(data))
+
+	// Re-read the history so sessions finished while the editor was open are kept
+	mu.Lock()
+	defer mu.Unlock()
+	records, err = loadHistory()
+	if err != nil {
+		slog.Error("Failed to load history", "err", err)
+		return
+	}
+	for i := range records {
+		if records[i].Type == sessionPomodoro && records[i].Start.Equal(record.Start) {
+			records[i].Note = note
+			if err := writeHistory(records); err != nil {
+				slog.Error("Failed to write history file", "err", err)
+			}
+			return
+		}
+	}
+}
+
+// editedNote returns the note saved in the editor without the header line,
+// so notes may start with "#", e.g. "#bug found in the parser".
+func editedNote(header, data string) string {
+	data = strings.ReplaceAll(strings.TrimPrefix(data, "\ufeff"), "\r\n", "\n")
+	if first, rest, _ := strings.Cut(data, "\n"); strings.TrimSpace(first) == header {
+		data = rest
+	}
+	return strings.TrimSpace(data)
+}
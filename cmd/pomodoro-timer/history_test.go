@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+func TestEditedNote(t *testing.T) {
+	const header = "# Note for the Pomodoro of 2026-10-17 09:00 - 09:25, written below this line."
+	for _, test := range []struct {
+		data, want string
+	}{
+		{header + "\nFixed the parser\n", "Fixed the parser"},
+		{header + "\r\n#bug found in the parser\r\nsecond line\r\n", "#bug found in the parser\nsecond line"},
+		{"\ufeff" + header + "\n# heading\n", "# heading"},
+		{header + "\n\n", ""},
+		{"Header deleted\n", "Header deleted"},
+	} {
+		if got := editedNote(header, test.data); got != test.want {
+			t.Errorf("editedNote(%q) = %q, want %q", test.data, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// haEntity is an entity announced to Home Assistant by MQTT discovery.
+type haEntity struct {
+	component string // "sensor" or "button"
+	id        string // Object ID, unique within the device
+	config    map[string]interface{}
+}
+
+// haEntities are the sensors and buttons of the timer in Home Assistant.
+var haEntities = []haEntity{
+	{"sensor", "state", map[string]interface{}{"name": "State", "icon": "mdi:timer-outline", "value_template": "{{ value_json.state }}"}},
+	{"sensor", "phase", map[string]interface{}{"name": "Phase", "icon": "mdi:coffee-outline", "value_template": "{{ value_json.phase }}"}},
+	{"sensor", "remaining", map[string]interface{}{"name": "Remaining", "icon": "mdi:timer-sand", "unit_of_measurement": "min", "state_class": "measurement"}},
+	{"sensor", "pomodoro_count", map[string]interface{}{"name": "Pomodoros in cycle", "icon": "mdi:counter", "value_template": "{{ value_json.pomodoro_count }}"}},
+	{"sensor", "task", map[string]interface{}{"name": "Task", "icon": "mdi:clipboard-text-outline", "value_template": "{{ value_json.task | default('') }}"}},
+	{"button", "start", map[string]interface{}{"name": "Start Pomodoro", "icon": "mdi:play", "payload_press": "start"}},
+	{"button", "start_break", map[string]interface{}{"name": "Start break", "icon": "mdi:coffee", "payload_press": "start break"}},
+	{"button", "pause", map[string]interface{}{"name": "Pause", "icon": "mdi:pause", "payload_press": "pause"}},
+	{"button", "resume", map[string]interface{}{"name": "Resume", "icon": "mdi:play-pause", "payload_press": "resume"}},
+	{"button", "skip", map[string]interface{}{"name": "Skip", "icon": "mdi:skip-next", "payload_press": "skip"}},
+	{"button", "stop", map[string]interface{}{"name": "Stop", "icon": "mdi:stop", "payload_press": "stop"}},
+}
+
+var haInvalidID = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// haNodeID returns the ID of this computer's timer in Home Assistant.
+func haNodeID() string {
+	hostname, _ := os.Hostname()
+	return haInvalidID.ReplaceAllString(appDirName+"_"+hostname, "_")
+}
+
+// haConfigTopic returns the discovery topic of an entity.
+func haConfigTopic(config MQTTSettings, entity haEntity) string {
+	prefix := config.DiscoveryPrefix
+	if prefix == "" {
+		prefix = "homeassistant"
+	}
+	return prefix + "/" + entity.component + "/" + haNodeID() + "/" + entity.id + "/config"
+}
+
+// publishHomeAssistantDiscovery announces the timer to Home Assistant as a
+// device with sensors and buttons.
+func publishHomeAssistantDiscovery(client mqtt.Client, config MQTTSettings) {
+	nodeID := haNodeID()
+	hostname, _ := os.Hostname()
+	device := map[string]interface{}{
+		"identifiers": []string{nodeID},
+		"name":        "Pomodoro Timer " + hostname,
+		"model":       "Pomodoro Timer",
+	}
+	for _, entity := range haEntities {
+		payload := map[string]interface{}{
+			"unique_id":          nodeID + "_" + entity.id,
+			"availability_topic": config.topic("availability"),
+			"device":             device,
+		}
+		for key, value := range entity.config {
+			payload[key] = value
+		}
+		switch {
+		case entity.component == "button":
+			payload["command_topic"] = config.topic("command")
+		case entity.id == "remaining":
+			payload["state_topic"] = config.topic("remaining")
+		default:
+			payload["state_topic"] = config.topic("state")
+		}
+		data, _ := json.Marshal(payload)
+		publishMQTT(client, haConfigTopic(config, entity), true, string(data))
+	}
+}
+
+// removeHomeAssistantDiscovery removes the timer's entities from Home
+// Assistant by clearing their retained discovery messages.
+func removeHomeAssistantDiscovery(client mqtt.Client, config MQTTSettings) {
+	for _, entity := range haEntities {
+		client.Publish(haConfigTopic(config, entity), 1, true, "").WaitTimeout(mqttTimeout)
+	}
+}
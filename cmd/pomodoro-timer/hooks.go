@@ -0,0 +1,59 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// hookCommand returns the shell command configured for a timer event, or ""
+// if there is none.
+func hookCommand(event string) string {
+	switch event {
+	case eventPomodoroStart:
+		return settings.OnPomodoroStart
+	case eventPomodoroEnd:
+		return settings.OnPomodoroEnd
+	case eventBreakStart:
+		return settings.OnBreakStart
+	case eventBreakEnd:
+		return settings.OnBreakEnd
+	case eventStop:
+		return settings.OnStop
+	}
+	return ""
+}
+
+// runHook runs the shell command configured for the event in the background,
+// describing the event in POMODORO_* environment variables. The caller must
+// hold mu.
+func runHook(event string, record *SessionRecord) {
+	command := strings.TrimSpace(hookCommand(event))
+	if command == "" {
+		return
+	}
+
+	env := append(os.Environ(),
+		"POMODORO_EVENT="+event,
+		"POMODORO_REMAINING_SECONDS="+strconv.Itoa(int(engine.Remaining().Seconds())),
+		"POMODORO_COUNT="+strconv.Itoa(engine.Count()),
+		"POMODORO_PROFILE="+settings.Profile,
+	)
+	if record != nil {
+		env = append(env,
+			"POMODORO_SESSION_TYPE="+record.Type,
+			"POMODORO_DURATION="+strconv.Itoa(record.Duration),
+			"POMODORO_TASK="+record.Task,
+			"POMODORO_TAG="+record.Tag,
+		)
+	}
+
+	go func() {
+		cmd := shellCommand(command)
+		cmd.Env = env
+		if output, err := cmd.CombinedOutput(); err != nil {
+			slog.Error("Hook failed", "command", command, "event", event, "err", err, "output", string(output))
+		}
+	}()
+}
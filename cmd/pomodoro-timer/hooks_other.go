@@ -0,0 +1,10 @@
+//go:build !windows
+
+package main
+
+import "os/exec"
+
+// shellCommand returns /bin/sh running command.
+func shellCommand(command string) *exec.Cmd {
+	return exec.Command("/bin/sh", "-c", command)
+}
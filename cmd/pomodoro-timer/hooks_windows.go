@@ -0,0 +1,22 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// shellCommand returns a hidden cmd.exe running command, passed unquoted so
+// that cmd.exe parses it as typed.
+func shellCommand(command string) *exec.Cmd {
+	shell := os.Getenv("ComSpec")
+	if shell == "" {
+		shell = "cmd.exe"
+	}
+	cmd := exec.Command(shell)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		HideWindow: true,
+		CmdLine:    `"` + shell + `" /S /C "` + command + `"`,
+	}
+	return cmd
+}
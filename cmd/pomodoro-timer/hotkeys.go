@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HotkeySettings holds the system-wide keyboard shortcuts, e.g. "Ctrl+Alt+P".
+// An empty shortcut is not registered.
+type HotkeySettings struct {
+	StartStop      string `json:"start_stop"`       // Stop the running session or start the next one
+	PauseResume    string `json:"pause_resume"`     // Pause or resume the running session
+	Skip           string `json:"skip"`             // End the running session and start the next one
+	AddFiveMinutes string `json:"add_five_minutes"` // Add 5 minutes to the running session
+}
+
+// Hotkey modifiers, with the values of the Windows MOD_ constants.
+const (
+	modAlt     = 0x1
+	modControl = 0x2
+	modShift   = 0x4
+	modWin     = 0x8
+)
+
+// hotkey is a parsed keyboard shortcut.
+type hotkey struct {
+	modifiers uint32 // Combination of the mod constants
+	key       uint32 // Windows virtual-key code
+}
+
+// hotkeyBinding is a shortcut and the action it triggers.
+type hotkeyBinding struct {
+	keys   string
+	action func()
+}
+
+// hotkeyBindings returns the configured shortcuts with their actions.
+func hotkeyBindings(h HotkeySettings) []hotkeyBinding {
+	return []hotkeyBinding{
+		{h.StartStop, handleTrayClick},
+		{h.PauseResume, togglePause},
+		{h.Skip, skipSession},
+		{h.AddFiveMinutes, func() { extendSession(extendDuration) }},
+	}
+}
+
+// hotkeyKeyNames maps the names of keys other than letters, digits and
+// function keys to their virtual-key codes.
+var hotkeyKeyNames = map[string]uint32{
+	"space": 0x20, "enter": 0x0D, "tab": 0x09, "esc": 0x1B, "escape": 0x1B,
+	"backspace": 0x08, "insert": 0x2D, "delete": 0x2E, "home": 0x24, "end": 0x23,
+	"pageup": 0x21, "pagedown": 0x22, "left": 0x25, "up": 0x26, "right": 0x27, "down": 0x28,
+	"plus": 0xBB, "minus": 0xBD, "pause": 0x13,
+}
+
+// parseHotkey parses a shortcut like "Ctrl+Alt+P". At least one modifier
+// (Ctrl, Alt, Shift or Win) is required, so plain typing is not captured.
+func parseHotkey(s string) (hotkey, error) {
+	var h hotkey
+	parts := strings.Split(s, "+")
+	for i, part := range parts {
+		name := strings.ToLower(strings.TrimSpace(part))
+		if i < len(parts)-1 {
+			switch name {
+			case "ctrl", "control":
+				h.modifiers |= modControl
+			case "alt":
+				h.modifiers |= modAlt
+			case "shift":
+				h.modifiers |= modShift
+			case "win", "cmd", "super":
+				h.modifiers |= modWin
+			default:
+				return h, fmt.Errorf("%q is not a modifier like Ctrl, Alt, Shift or Win", part)
+			}
+			continue
+		}
+
+		var fn int
+		switch {
+		case len(name) == 1 && (name[0] >= 'a' && name[0] <= 'z' || name[0] >= '0' && name[0] <= '9'):
+			h.key = uint32(strings.ToUpper(name)[0])
+		case hotkeyKeyNames[name] != 0:
+			h.key = hotkeyKeyNames[name]
+		case len(name) > 1 && name[0] == 'f':
+			if _, err := fmt.Sscanf(name, "f%d", &fn); err == nil && fn >= 1 && fn <= 24 {
+				h.key = 0x70 + uint32(fn-1)
+			}
+		}
+		if h.key == 0 {
+			return h, fmt.Errorf("%q is not a key like P, 5, F9, Space or Plus", part)
+		}
+	}
+	if h.modifiers == 0 {
+		return h, fmt.Errorf("%q needs a modifier like Ctrl or Alt", s)
+	}
+	return h, nil
+}
+
+// validateHotkeys clears shortcuts that cannot be parsed and returns a
+// description of each.
+func validateHotkeys(h *HotkeySettings) []string {
+	var problems []string
+	for _, field := range []struct {
+		key   string
+		value *string
+	}{
+		{"hotkeys.start_stop", &h.StartStop},
+		{"hotkeys.pause_resume", &h.PauseResume},
+		{"hotkeys.skip", &h.Skip},
+		{"hotkeys.add_five_minutes", &h.AddFiveMinutes},
+	} {
+		if *field.value == "" {
+			continue
+		}
+		if _, err := parseHotkey(*field.value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v; the shortcut is disabled", field.key, err))
+			*field.value = ""
+		}
+	}
+	return problems
+}
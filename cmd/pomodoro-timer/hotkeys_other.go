@@ -0,0 +1,16 @@
+//go:build !windows
+
+package main
+
+import "log/slog"
+
+// updateHotkeys reports that system-wide shortcuts are not supported on this
+// platform if any are configured.
+func updateHotkeys() {
+	for _, binding := range hotkeyBindings(settings.Hotkeys) {
+		if binding.keys != "" {
+			slog.Warn("Keyboard shortcuts are only supported on Windows")
+			return
+		}
+	}
+}
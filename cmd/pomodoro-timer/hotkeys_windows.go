@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	procRegisterHotKey     = user32.NewProc("RegisterHotKey")
+	procUnregisterHotKey   = user32.NewProc("UnregisterHotKey")
+	procPostThreadMessageW = user32.NewProc("PostThreadMessageW")
+
+	hotkeyOnce     sync.Once
+	hotkeyThreadID uint32 // Thread owning the hotkeys, 0 until they are registered
+	hotkeyMu       sync.Mutex
+)
+
+const (
+	wmHotkey    = 0x0312
+	modNoRepeat = 0x4000 // Do not repeat while the keys are held down
+)
+
+// updateHotkeys registers the shortcuts from the settings, replacing the
+// previously registered ones.
+func updateHotkeys() {
+	hotkeyOnce.Do(func() {
+		go runHotkeys()
+	})
+	hotkeyMu.Lock()
+	threadID := hotkeyThreadID
+	hotkeyMu.Unlock()
+	if threadID != 0 {
+		procPostThreadMessageW.Call(uintptr(threadID), wmApp, 0, 0)
+	}
+}
+
+// runHotkeys owns the hotkeys, which belong to the thread that registered
+// them, and runs their actions.
+func runHotkeys() {
+	runtime.LockOSThread()
+	bindings := registerHotkeys(0)
+	hotkeyMu.Lock()
+	hotkeyThreadID = windows.GetCurrentThreadId()
+	hotkeyMu.Unlock()
+
+	var m struct {
+		Hwnd    uintptr
+		Message uint32
+		WParam  uintptr
+		LParam  uintptr
+		Time    uint32
+		Pt      struct{ X, Y int32 }
+	}
+	for {
+		ret, _, _ := procGetMessageW.Call(uintptr(unsafe.Pointer(&m)), 0, 0, 0)
+		if int32(ret) <= 0 {
+			return
+		}
+		switch m.Message {
+		case wmHotkey:
+			if id := int(m.WParam) - 1; id >= 0 && id < len(bindings) {
+				go bindings[id].action()
+			}
+		case wmApp:
+			bindings = registerHotkeys(len(bindings))
+		}
+	}
+}
+
+// registerHotkeys unregisters the previous count hotkeys and registers the
+// configured ones on the current thread. Hotkey IDs are binding indexes + 1.
+func registerHotkeys(previous int) []hotkeyBinding {
+	for id := 1; id <= previous; id++ {
+		procUnregisterHotKey.Call(0, uintptr(id))
+	}
+	bindings := hotkeyBindings(settings.Hotkeys)
+	for i, binding := range bindings {
+		if binding.keys == "" {
+			continue
+		}
+		h, err := parseHotkey(binding.keys)
+		if err != nil {
+			continue // Reported by validateSettings
+		}
+		ret, _, err := procRegisterHotKey.Call(0, uintptr(i+1), uintptr(h.modifiers|modNoRepeat), uintptr(h.key))
+		if ret == 0 {
+			slog.Error("Failed to register hotkey", "keys", binding.keys, "err", err)
+			go sendNotification(tr("Shortcut not available"), fmt.Sprintf(tr("%s is already used by another application"), binding.keys))
+		}
+	}
+	return bindings
+}
@@ -0,0 +1,378 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lutischan-ferenc/systray"
+)
+
+// HueSettings configures coloring Philips Hue lights by the session.
+type HueSettings struct {
+	Bridge        string   `json:"bridge"`         // IP address of the Hue bridge, found automatically when connecting
+	Username      string   `json:"username"`       // Key of the app on the bridge, created by "Connect Hue Bridge..."
+	Lights        []string `json:"lights"`         // IDs or names of the lights to color
+	Brightness    int      `json:"brightness"`     // Brightness of the lights in percent, 1-100
+	PomodoroColor string   `json:"pomodoro_color"` // Hex color of the lights during Pomodoros
+	BreakColor    string   `json:"break_color"`    // Hex color of the lights during breaks
+	PomodoroScene string   `json:"pomodoro_scene"` // ID of a scene recalled during Pomodoros instead of the color
+	BreakScene    string   `json:"break_scene"`    // ID of a scene recalled during breaks instead of the color
+}
+
+// hueLightState is the state of a light in the Hue API.
+type hueLightState struct {
+	On   bool      `json:"on"`
+	Bri  int       `json:"bri,omitempty"`
+	XY   []float64 `json:"xy,omitempty"`
+	CT   int       `json:"ct,omitempty"`
+	Mode string    `json:"colormode,omitempty"`
+}
+
+// hueLight is a light as listed by the Hue API.
+type hueLight struct {
+	Name  string        `json:"name"`
+	State hueLightState `json:"state"`
+}
+
+const hueDiscoveryURL = "https://discovery.meethue.com/"
+
+var (
+	hueClient = &http.Client{Timeout: 10 * time.Second}
+
+	mHue *systray.MenuItem // Menu item for connecting or disconnecting the Hue bridge
+)
+
+// validateHue checks the Hue colors and brightness, returning the problems
+// found. Invalid values are replaced by the defaults.
+func validateHue(h *HueSettings) []string {
+	var problems []string
+	defaults := defaultSettings().Hue
+	if h.Brightness < 1 || h.Brightness > 100 {
+		problems = append(problems, fmt.Sprintf("hue.brightness: %d is not between 1 and 100; using %d", h.Brightness, defaults.Brightness))
+		h.Brightness = defaults.Brightness
+	}
+	colors := []struct {
+		key      string
+		value    *string
+		fallback string
+	}{
+		{"hue.pomodoro_color", &h.PomodoroColor, defaults.PomodoroColor},
+		{"hue.break_color", &h.BreakColor, defaults.BreakColor},
+	}
+	for _, c := range colors {
+		if _, err := parseHexColor(*c.value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %q is not a hex color like #FF0000; using %s", c.key, *c.value, c.fallback))
+			*c.value = c.fallback
+		}
+	}
+	return problems
+}
+
+// addHueMenu adds the Hue bridge connect/disconnect menu item.
+func addHueMenu() {
+	mHue = systray.AddMenuItem("", tr("Color Philips Hue lights red during Pomodoros and green during breaks"))
+	mHue.Click(func() {
+		if newSettings := currentSettings(); newSettings.Hue.Username != "" {
+			newSettings.Hue.Username = ""
+			applySettings(newSettings)
+		} else {
+			go connectHue()
+		}
+	})
+	updateHueMenu()
+}
+
+// updateHueMenu updates the Hue menu item to the connection state.
+func updateHueMenu() {
+	if mHue == nil {
+		return
+	}
+	if settings.Hue.Username != "" {
+		mHue.SetTitle(tr("Disconnect Hue Bridge"))
+	} else {
+		mHue.SetTitle(tr("Connect Hue Bridge..."))
+	}
+}
+
+// connectHue finds the Hue bridge and registers the app on it once its link
+// button is pressed.
+func connectHue() {
+	bridge := settings.Hue.Bridge
+	if bridge == "" {
+		var err error
+		if bridge, err = discoverHueBridge(); err != nil {
+			slog.Error("Failed to find the Hue bridge", "err", err)
+			sendNotification(tr("Hue bridge not found"), tr("Set hue.bridge to the IP address of your bridge in the settings"))
+			return
+		}
+	}
+
+	sendNotification(tr("Connect Hue Bridge"), tr("Press the link button on your Hue bridge within 30 seconds"))
+	hostname, _ := os.Hostname()
+	request := map[string]string{"devicetype": "pomodoro_timer#" + hostname}
+	var username string
+	for deadline := time.Now().Add(30 * time.Second); username == "" && time.Now().Before(deadline); time.Sleep(2 * time.Second) {
+		var reply []struct {
+			Success struct {
+				Username string `json:"username"`
+			} `json:"success"`
+			Error struct {
+				Type        int    `json:"type"`
+				Description string `json:"description"`
+			} `json:"error"`
+		}
+		if err := hueCall(http.MethodPost, "http://"+bridge+"/api", request, &reply); err != nil {
+			slog.Error("Failed to connect to the Hue bridge", "err", err)
+			sendNotification(tr("Hue bridge not reachable"), err.Error())
+			return
+		}
+		if len(reply) == 0 {
+			continue
+		}
+		if reply[0].Error.Type != 0 && reply[0].Error.Type != 101 { // 101: link button not pressed
+			slog.Error("Hue bridge refused the connection", "err", reply[0].Error.Description)
+			sendNotification(tr("Hue bridge not connected"), reply[0].Error.Description)
+			return
+		}
+		username = reply[0].Success.Username
+	}
+	if username == "" {
+		sendNotification(tr("Hue bridge not connected"), tr("The link button was not pressed in time"))
+		return
+	}
+
+	newSettings := currentSettings()
+	newSettings.Hue.Bridge = bridge
+	newSettings.Hue.Username = username
+	applySettings(newSettings)
+	if len(newSettings.Hue.Lights) == 0 {
+		showHueLights()
+	} else {
+		sendNotification(tr("Hue bridge connected"), tr("Your lights change color with the sessions"))
+	}
+}
+
+// discoverHueBridge returns the IP address of the first Hue bridge on the
+// local network, as registered with the Philips discovery service.
+func discoverHueBridge() (string, error) {
+	var bridges []struct {
+		IP string `json:"internalipaddress"`
+	}
+	if err := hueCall(http.MethodGet, hueDiscoveryURL, nil, &bridges); err != nil {
+		return "", err
+	}
+	if len(bridges) == 0 || bridges[0].IP == "" {
+		return "", fmt.Errorf("no bridge found on the network")
+	}
+	return bridges[0].IP, nil
+}
+
+// showHueLights lists the lights of the bridge in the text editor so their
+// IDs can be copied to hue.lights.
+func showHueLights() {
+	lights, err := hueLights(settings.Hue)
+	if err != nil {
+		slog.Error("Failed to list the Hue lights", "err", err)
+		return
+	}
+	ids := make([]string, 0, len(lights))
+	for id := range lights {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var sb strings.Builder
+	sb.WriteString("The Hue bridge is connected. Add the IDs or names of the lights to color to\n")
+	sb.WriteString("\"lights\" in the \"hue\" section of the settings, e.g. \"lights\": [\"1\", \"Desk lamp\"]\n\n")
+	for _, id := range ids {
+		fmt.Fprintf(&sb, "%s: %s\n", id, lights[id].Name)
+	}
+	sb.WriteString("\nYou can close this file.\n")
+	editInEditor("pomodoro_hue_lights_*.txt", []byte(sb.String()))
+}
+
+// hueLights returns the lights of the bridge by ID.
+func hueLights(hue HueSettings) (map[string]hueLight, error) {
+	var lights map[string]hueLight
+	err := hueCall(http.MethodGet, hueURL(hue, "/lights"), nil, &lights)
+	return lights, err
+}
+
+// runHueLights colors the lights by the timer events: the Pomodoro or break
+// color while a session runs, and the lights' previous state after it.
+func runHueLights() {
+	saved := map[string]hueLightState{} // State of the lights before the first session, by ID
+	for event := range subscribeEvents() {
+		hue := settings.Hue
+		if hue.Username == "" || hue.Bridge == "" || len(hue.Lights) == 0 {
+			continue
+		}
+		var err error
+		switch event.name {
+		case eventPomodoroStart:
+			err = setHueLights(hue, hue.PomodoroColor, hue.PomodoroScene, saved)
+		case eventBreakStart:
+			err = setHueLights(hue, hue.BreakColor, hue.BreakScene, saved)
+		case eventPomodoroEnd, eventBreakEnd, eventStop:
+			err = restoreHueLights(hue, saved)
+		}
+		if err != nil {
+			slog.Error("Failed to set the Hue lights", "err", err)
+		}
+	}
+}
+
+// setHueLights sets the lights to the color, or recalls the scene, first
+// saving the state of the lights if it is not saved yet.
+func setHueLights(hue HueSettings, hexColor, scene string, saved map[string]hueLightState) error {
+	lights, err := hueLights(hue)
+	if err != nil {
+		return err
+	}
+	ids := selectedHueLights(hue, lights)
+	if len(saved) == 0 {
+		for _, id := range ids {
+			saved[id] = lights[id].State
+		}
+	}
+
+	if scene != "" {
+		return hueCall(http.MethodPut, hueURL(hue, "/groups/0/action"), map[string]string{"scene": scene}, nil)
+	}
+	c, err := parseHexColor(hexColor)
+	if err != nil {
+		return err
+	}
+	x, y := hueXY(c.R, c.G, c.B)
+	state := map[string]interface{}{
+		"on":  true,
+		"bri": int(math.Round(float64(hue.Brightness) * 254 / 100)),
+		"xy":  []float64{x, y},
+	}
+	for _, id := range ids {
+		if err := hueCall(http.MethodPut, hueURL(hue, "/lights/"+id+"/state"), state, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreHueLights returns the lights to their saved state.
+func restoreHueLights(hue HueSettings, saved map[string]hueLightState) error {
+	for id, state := range saved {
+		restored := map[string]interface{}{"on": state.On}
+		if state.On {
+			restored["bri"] = state.Bri
+			switch state.Mode {
+			case "ct":
+				restored["ct"] = state.CT
+			case "xy", "hs":
+				restored["xy"] = state.XY
+			}
+		}
+		if err := hueCall(http.MethodPut, hueURL(hue, "/lights/"+id+"/state"), restored, nil); err != nil {
+			return err
+		}
+		delete(saved, id)
+	}
+	return nil
+}
+
+// selectedHueLights returns the IDs of the configured lights, which are
+// given by ID or name.
+func selectedHueLights(hue HueSettings, lights map[string]hueLight) []string {
+	var ids []string
+	for id, light := range lights {
+		for _, wanted := range hue.Lights {
+			if wanted == id || strings.EqualFold(wanted, light.Name) {
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// hueURL returns the URL of a resource of the bridge API.
+func hueURL(hue HueSettings, path string) string {
+	return "http://" + hue.Bridge + "/api/" + hue.Username + path
+}
+
+// hueXY converts an sRGB color to the CIE xy coordinates used by Hue lights.
+func hueXY(r, g, b uint8) (float64, float64) {
+	linear := func(c uint8) float64 {
+		v := float64(c) / 255
+		if v > 0.04045 {
+			return math.Pow((v+0.055)/1.055, 2.4)
+		}
+		return v / 12.92
+	}
+	rl, gl, bl := linear(r), linear(g), linear(b)
+	X := rl*0.4124 + gl*0.3576 + bl*0.1805
+	Y := rl*0.2126 + gl*0.7152 + bl*0.0722
+	Z := rl*0.0193 + gl*0.1192 + bl*0.9505
+	if X+Y+Z == 0 {
+		return 0.3127, 0.3290 // White point for black
+	}
+	return math.Round(X/(X+Y+Z)*10000) / 10000, math.Round(Y/(X+Y+Z)*10000) / 10000
+}
+
+// hueCall sends a request to the Hue bridge or discovery service and decodes
+// the JSON reply into result, if not nil. Failures reported in the reply of
+// a state change are returned as errors.
+func hueCall(method, url string, payload, result interface{}) error {
+	var body *bytes.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	} else {
+		body = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := hueClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+	if result != nil {
+		return json.Unmarshal(data, result)
+	}
+	var reply []struct {
+		Error *struct {
+			Description string `json:"description"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(data, &reply) == nil {
+		for _, r := range reply {
+			if r.Error != nil {
+				return fmt.Errorf("%s", r.Error.Description)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"pomodoro-timer/internal/icon"
+)
+
+const iconSize = 64 // Size of the PNG tray icon in pixels
+
+var (
+	iconRenderer  = icon.New(nil) // Draws and caches the icons, with the embedded font once it is parsed
+	lastTraySpecs []icon.Spec     // Icons shown in the tray, guarded by mu
+)
+
+// iconText returns the text shown on the icon for the remaining time: minutes,
+// m:ss below the configured threshold, or seconds in the last minute.
+func iconText(remaining time.Duration) string {
+	if remaining < time.Duration(settings.IconSecondsMinutes)*time.Minute {
+		return fmt.Sprintf("%d:%02d", int(remaining.Minutes()), int(remaining.Seconds())%60)
+	}
+	if remaining < time.Minute {
+		return fmt.Sprintf("%d", int(remaining.Seconds()))
+	}
+	return fmt.Sprintf("%d", int(remaining.Minutes()))
+}
+
+// currentPalette returns the theme colors for the state of the timer.
+// The caller must hold mu.
+func currentPalette() icon.Palette {
+	return icon.Palette{Background: iconPhaseBackground(), Text: iconTextColor, Dots: iconDotColor}
+}
+
+// iconSpec describes the tray icon of the given size in the configured style
+// and the palette. The caller must hold mu.
+func iconSpec(palette icon.Palette, size int, text string, dotCount int) icon.Spec {
+	progress := 0.0
+	if engine.Duration() > 0 {
+		progress = float64(engine.Remaining()) / float64(engine.Duration())
+	}
+	return icon.Spec{
+		Style:    icon.Style(settings.IconStyle),
+		Palette:  palette,
+		Size:     size,
+		Text:     text,
+		Dots:     dotCount,
+		Progress: progress,
+	}
+}
+
+// progressIconStyle reports whether the configured icon style shows the
+// progress, so the icon may change every second.
+func progressIconStyle() bool {
+	style := icon.Style(settings.IconStyle)
+	return style == icon.Ring || style == icon.Pie
+}
+
+// trayIconChanged reports whether the specs differ from the icon shown in the
+// tray, remembering them as shown. Comparing the specs instead of the encoded
+// images spares drawing, encoding and pushing an unchanged icon to the tray
+// every second. The caller must hold mu.
+func trayIconChanged(specs []icon.Spec) bool {
+	if icon.Same(specs, lastTraySpecs) {
+		return false
+	}
+	lastTraySpecs = specs
+	return true
+}
+
+// prerenderSessionIcons renders the tray icons of every minute of a session
+// of the given length in the background, so they are ready when shown. Only
+// the digits style is rendered ahead, as the others change every few
+// seconds. The caller must hold mu.
+func prerenderSessionIcons(duration time.Duration) {
+	if launch.headless || progressIconStyle() {
+		return
+	}
+	var specs []icon.Spec
+	for remaining := duration.Truncate(time.Minute); remaining >= time.Minute; remaining -= time.Minute {
+		specs = append(specs, trayIconSpecs(iconText(remaining), engine.Count())...)
+	}
+	go iconRenderer.Prerender(specs)
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	icsDateLayout  = "2006-01-02"       // Layout of the dates of the export range
+	icsTimeLayout  = "20060102T150405Z" // Layout of UTC times in iCalendar
+	icsLineLength  = 75                 // Longest line in octets before it is folded
+	icsDefaultDays = 30                 // Days the export range suggests
+)
+
+// openCalendarExport asks in the text editor for the dates to export the
+// sessions of, and exports them as an iCalendar file.
+func openCalendarExport() {
+	to := time.Now()
+	from := to.AddDate(0, 0, -icsDefaultDays+1)
+	text := "# Export the sessions from the first to the last date, e.g. 2026-10-01 2026-10-31. Lines starting with # are ignored.\n" +
+		from.Format(icsDateLayout) + " " + to.Format(icsDateLayout) + "\n"
+	data, err := editInEditor("pomodoro_export_ics_*.txt", []byte(text))
+	if err != nil {
+		slog.Error("Failed to edit the dates to export", "err", err)
+		return
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		from, to, err := parseICSRange(line)
+		if err != nil {
+			slog.Error("Failed to export the sessions", "err", err)
+			go sendNotification(tr("Export failed"), err.Error())
+			return
+		}
+		exportCalendar(from, to)
+		return
+	}
+}
+
+// parseICSRange parses the first and last date of the export range, e.g.
+// "2026-10-01 2026-10-31", and returns the start of the first day and the end
+// of the last one. A single date exports that day.
+func parseICSRange(s string) (time.Time, time.Time, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 1 {
+		fields = append(fields, fields[0])
+	}
+	if len(fields) != 2 {
+		return time.Time{}, time.Time{}, fmt.Errorf("%q is not two dates like 2026-10-01 2026-10-31", s)
+	}
+	from, err1 := time.ParseInLocation(icsDateLayout, fields[0], time.Local)
+	to, err2 := time.ParseInLocation(icsDateLayout, fields[1], time.Local)
+	if err1 != nil || err2 != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%q is not two dates like 2026-10-01 2026-10-31", s)
+	}
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%s is before %s", fields[1], fields[0])
+	}
+	return from, to.AddDate(0, 0, 1), nil
+}
+
+// exportCalendar writes the sessions started between from and to as events
+// of an iCalendar file in the home directory.
+func exportCalendar(from, to time.Time) {
+	records, err := loadHistory()
+	if err != nil {
+		slog.Error("Failed to load history", "err", err)
+		return
+	}
+	var selected []SessionRecord
+	for _, record := range records {
+		if !record.Start.Before(from) && record.Start.Before(to) && !record.End.IsZero() {
+			selected = append(selected, record)
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+	path := filepath.Join(home, fmt.Sprintf("pomodoro-timer-sessions-%s-%s.ics",
+		from.Format(icsDateLayout), to.AddDate(0, 0, -1).Format(icsDateLayout)))
+	if err := ioutil.WriteFile(path, []byte(sessionsICS(selected, time.Now())), 0644); err != nil {
+		slog.Error("Failed to write the calendar export", "err", err)
+		sendNotification(tr("Export failed"), err.Error())
+		return
+	}
+	slog.Info("Exported sessions as iCalendar", "path", path, "sessions", len(selected))
+	sendNotification(fmt.Sprintf(tr("%d sessions exported"), len(selected)), path)
+}
+
+// sessionsICS formats the sessions as an iCalendar file with an event each.
+func sessionsICS(records []SessionRecord, now time.Time) string {
+	var sb strings.Builder
+	writeICSLine(&sb, "BEGIN:VCALENDAR")
+	writeICSLine(&sb, "VERSION:2.0")
+	writeICSLine(&sb, "PRODID:-//Pomodoro Timer//Session History//EN")
+	writeICSLine(&sb, "CALSCALE:GREGORIAN")
+	writeICSLine(&sb, "X-WR-CALNAME:Pomodoro Timer")
+	for _, record := range records {
+		summary := "🍅 Pomodoro"
+		if record.Type != sessionPomodoro {
+			summary = "☕ Break"
+		}
+		if record.Task != "" {
+			summary += ": " + record.Task
+		}
+		var description []string
+		if !record.Completed {
+			description = append(description, "Stopped early")
+		}
+		if n := len(record.Interruptions); n > 0 {
+			description = append(description, fmt.Sprintf("%d interruptions", n))
+		}
+		if record.Note != "" {
+			description = append(description, record.Note)
+		}
+
+		writeICSLine(&sb, "BEGIN:VEVENT")
+		writeICSLine(&sb, fmt.Sprintf("UID:%s-%s@pomodoro-timer", record.Start.UTC().Format(icsTimeLayout), record.Type))
+		writeICSLine(&sb, "DTSTAMP:"+now.UTC().Format(icsTimeLayout))
+		writeICSLine(&sb, "DTSTART:"+record.Start.UTC().Format(icsTimeLayout))
+		writeICSLine(&sb, "DTEND:"+record.End.UTC().Format(icsTimeLayout))
+		writeICSLine(&sb, "SUMMARY:"+icsEscape(summary))
+		if len(description) > 0 {
+			writeICSLine(&sb, "DESCRIPTION:"+icsEscape(strings.Join(description, "\n")))
+		}
+		if record.Tag != "" {
+			writeICSLine(&sb, "CATEGORIES:"+icsEscape(record.Tag))
+		}
+		writeICSLine(&sb, "TRANSP:TRANSPARENT")
+		writeICSLine(&sb, "END:VEVENT")
+	}
+	writeICSLine(&sb, "END:VCALENDAR")
+	return sb.String()
+}
+
+// icsEscape escapes the characters with a meaning in iCalendar text values.
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// writeICSLine writes a content line ending in CRLF, folding it into lines of
+// at most 75 octets without splitting a character.
+func writeICSLine(sb *strings.Builder, line string) {
+	limit := icsLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		sb.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = icsLineLength - 1 // The leading space of a continuation line counts
+	}
+	sb.WriteString(line + "\r\n")
+}
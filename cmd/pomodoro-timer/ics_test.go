@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestICSEscape(t *testing.T) {
+	for s, want := range map[string]string{
+		"Write report":        "Write report",
+		"a;b,c":               `a\;b\,c`,
+		`C:\temp`:             `C:\\temp`,
+		"Stopped early\nnote": `Stopped early\nnote`,
+		"line\r\nbreak":       `line\nbreak`,
+	} {
+		if got := icsEscape(s); got != want {
+			t.Errorf("icsEscape(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestParseICSRange(t *testing.T) {
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, time.Local)
+	}
+	for _, test := range []struct {
+		s        string
+		from, to time.Time
+	}{
+		{"2026-10-01 2026-10-31", day(2026, 10, 1), day(2026, 11, 1)},
+		{"  2026-10-05\t2026-10-05 ", day(2026, 10, 5), day(2026, 10, 6)},
+		{"2026-12-31", day(2026, 12, 31), day(2027, 1, 1)},
+	} {
+		from, to, err := parseICSRange(test.s)
+		if err != nil || !from.Equal(test.from) || !to.Equal(test.to) {
+			t.Errorf("parseICSRange(%q) = %v, %v, %v; want %v, %v", test.s, from, to, err, test.from, test.to)
+		}
+	}
+}
+
+func TestParseICSRangeErrors(t *testing.T) {
+	for _, s := range []string{"", "yesterday", "2026-10-31 2026-10-01", "2026-10-01 to 2026-10-31", "2026-13-01 2026-13-02", "01/10/2026"} {
+		if from, to, err := parseICSRange(s); err == nil {
+			t.Errorf("parseICSRange(%q) = %v, %v; want an error", s, from, to)
+		}
+	}
+}
+
+func TestWriteICSLine(t *testing.T) {
+	var sb strings.Builder
+	line := "DESCRIPTION:" + strings.Repeat("é", 100)
+	writeICSLine(&sb, line)
+	folded := sb.String()
+	if !strings.HasSuffix(folded, "\r\n") {
+		t.Fatalf("line does not end in CRLF: %q", folded)
+	}
+	for i, part := range strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n") {
+		if len(part) > icsLineLength {
+			t.Errorf("line %d is %d octets long", i, len(part))
+		}
+		if i > 0 && !strings.HasPrefix(part, " ") {
+			t.Errorf("continuation line %d does not start with a space", i)
+		}
+	}
+	if unfolded := strings.ReplaceAll(folded, "\r\n ", ""); unfolded != line+"\r\n" {
+		t.Errorf("unfolded line %q, want %q", unfolded, line)
+	}
+}
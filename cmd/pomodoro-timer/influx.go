@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// InfluxSettings configures the export of completed sessions to InfluxDB or
+// a line protocol file.
+type InfluxSettings struct {
+	URL         string `json:"url"`         // InfluxDB server, e.g. http://localhost:8086; empty to not send sessions
+	Token       string `json:"token"`       // API token, or "user:password" for InfluxDB 1.8
+	Org         string `json:"org"`         // Organization of the bucket, empty for InfluxDB 1.8
+	Bucket      string `json:"bucket"`      // Bucket the sessions are written to, or "database/retention_policy" for InfluxDB 1.8
+	File        string `json:"file"`        // Line protocol file the sessions are appended to, empty for none
+	Measurement string `json:"measurement"` // Measurement of the points, "pomodoro" by default
+}
+
+var influxClient = &http.Client{Timeout: 15 * time.Second}
+
+// influxSessionEnded writes a completed session to InfluxDB and to the line
+// protocol file, whichever is set.
+func influxSessionEnded(record SessionRecord) {
+	influx := settings.Influx
+	if (influx.URL == "" && influx.File == "") || !record.Completed {
+		return
+	}
+	if influx.Measurement == "" {
+		influx.Measurement = defaultSettings().Influx.Measurement
+	}
+	line := influxLine(influx.Measurement, record)
+	if influx.File != "" {
+		if err := appendInfluxFile(influx.File, line); err != nil {
+			slog.Error("Failed to write the InfluxDB line protocol file", "err", err)
+		}
+	}
+	if influx.URL != "" {
+		go func() {
+			if err := influxWrite(influx, line); err != nil {
+				slog.Error("Failed to write the session to InfluxDB", "err", err)
+			}
+		}()
+	}
+}
+
+// influxLine formats a session as a point in the line protocol, tagged with
+// its type, task and tag and timestamped in seconds with its end, e.g.
+// "pomodoro,type=pomodoro,task=Write\ report focus_seconds=1500i,... 1760000000".
+func influxLine(measurement string, record SessionRecord) string {
+	var sb strings.Builder
+	sb.WriteString(influxEscape(measurement, ", "))
+	for _, tag := range []struct{ key, value string }{
+		{"type", record.Type},
+		{"task", record.Task},
+		{"tag", record.Tag},
+	} {
+		if tag.value != "" {
+			fmt.Fprintf(&sb, ",%s=%s", tag.key, influxEscape(tag.value, ",= "))
+		}
+	}
+	focus := record.End.Sub(record.Start) - time.Duration(record.PausedSeconds)*time.Second
+	fmt.Fprintf(&sb, " focus_seconds=%di,duration_seconds=%di,paused_seconds=%di,interruptions=%di %d",
+		int(focus.Seconds()), record.Duration*60, record.PausedSeconds, len(record.Interruptions), record.End.Unix())
+	return sb.String()
+}
+
+// influxEscape escapes backslashes and the given characters with a backslash.
+// Line breaks, which cannot be escaped, become spaces first.
+func influxEscape(s, special string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if r == '\n' || r == '\r' {
+			r = ' '
+		}
+		if r == '\\' || strings.ContainsRune(special, r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// appendInfluxFile appends a line to the line protocol file.
+func appendInfluxFile(path, line string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(line + "\n")
+	return err
+}
+
+// influxWrite sends a line to the write endpoint of the InfluxDB 2 API, which
+// InfluxDB 1.8 also serves.
+func influxWrite(influx InfluxSettings, line string) error {
+	query := url.Values{"bucket": {influx.Bucket}, "precision": {"s"}}
+	if influx.Org != "" {
+		query.Set("org", influx.Org)
+	}
+	endpoint := strings.TrimRight(influx.URL, "/") + "/api/v2/write?" + query.Encode()
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(line+"\n"))
+	if err != nil {
+		return err
+	}
+	if influx.Token != "" {
+		req.Header.Set("Authorization", "Token "+influx.Token)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	resp, err := influxClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInfluxEscape(t *testing.T) {
+	for _, test := range []struct {
+		s, special, want string
+	}{
+		{"pomodoro", ", ", "pomodoro"},
+		{"my measurement,1", ", ", `my\ measurement\,1`},
+		{"Write report, v2=final", ",= ", `Write\ report\,\ v2\=final`},
+		{`C:\temp`, ",= ", `C:\\temp`},
+		{"two\nlines", ",= ", `two\ lines`},
+		{"a=b", ", ", "a=b"}, // Measurements may contain =
+	} {
+		if got := influxEscape(test.s, test.special); got != test.want {
+			t.Errorf("influxEscape(%q, %q) = %q, want %q", test.s, test.special, got, test.want)
+		}
+	}
+}
+
+func TestInfluxLine(t *testing.T) {
+	start := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	record := SessionRecord{
+		Type:          sessionPomodoro,
+		Start:         start,
+		End:           start.Add(27 * time.Minute),
+		Duration:      25,
+		Completed:     true,
+		Task:          "Write report",
+		PausedSeconds: 120,
+		Interruptions: make([]Interruption, 2),
+	}
+	want := `pomodoro,type=pomodoro,task=Write\ report focus_seconds=1500i,duration_seconds=1500i,paused_seconds=120i,interruptions=2i 1792229220`
+	if got := influxLine("pomodoro", record); got != want {
+		t.Errorf("influxLine = %q,\nwant %q", got, want)
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+var instanceLock *os.File // Lock file held while this instance runs
+
+// getInstanceLockPath returns the path of the file locked by the running instance.
+func getInstanceLockPath() string {
+	return getCacheFilePath("instance.lock")
+}
+
+// forwardToInstance sends the command-line flags to the running instance
+// instead of starting a second one, and returns the exit code.
+func forwardToInstance(args []string) int {
+	// The other instance may still be starting up.
+	status, _, err := sendControlCommand(append([]string{"launch"}, args...), 5*time.Second)
+	if err != nil {
+		attachParentConsole()
+		fmt.Fprintln(os.Stderr, "Pomodoro Timer is already running, but does not respond:", err)
+		return 1
+	}
+	if status.Error != "" {
+		attachParentConsole()
+		fmt.Fprintln(os.Stderr, status.Error)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+
+package main
+
+import (
+	"log/slog"
+	"os"
+	"syscall"
+)
+
+// lockInstance locks the instance lock file and reports whether this is the
+// only instance. The lock is released by the system when the process exits.
+func lockInstance() bool {
+	f, err := os.OpenFile(getInstanceLockPath(), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		slog.Error("Failed to open the instance lock", "err", err)
+		return true
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		return false
+	}
+	instanceLock = f
+	return true
+}
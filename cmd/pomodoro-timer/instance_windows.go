@@ -0,0 +1,25 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockInstance locks the instance lock file and reports whether this is the
+// only instance. The lock is released by Windows when the process exits.
+func lockInstance() bool {
+	f, err := os.OpenFile(getInstanceLockPath(), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		slog.Error("Failed to open the instance lock", "err", err)
+		return true
+	}
+	err = windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, new(windows.Overlapped))
+	if err != nil {
+		f.Close()
+		return false
+	}
+	instanceLock = f
+	return true
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lutischan-ferenc/systray"
+)
+
+// JiraSettings stores the Jira server connection and the issue Pomodoros are logged against.
+type JiraSettings struct {
+	URL          string   `json:"url"`           // Base URL of the Jira server, e.g. https://example.atlassian.net
+	Email        string   `json:"email"`         // Account email for Jira Cloud; leave empty to use a personal access token
+	Token        string   `json:"token"`         // API token (Cloud) or personal access token (Server/Data Center)
+	IssueKey     string   `json:"issue_key"`     // Issue the next Pomodoros are attached to
+	RecentIssues []string `json:"recent_issues"` // Recently used issue keys, newest first
+}
+
+const maxRecentJiraIssues = 5
+
+var (
+	jiraIssueKeyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*-[0-9]+$`)
+	jiraClient          = &http.Client{Timeout: 30 * time.Second}
+
+	mJira          *systray.MenuItem   // Submenu showing the current Jira issue
+	mJiraNone      *systray.MenuItem   // Menu item for detaching Pomodoros from Jira
+	mJiraRecent    []*systray.MenuItem // Menu items for the recently used issue keys
+	jiraRecentKeys []string            // Issue keys currently shown in mJiraRecent
+
+	jiraWorklogIssue string // Issue the running Pomodoro is logged to, guarded by mu
+)
+
+// addJiraMenu adds the Jira issue submenu to the system tray.
+func addJiraMenu() {
+	mJira = systray.AddMenuItem(tr("Jira Issue"), tr("Log completed Pomodoros to a Jira issue"))
+	mJiraSet := mJira.AddSubMenuItem(tr("Enter Issue Key..."), tr("Type the key of the issue to work on"))
+	mJiraSet.Click(func() {
+		promptJiraIssueKey()
+	})
+	mJiraNone = mJira.AddSubMenuItemCheckbox(tr("No Issue"), tr("Do not log Pomodoros to Jira"), false)
+	mJiraNone.Click(func() {
+		selectJiraIssue("")
+	})
+
+	jiraRecentKeys = make([]string, maxRecentJiraIssues)
+	for i := 0; i < maxRecentJiraIssues; i++ {
+		slot := i
+		item := mJira.AddSubMenuItemCheckbox("", tr("Log Pomodoros to this issue"), false)
+		item.Click(func() {
+			selectJiraIssue(jiraRecentKeys[slot])
+		})
+		item.Hide()
+		mJiraRecent = append(mJiraRecent, item)
+	}
+	updateJiraMenu()
+}
+
+// updateJiraMenu refreshes the Jira submenu from the current settings.
+func updateJiraMenu() {
+	if mJira == nil {
+		return
+	}
+	current := settings.Jira.IssueKey
+	if current == "" {
+		mJira.SetTitle(tr("Jira Issue: none"))
+		mJiraNone.Check()
+	} else {
+		mJira.SetTitle(fmt.Sprintf(tr("Jira Issue: %s"), current))
+		mJiraNone.Uncheck()
+	}
+
+	for i, item := range mJiraRecent {
+		if i >= len(settings.Jira.RecentIssues) {
+			jiraRecentKeys[i] = ""
+			item.Hide()
+			continue
+		}
+		key := settings.Jira.RecentIssues[i]
+		jiraRecentKeys[i] = key
+		item.SetTitle(key)
+		if key == current {
+			item.Check()
+		} else {
+			item.Uncheck()
+		}
+		item.Show()
+	}
+}
+
+// promptJiraIssueKey lets the user type an issue key in the default text editor.
+func promptJiraIssueKey() {
+	text := settings.Jira.IssueKey + "\n"
+	data, err := editInEditor("pomodoro_jira_issue_*.txt", []byte(text))
+	if err != nil {
+		slog.Error("Failed to edit Jira issue", "err", err)
+		return
+	}
+
+	key := ""
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			key = strings.ToUpper(line)
+			break
+		}
+	}
+	if key != "" && !jiraIssueKeyPattern.MatchString(key) {
+		slog.Warn("Invalid Jira issue key", "key", key)
+		return
+	}
+	selectJiraIssue(key)
+}
+
+// selectJiraIssue attaches the following Pomodoros to the given issue key.
+// An empty key detaches them from Jira.
+func selectJiraIssue(key string) {
+	mu.Lock()
+	settings.Jira.IssueKey = key
+	if key != "" {
+		recent := []string{key}
+		for _, k := range settings.Jira.RecentIssues {
+			if k != key && len(recent) < maxRecentJiraIssues {
+				recent = append(recent, k)
+			}
+		}
+		settings.Jira.RecentIssues = recent
+	}
+	saveSettings()
+	mu.Unlock()
+	updateJiraMenu()
+}
+
+// jiraSessionStarted remembers the issue of the Pomodoro, so switching the
+// issue while it runs does not move its worklog.
+func jiraSessionStarted(sessionInfo) {
+	jiraWorklogIssue = settings.Jira.IssueKey
+}
+
+// jiraSessionCompleted logs the completed Pomodoro to its issue.
+func jiraSessionCompleted(s sessionInfo) {
+	if jiraWorklogIssue != "" {
+		go postJiraWorklog(settings.Jira, jiraWorklogIssue, s.timer.Start, s.timer.Duration)
+	}
+}
+
+// postJiraWorklog adds a worklog entry for a completed Pomodoro to the given
+// issue of the Jira server configured by jira.
+func postJiraWorklog(jira JiraSettings, issueKey string, started time.Time, duration time.Duration) {
+	if jira.URL == "" || jira.Token == "" {
+		slog.Warn("Jira is not configured, skipping worklog", "issue", issueKey)
+		return
+	}
+
+	payload := map[string]interface{}{
+		"started":          started.Format("2006-01-02T15:04:05.000-0700"),
+		"timeSpentSeconds": int(duration.Seconds()),
+		"comment":          "Pomodoro",
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("Failed to encode Jira worklog", "err", err)
+		return
+	}
+
+	url := fmt.Sprintf("%s/rest/api/2/issue/%s/worklog", strings.TrimRight(jira.URL, "/"), issueKey)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		slog.Error("Failed to create Jira request", "err", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if jira.Email != "" {
+		req.SetBasicAuth(jira.Email, jira.Token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+jira.Token)
+	}
+
+	resp, err := jiraClient.Do(req)
+	if err != nil {
+		slog.Error("Failed to post Jira worklog", "err", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		slog.Error("Jira worklog failed", "issue", issueKey, "status", resp.Status)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"log/slog"
+	"strings"
+)
+
+//go:embed assets/locales/*.json
+var localeFiles embed.FS
+
+// languageAuto follows the language of the operating system.
+const languageAuto = "auto"
+
+// languages are the languages of the menus and notifications. English is the
+// language of the source texts; the others have a translation file mapping
+// the English texts to theirs.
+var languages = []string{"en", "de", "es", "fr", "hu"}
+
+var translations map[string]string // Translations of the English texts into the selected language, nil for English
+
+// initLanguage loads the translations of the language setting, or of the
+// system's language if it is "auto". Unsupported languages fall back to
+// English.
+func initLanguage() {
+	language := settings.Language
+	if language == languageAuto || language == "" {
+		language = languageCode(systemLanguage())
+	}
+	translations = nil
+	if language == "en" || !containsString(languages, language) {
+		return
+	}
+	data, err := localeFiles.ReadFile("assets/locales/" + language + ".json")
+	if err == nil {
+		err = json.Unmarshal(data, &translations)
+	}
+	if err != nil {
+		slog.Error("Failed to load translations", "language", language, "err", err)
+	}
+}
+
+// languageCode returns the two-letter code of a locale such as "hu_HU.UTF-8" or "de-AT".
+func languageCode(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, "_-.@"); i >= 0 {
+		locale = locale[:i]
+	}
+	return locale
+}
+
+// tr returns the translation of an English text of the menus and
+// notifications into the selected language, or the text itself if it has
+// none. Texts with verbs are formatted after translating them, e.g.
+// fmt.Sprintf(tr("Snooze %d min"), n).
+func tr(text string) string {
+	if translated, ok := translations[text]; ok && translated != "" {
+		return translated
+	}
+	return text
+}
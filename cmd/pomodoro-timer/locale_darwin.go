@@ -0,0 +1,20 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// systemLanguage returns the locale of the user, e.g. "hu_HU". Apps started
+// from the Finder have no LANG, so the system preference is read then.
+func systemLanguage() string {
+	if value := os.Getenv("LANG"); value != "" && value != "C" {
+		return value
+	}
+	out, err := exec.Command("defaults", "read", "-g", "AppleLocale").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
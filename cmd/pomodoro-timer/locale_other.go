@@ -0,0 +1,15 @@
+//go:build !windows && !darwin
+
+package main
+
+import "os"
+
+// systemLanguage returns the locale of the user from the environment, e.g. "hu_HU.UTF-8".
+func systemLanguage() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" && value != "C" && value != "POSIX" {
+			return value
+		}
+	}
+	return ""
+}
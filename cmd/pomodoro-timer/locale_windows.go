@@ -0,0 +1,12 @@
+package main
+
+import "golang.org/x/sys/windows"
+
+// systemLanguage returns the user's preferred display language, e.g. "hu-HU".
+func systemLanguage() string {
+	languages, err := windows.GetUserPreferredUILanguages(windows.MUI_LANGUAGE_NAME)
+	if err != nil || len(languages) == 0 {
+		return ""
+	}
+	return languages[0]
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime/debug"
+	"sync"
+)
+
+const (
+	maxLogSize  = 1 << 20 // Size at which the log file is rotated
+	maxLogFiles = 3       // Number of rotated log files kept
+)
+
+// rotatingFile is a log file that is renamed to .1, .2, ... when it grows
+// beyond maxLogSize. Each record is written straight to the file, so nothing
+// is lost when the app crashes.
+type rotatingFile struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	size int64
+}
+
+// getLogPath returns the path to the log file.
+func getLogPath() string {
+	return getDataFilePath("pomodoro-timer.log")
+}
+
+// initLogging sends the log to the log file as well as to standard error,
+// and writes the report of a crash to the log file too.
+func initLogging() {
+	f := &rotatingFile{path: getLogPath()}
+	if err := f.open(); err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to open log file:", err)
+		return
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.MultiWriter(os.Stderr, f), nil)))
+	if err := debug.SetCrashOutput(f.file, debug.CrashOptions{}); err != nil {
+		slog.Warn("Failed to log crashes", "err", err)
+	}
+}
+
+// open opens the log file for appending. The caller must hold f.mu or be
+// the only user of f.
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// Write writes a log record, rotating the file first if it is full.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.size+int64(len(p)) > maxLogSize {
+		f.rotate()
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate renames the log files to make room for a new one and moves the
+// crash output to it. The caller must hold f.mu.
+func (f *rotatingFile) rotate() {
+	// Windows cannot rename the file while the crash output holds it open.
+	debug.SetCrashOutput(nil, debug.CrashOptions{})
+	f.file.Close()
+	for i := maxLogFiles - 1; i > 0; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", f.path, i), fmt.Sprintf("%s.%d", f.path, i+1))
+	}
+	if err := os.Rename(f.path, f.path+".1"); err != nil {
+		// Start over rather than trying to rotate on every write.
+		os.Truncate(f.path, 0)
+	}
+	if err := f.open(); err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to open log file:", err)
+		// Keep logging to standard error only.
+		f.file, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+		f.size = 0
+		return
+	}
+	if err := debug.SetCrashOutput(f.file, debug.CrashOptions{}); err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to log crashes:", err)
+	}
+}
+
+// openLogFile opens the log file in the default viewer.
+func openLogFile() {
+	openBrowser(getLogPath())
+}
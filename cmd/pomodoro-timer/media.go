@@ -0,0 +1,52 @@
+package main
+
+import (
+	"log/slog"
+)
+
+// Values of the pause_media setting.
+const (
+	pauseMediaOff       = "off"       // Leave the music alone
+	pauseMediaBreaks    = "breaks"    // Pause the music during breaks
+	pauseMediaPomodoros = "pomodoros" // Pause the music during Pomodoros
+)
+
+var (
+	mediaQueue  = newCallQueue("media players") // Media player changes, so resuming never overtakes pausing
+	mediaResume func() error                    // Resumes the players paused by the app, nil if none; used by the worker only
+)
+
+// mediaSessionStarted pauses the playing music when a session of the type
+// chosen by pause_media starts, and resumes the music it paused when a
+// session of the other type starts.
+func mediaSessionStarted(sessionType string) {
+	mode := settings.PauseMedia
+	if mode == pauseMediaOff || mode == "" {
+		return
+	}
+	pause := (mode == pauseMediaBreaks) == (sessionType == sessionBreak)
+	enqueue := mediaQueue.enqueue
+	if !pause {
+		enqueue = mediaQueue.enqueueRestore // Resuming the music is never dropped
+	}
+	enqueue(func() {
+		if !pause {
+			if mediaResume != nil {
+				if err := mediaResume(); err != nil {
+					slog.Error("Failed to resume media", "err", err)
+				}
+				mediaResume = nil
+			}
+			return
+		}
+		if mediaResume != nil {
+			return
+		}
+		resume, err := pausePlayingMedia()
+		if err != nil {
+			slog.Error("Failed to pause media", "err", err)
+			return
+		}
+		mediaResume = resume
+	})
+}
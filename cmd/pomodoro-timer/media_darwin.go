@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+)
+
+// mediaApps are the macOS players controlled through AppleScript.
+var mediaApps = []string{"Spotify", "Music"}
+
+// pausePlayingMedia pauses Spotify and Music if they are playing and returns
+// a function resuming them, or nil if neither was playing.
+func pausePlayingMedia() (func() error, error) {
+	var paused []string
+	for _, app := range mediaApps {
+		// Checking "is running" first keeps AppleScript from launching the app.
+		script := fmt.Sprintf(`if application %[1]s is running then
+	tell application %[1]s
+		if player state is playing then
+			pause
+			return "paused"
+		end if
+	end tell
+end if`, appleScriptString(app))
+		out, err := exec.Command("osascript", "-e", script).Output()
+		if err != nil {
+			slog.Error("Failed to pause media", "app", app, "err", err)
+			continue
+		}
+		if strings.TrimSpace(string(out)) == "paused" {
+			paused = append(paused, app)
+		}
+	}
+	if len(paused) == 0 {
+		return nil, nil
+	}
+	return func() error {
+		var failed error
+		for _, app := range paused {
+			if err := exec.Command("osascript", "-e", "tell application "+appleScriptString(app)+" to play").Run(); err != nil {
+				failed = err
+			}
+		}
+		return failed
+	}, nil
+}
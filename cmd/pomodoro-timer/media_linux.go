@@ -0,0 +1,56 @@
+package main
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	mprisPrefix = "org.mpris.MediaPlayer2."
+	mprisPath   = "/org/mpris/MediaPlayer2"
+	mprisPlayer = "org.mpris.MediaPlayer2.Player"
+)
+
+// pausePlayingMedia pauses the MPRIS media players that are playing and
+// returns a function resuming them, or nil if none was playing.
+func pausePlayingMedia() (func() error, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	if err := conn.BusObject().Call("org.freedesktop.DBus.ListNames", 0).Store(&names); err != nil {
+		return nil, err
+	}
+
+	var paused []dbus.BusObject
+	for _, name := range names {
+		if !strings.HasPrefix(name, mprisPrefix) {
+			continue
+		}
+		player := conn.Object(name, mprisPath)
+		status, err := player.GetProperty(mprisPlayer + ".PlaybackStatus")
+		if err != nil || status.Value() != "Playing" {
+			continue
+		}
+		if err := player.Call(mprisPlayer+".Pause", 0).Err; err != nil {
+			slog.Error("Failed to pause media", "player", strings.TrimPrefix(name, mprisPrefix), "err", err)
+			continue
+		}
+		paused = append(paused, player)
+	}
+	if len(paused) == 0 {
+		return nil, nil
+	}
+	return func() error {
+		var failed error
+		for _, player := range paused {
+			if err := player.Call(mprisPlayer+".Play", 0).Err; err != nil {
+				failed = err
+			}
+		}
+		return failed
+	}, nil
+}
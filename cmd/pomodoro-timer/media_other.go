@@ -0,0 +1,10 @@
+//go:build !windows && !darwin && !linux
+
+package main
+
+import "errors"
+
+// pausePlayingMedia is not supported on this platform.
+func pausePlayingMedia() (func() error, error) {
+	return nil, errors.New("pausing media is not supported on this platform")
+}
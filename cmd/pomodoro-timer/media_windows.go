@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// mediaScriptPrefix loads the System Media Transport Controls, the media
+// sessions shown in the volume flyout, and defines Await for their async calls.
+const mediaScriptPrefix = `
+Add-Type -AssemblyName System.Runtime.WindowsRuntime
+$asTask = [System.WindowsRuntimeSystemExtensions].GetMethods() | Where-Object {
+	$_.Name -eq 'AsTask' -and $_.GetParameters().Count -eq 1 -and $_.GetParameters()[0].ParameterType.Name -eq 'IAsyncOperation` + "`" + `1'
+} | Select-Object -First 1
+function Await($op, $type) {
+	$task = $asTask.MakeGenericMethod($type).Invoke($null, @($op))
+	$task.Wait(-1) | Out-Null
+	$task.Result
+}
+$managerType = [Windows.Media.Control.GlobalSystemMediaTransportControlsSessionManager, Windows.Media.Control, ContentType = WindowsRuntime]
+$manager = Await ($managerType::RequestAsync()) $managerType
+`
+
+// pauseMediaScript pauses the playing media sessions and prints the app IDs of the paused ones.
+const pauseMediaScript = mediaScriptPrefix + `
+foreach ($session in $manager.GetSessions()) {
+	if ($session.GetPlaybackInfo().PlaybackStatus -eq 'Playing') {
+		if (Await ($session.TryPauseAsync()) ([bool])) { $session.SourceAppUserModelId }
+	}
+}
+`
+
+// resumeMediaScript resumes the media sessions of the app IDs listed in %s.
+const resumeMediaScript = mediaScriptPrefix + `
+$ids = @(%s)
+foreach ($session in $manager.GetSessions()) {
+	if ($ids -contains $session.SourceAppUserModelId) { Await ($session.TryPlayAsync()) ([bool]) | Out-Null }
+}
+`
+
+// pausePlayingMedia pauses the media sessions that are playing, e.g. Spotify
+// or a browser tab, and returns a function resuming them, or nil if none was playing.
+func pausePlayingMedia() (func() error, error) {
+	out, err := newPowerShellCommand(pauseMediaScript).Output()
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if id := strings.TrimSpace(line); id != "" {
+			// PowerShell single-quoted strings escape quotes by doubling them.
+			ids = append(ids, "'"+strings.ReplaceAll(id, "'", "''")+"'")
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return func() error {
+		return runPowerShell(fmt.Sprintf(resumeMediaScript, strings.Join(ids, ",")))
+	}, nil
+}
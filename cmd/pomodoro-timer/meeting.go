@@ -0,0 +1,48 @@
+package main
+
+import (
+	"log/slog"
+	"sync/atomic"
+)
+
+// meetingCheckSeconds is how often a running session checks for a meeting.
+const meetingCheckSeconds = 5
+
+var (
+	meetingMuted    atomic.Bool // Sounds of the current session are silenced because of a meeting
+	meetingChecking atomic.Bool // A meeting check is running
+)
+
+// meetingSessionStarted lifts the mute of the previous session and checks
+// for a meeting right away.
+func meetingSessionStarted(sessionInfo) {
+	meetingMuted.Store(false)
+	go checkMeeting()
+}
+
+// meetingTick checks for a meeting every meetingCheckSeconds of the running session.
+func meetingTick(s sessionInfo) {
+	if int(s.timer.Remaining.Seconds())%meetingCheckSeconds == 0 {
+		go checkMeeting()
+	}
+}
+
+// checkMeeting silences the sounds of the current session if a meeting is
+// detected. The mute lasts until the next session starts.
+func checkMeeting() {
+	if !settings.AutoMuteInMeetings || meetingMuted.Load() || !meetingChecking.CompareAndSwap(false, true) {
+		return
+	}
+	defer meetingChecking.Store(false)
+
+	reason := meetingInProgress()
+	if reason == "" || !meetingMuted.CompareAndSwap(false, true) {
+		return
+	}
+	slog.Info("Meeting detected, muting sounds for this session", "reason", reason)
+	mu.Lock()
+	if currentSession != nil {
+		currentSession.MeetingMuted = true
+	}
+	mu.Unlock()
+}
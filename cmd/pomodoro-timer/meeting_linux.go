@@ -0,0 +1,21 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// meetingInProgress returns why a meeting is assumed, or "" if none is detected.
+// An ALSA capture stream in the RUNNING state means the microphone is in use;
+// PulseAudio and PipeWire keep it running while any app records.
+func meetingInProgress() string {
+	files, _ := filepath.Glob("/proc/asound/card*/pcm*c/sub*/status")
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err == nil && strings.Contains(string(data), "state: RUNNING") {
+			return "microphone in use"
+		}
+	}
+	return ""
+}
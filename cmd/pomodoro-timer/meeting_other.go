@@ -0,0 +1,9 @@
+//go:build !windows && !linux
+
+package main
+
+// meetingInProgress returns why a meeting is assumed, or "" if none is detected.
+// Meeting detection is not supported on this platform.
+func meetingInProgress() string {
+	return ""
+}
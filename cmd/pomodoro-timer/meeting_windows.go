@@ -0,0 +1,101 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// consentStorePath is the registry key where Windows records which apps use
+// privacy-sensitive devices.
+const consentStorePath = `Software\Microsoft\Windows\CurrentVersion\CapabilityAccessManager\ConsentStore`
+
+// meetingApps are executable names of conferencing apps, compared case-insensitively.
+var meetingApps = []string{
+	"Zoom.exe", "Teams.exe", "ms-teams.exe", "Webex.exe", "CiscoWebexStart.exe",
+	"atmgr.exe", "Skype.exe", "lync.exe", "g2mcomm.exe",
+}
+
+// meetingInProgress returns why a meeting is assumed, or "" if none is detected.
+func meetingInProgress() string {
+	if deviceInUse("microphone") {
+		return "microphone in use"
+	}
+	if deviceInUse("webcam") {
+		return "camera in use"
+	}
+	if app := foregroundApp(); isMeetingApp(app) {
+		return app + " in the foreground"
+	}
+	return ""
+}
+
+// deviceInUse reports whether any app is using the device. Windows sets
+// LastUsedTimeStop to 0 while an app is using it.
+func deviceInUse(device string) bool {
+	base := consentStorePath + `\` + device
+	return anyAppInUse(base) || anyAppInUse(base+`\NonPackaged`)
+}
+
+// anyAppInUse checks the app subkeys of a consent store key.
+func anyAppInUse(path string) bool {
+	key, err := registry.OpenKey(registry.CURRENT_USER, path, registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		return false
+	}
+	defer key.Close()
+
+	apps, err := key.ReadSubKeyNames(-1)
+	if err != nil {
+		return false
+	}
+	for _, app := range apps {
+		appKey, err := registry.OpenKey(key, app, registry.QUERY_VALUE)
+		if err != nil {
+			continue
+		}
+		start, _, errStart := appKey.GetIntegerValue("LastUsedTimeStart")
+		stop, _, errStop := appKey.GetIntegerValue("LastUsedTimeStop")
+		appKey.Close()
+		if errStart == nil && errStop == nil && start > 0 && stop == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// foregroundApp returns the executable name of the foreground window's process.
+func foregroundApp() string {
+	hwnd := windows.GetForegroundWindow()
+	if hwnd == 0 {
+		return ""
+	}
+	var pid uint32
+	if _, err := windows.GetWindowThreadProcessId(hwnd, &pid); err != nil || pid == 0 {
+		return ""
+	}
+	process, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return ""
+	}
+	defer windows.CloseHandle(process)
+
+	buf := make([]uint16, windows.MAX_PATH)
+	size := uint32(len(buf))
+	if err := windows.QueryFullProcessImageName(process, 0, &buf[0], &size); err != nil {
+		return ""
+	}
+	return filepath.Base(windows.UTF16ToString(buf[:size]))
+}
+
+// isMeetingApp reports whether the executable is a known conferencing app.
+func isMeetingApp(name string) bool {
+	for _, app := range meetingApps {
+		if strings.EqualFold(name, app) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"sync/atomic"
+
+	"github.com/lutischan-ferenc/systray"
+)
+
+// Actions offered on the notification when meeting mode is turned off.
+const (
+	actionResumeInterrupted  = "resume_interrupted"
+	actionRestartInterrupted = "restart_interrupted"
+)
+
+// meetingModeColor is the icon background in meeting mode, whatever the theme.
+var meetingModeColor = color.RGBA{0x6A, 0x1B, 0x9A, 0xFF}
+
+var (
+	meetingMode        atomic.Bool // All sounds and notifications are off until meeting mode is turned off
+	meetingInterrupted bool        // Meeting mode paused the running session, guarded by mu
+
+	mMeetingMode  *systray.MenuItem // Menu item for toggling meeting mode
+	mResumeAfter  *systray.MenuItem // Resumes the session paused by meeting mode
+	mRestartAfter *systray.MenuItem // Restarts the session paused by meeting mode
+)
+
+// addMeetingModeMenu adds the meeting mode toggle, and the items resuming or
+// restarting the interrupted session, which are shown after the meeting.
+func addMeetingModeMenu() {
+	mMeetingMode = systray.AddMenuItemCheckbox(tr("Meeting Mode"), tr("Pause the session and silence all sounds and notifications"), false)
+	mMeetingMode.Click(func() {
+		toggleMeetingMode()
+	})
+	mResumeAfter = systray.AddMenuItem(tr("Resume Interrupted Session"), tr("Continue the session paused for the meeting"))
+	mResumeAfter.Click(func() {
+		resumeInterruptedSession()
+	})
+	mRestartAfter = systray.AddMenuItem(tr("Restart Interrupted Session"), tr("Start the session paused for the meeting from the beginning"))
+	mRestartAfter.Click(func() {
+		restartInterruptedSession()
+	})
+	mResumeAfter.Hide()
+	mRestartAfter.Hide()
+}
+
+// toggleMeetingMode turns meeting mode on or off.
+func toggleMeetingMode() {
+	mu.Lock()
+	defer mu.Unlock()
+	if meetingMode.Load() {
+		leaveMeetingMode()
+	} else {
+		enterMeetingMode()
+	}
+}
+
+// enterMeetingMode pauses the running session, silences the alarms and
+// reminders, and shows the meeting icon. The caller must hold mu.
+func enterMeetingMode() {
+	meetingMode.Store(true)
+	acknowledgeAlarm()
+	stopIconFlash()
+	stopBreakReminders()
+	hideInterruptedMenu()
+	meetingInterrupted = false
+	if engine.Running() && !engine.Paused() {
+		pauseTimer()
+		meetingInterrupted = true
+	}
+	if mMeetingMode != nil {
+		mMeetingMode.Check()
+	}
+	redrawIcon()
+	setTooltip(tr("Meeting mode - Turn it off in the menu when the meeting is over"))
+}
+
+// leaveMeetingMode restores the sounds, notifications and icon, and offers
+// to resume or restart the session it paused. The caller must hold mu.
+func leaveMeetingMode() {
+	meetingMode.Store(false)
+	if mMeetingMode != nil {
+		mMeetingMode.Uncheck()
+	}
+	redrawIcon()
+	if !meetingInterrupted || !engine.Paused() {
+		meetingInterrupted = false
+		if !engine.Running() {
+			setTooltip(fmt.Sprintf(tr("Meeting over - Click to start %s"), nextSessionName()))
+		}
+		return
+	}
+
+	left := fmt.Sprintf("%02d:%02d", int(engine.Remaining().Minutes()), int(engine.Remaining().Seconds())%60)
+	setTooltip(fmt.Sprintf(tr("Meeting over, paused with %s left - Resume or restart it from the menu"), left))
+	if mResumeAfter != nil {
+		mResumeAfter.Show()
+		mRestartAfter.Show()
+	}
+	go sendActionNotification(tr("Meeting over"), fmt.Sprintf(tr("Resume the %s with %s left, or start it again?"), nextSessionName(), left), []notificationAction{
+		{actionResumeInterrupted, tr("Resume")},
+		{actionRestartInterrupted, tr("Restart")},
+	})
+}
+
+// nextSessionName returns "Pomodoro" or "break" for the session a click
+// continues: the running one, or the next one while stopped. The caller must hold mu.
+func nextSessionName() string {
+	pomodoroNext := engine.InPomodoro()
+	if !engine.Running() {
+		pomodoroNext = !pomodoroNext
+	}
+	if pomodoroNext {
+		return tr("Pomodoro")
+	}
+	return tr("break")
+}
+
+// resumeInterruptedSession continues the session paused by meeting mode.
+func resumeInterruptedSession() {
+	mu.Lock()
+	defer mu.Unlock()
+	if meetingInterrupted && engine.Paused() {
+		resumeTimer()
+	}
+}
+
+// restartInterruptedSession starts the session paused by meeting mode anew,
+// with its full length.
+func restartInterruptedSession() {
+	mu.Lock()
+	restart := meetingInterrupted && engine.Paused()
+	kind, duration := engine.Kind(), engine.Duration()
+	meetingInterrupted = false
+	hideInterruptedMenu()
+	mu.Unlock()
+	if restart {
+		handleTimerClick(kind, duration)
+	}
+}
+
+// meetingModeSessionChanged forgets the interrupted session once another
+// session starts or it is stopped.
+func meetingModeSessionChanged(sessionInfo) {
+	meetingInterrupted = false
+	hideInterruptedMenu()
+}
+
+// hideInterruptedMenu hides the items resuming or restarting the interrupted
+// session. The caller must hold mu.
+func hideInterruptedMenu() {
+	if mResumeAfter != nil {
+		mResumeAfter.Hide()
+		mRestartAfter.Hide()
+	}
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lutischan-ferenc/systray"
+	"pomodoro-timer/pkg/pomodoro"
+)
+
+// menuSeparator is the menu_layout entry adding a separator line.
+const menuSeparator = "separator"
+
+// defaultMenuLayout is the tray menu used while menu_layout is empty.
+var defaultMenuLayout = []string{
+	"website",
+	menuSeparator,
+	"start_pomodoro", "start_break", "start_long_break", "focus_until", "recent",
+	"pause", "meeting_mode", "quick_timers", "break_reminders", "interruptions", "note",
+	menuSeparator,
+	"tasks", "plan", "tags", "profiles", "jira", "teams", "google_calendar", "hue",
+	"autostart", "background_sound", "volume", "theme", "notifications",
+	menuSeparator,
+	"statistics", "heatmap", "export_calendar", "achievements", "dashboard", "settings", "settings_file",
+	"export_settings", "import_settings", "log", "warnings", "update",
+	menuSeparator,
+	"exit",
+}
+
+// buildMenu adds the entries of menu_layout to the tray menu, or the default
+// menu if it is empty.
+func buildMenu() {
+	layout := settings.MenuLayout
+	if len(layout) == 0 {
+		layout = defaultMenuLayout
+	}
+	for _, id := range layout {
+		if id == menuSeparator {
+			systray.AddSeparator()
+		} else if add := menuEntry(id); add != nil {
+			add()
+		}
+	}
+}
+
+// menuEntry returns the function adding the menu_layout entry with the given
+// ID, or nil if there is none. Besides the fixed entries, "pomodoro:50" and
+// "break:10" start a session of that many minutes.
+func menuEntry(id string) func() {
+	if kind, minutes, ok := parseMenuDuration(id); ok {
+		return func() { addDurationMenu(kind, minutes) }
+	}
+	switch id {
+	case "website":
+		return func() {
+			mWeb := systray.AddMenuItem("Pomodoro Timer "+version, tr("Open the website in browser"))
+			mWeb.Click(func() {
+				openBrowser("https://github.com/lutischan-ferenc/pomodoro-timer")
+			})
+		}
+	case "start_pomodoro":
+		return func() {
+			mPomodoro = systray.AddMenuItem(tr("Start Pomodoro"), tr("Start a new Pomodoro session"))
+			mPomodoro.Click(func() {
+				startPomodoro()
+			})
+		}
+	case "start_break":
+		return func() {
+			mBreak = systray.AddMenuItem(tr("Start Break"), tr("Take a break"))
+			mBreak.Click(func() {
+				handleTimerClick(pomodoro.Break, shortBreakDuration())
+			})
+		}
+	case "start_long_break":
+		return func() {
+			mLongBreak = systray.AddMenuItem(tr("Start Long Break"), tr("Take a long break"))
+			mLongBreak.Click(func() {
+				handleTimerClick(pomodoro.Break, longBreakDuration())
+			})
+		}
+	case "focus_until":
+		return func() {
+			mFocusUntil := systray.AddMenuItem(tr("Focus Until..."), tr("Start a Pomodoro ending at a time of day"))
+			mFocusUntil.Click(func() {
+				openFocusUntil()
+			})
+		}
+	case "recent":
+		return addRecentMenu
+	case "pause":
+		return addPauseMenu
+	case "meeting_mode":
+		return addMeetingModeMenu
+	case "quick_timers":
+		return addQuickTimerMenu
+	case "break_reminders":
+		return addBreakReminderMenu
+	case "interruptions":
+		return addInterruptionMenu
+	case "note":
+		return func() {
+			mNote := systray.AddMenuItem(tr("Add Note to Last Pomodoro..."), tr("Write a short note about the last Pomodoro"))
+			mNote.Click(func() {
+				addNoteToLastPomodoro()
+			})
+		}
+	case "tasks":
+		return addTaskMenu
+	case "plan":
+		return addPlanMenu
+	case "tags":
+		return addTagMenu
+	case "profiles":
+		return addProfileMenu
+	case "jira":
+		return addJiraMenu
+	case "teams":
+		return addTeamsMenu
+	case "google_calendar":
+		return addGoogleCalendarMenu
+	case "hue":
+		return addHueMenu
+	case "autostart":
+		return addAutoStartMenu
+	case "background_sound":
+		return addBackgroundSoundMenu
+	case "volume":
+		return addVolumeMenu
+	case "theme":
+		return addThemeMenu
+	case "notifications":
+		return func() {
+			mNotifications = systray.AddMenuItemCheckbox(tr("Notifications"), tr("Show a desktop notification when a session finishes"), settings.EnableNotifications)
+			mNotifications.Click(func() {
+				mu.Lock()
+				settings.EnableNotifications = !settings.EnableNotifications
+				enabled := settings.EnableNotifications
+				saveSettings()
+				mu.Unlock()
+				if enabled {
+					mNotifications.Check()
+				} else {
+					mNotifications.Uncheck()
+				}
+			})
+		}
+	case "statistics":
+		return func() {
+			mStatistics := systray.AddMenuItem(tr("Statistics..."), tr("Show statistics of the session history"))
+			mStatistics.Click(func() {
+				openStatistics()
+			})
+		}
+	case "heatmap":
+		return func() {
+			mHeatmap := systray.AddMenuItem(tr("Export Heatmap..."), tr("Save a picture of the Pomodoros per day of the last year"))
+			mHeatmap.Click(func() {
+				exportHeatmap()
+			})
+		}
+	case "export_calendar":
+		return func() {
+			mCalendarExport := systray.AddMenuItem(tr("Export as .ics..."), tr("Save the sessions of a date range as calendar events"))
+			mCalendarExport.Click(func() {
+				openCalendarExport()
+			})
+		}
+	case "achievements":
+		return addAchievementsMenu
+	case "dashboard":
+		return func() {
+			mDashboard := systray.AddMenuItem(tr("Open Dashboard..."), tr("Show the timer, today's statistics and settings in the browser"))
+			mDashboard.Click(func() {
+				openDashboard()
+			})
+		}
+	case "settings":
+		return func() {
+			mSettings := systray.AddMenuItem(tr("Settings..."), tr("Configure timers, sounds and the icon"))
+			mSettings.Click(func() {
+				openSettingsForm()
+			})
+		}
+	case "settings_file":
+		return func() {
+			mSettingsFile := systray.AddMenuItem(tr("Edit Settings File..."), tr("Edit all settings as JSON"))
+			mSettingsFile.Click(func() {
+				openSettingsEditor()
+			})
+		}
+	case "export_settings":
+		return func() {
+			mExport := systray.AddMenuItem(tr("Export Settings..."), tr("Save the settings, profiles and tasks to a file"))
+			mExport.Click(func() {
+				exportSettings()
+			})
+		}
+	case "import_settings":
+		return func() {
+			mImport := systray.AddMenuItem(tr("Import Settings..."), tr("Replace the settings, profiles and tasks with an exported file"))
+			mImport.Click(func() {
+				openImportSettings()
+			})
+		}
+	case "log":
+		return func() {
+			mLog := systray.AddMenuItem(tr("Open Log File..."), tr("Show the log of errors and events"))
+			mLog.Click(func() {
+				openLogFile()
+			})
+		}
+	case "warnings":
+		// Shown only while the settings have problems or there is no sound device
+		return func() {
+			mSettingsProblems = systray.AddMenuItem(tr("⚠ Settings Problems..."), tr("Show what is wrong in the settings file and which defaults are used"))
+			mSettingsProblems.Click(func() {
+				showSettingsProblems()
+			})
+			updateSettingsProblemsMenu()
+			addNoSoundMenu()
+		}
+	case "update":
+		return addUpdateMenu
+	case "exit":
+		return func() {
+			mQuit := systray.AddMenuItem(tr("Exit"), tr("Exit the application"))
+			mQuit.Click(func() {
+				systray.Quit()
+			})
+		}
+	}
+	return nil
+}
+
+// parseMenuDuration parses a menu_layout entry like "pomodoro:50" or
+// "break:10" starting a session of that many minutes.
+func parseMenuDuration(id string) (pomodoro.Kind, int, bool) {
+	name, value, ok := strings.Cut(id, ":")
+	kind := pomodoro.Kind(name)
+	if !ok || (kind != pomodoro.Pomodoro && kind != pomodoro.Break) {
+		return "", 0, false
+	}
+	minutes, err := strconv.Atoi(value)
+	if err != nil || minutes < 1 || minutes > 600 {
+		return "", 0, false
+	}
+	return kind, minutes, true
+}
+
+// addDurationMenu adds a menu item starting a session of the given kind and minutes.
+func addDurationMenu(kind pomodoro.Kind, minutes int) {
+	title := fmt.Sprintf(tr("Start %d-Minute Pomodoro"), minutes)
+	if kind == pomodoro.Break {
+		title = fmt.Sprintf(tr("Start %d-Minute Break"), minutes)
+	}
+	item := systray.AddMenuItem(title, tr("Start a session of this length, whatever the configured one"))
+	item.Click(func() {
+		handleTimerClick(kind, time.Duration(minutes)*time.Minute)
+	})
+}
+
+// validateMenuLayout drops unknown and repeated menu_layout entries, and adds
+// Exit if it is missing, so that the app can always be closed from the menu.
+func validateMenuLayout(s *TimerSettings) []string {
+	if len(s.MenuLayout) == 0 {
+		return nil
+	}
+	var problems []string
+	var valid []string
+	seen := map[string]bool{}
+	for _, id := range s.MenuLayout {
+		switch {
+		case id == menuSeparator:
+		case menuEntry(id) == nil:
+			problems = append(problems, fmt.Sprintf("menu_layout: %q is not a menu entry like \"start_pomodoro\" or \"pomodoro:50\"; ignoring it", id))
+			continue
+		case seen[id]:
+			problems = append(problems, fmt.Sprintf("menu_layout: %q is listed twice; ignoring the second one", id))
+			continue
+		}
+		seen[id] = true
+		valid = append(valid, id)
+	}
+	if !seen["exit"] {
+		problems = append(problems, "menu_layout: \"exit\" is missing; adding it at the end")
+		valid = append(valid, menuSeparator, "exit")
+	}
+	if len(problems) > 0 {
+		s.MenuLayout = valid
+	}
+	return problems
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// handleAPIMetrics returns the totals of the history and the state of the
+// timer in the Prometheus text format, to be scraped into Prometheus or Grafana.
+func handleAPIMetrics(w http.ResponseWriter, r *http.Request) {
+	records, err := loadHistory()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var stats sessionStats
+	for _, record := range records {
+		stats.add(record)
+	}
+	mu.Lock()
+	status := currentTimerStatus()
+	mu.Unlock()
+
+	var sb strings.Builder
+	writeMetric(&sb, "pomodoros_completed_total", "counter", "Pomodoros completed.", float64(stats.pomodoros))
+	writeMetric(&sb, "pomodoros_stopped_total", "counter", "Pomodoros stopped early.", float64(stats.stopped))
+	writeMetric(&sb, "focus_seconds_total", "counter", "Time spent in completed Pomodoros.", stats.focus.Seconds())
+	writeEnumMetric(&sb, "session_state", "Whether the timer is in the state.", "state", []string{"running", "paused", "stopped"}, status.State)
+	writeEnumMetric(&sb, "session_phase", "Whether the running or last session is in the phase.", "phase", []string{sessionPomodoro, sessionBreak}, status.Phase)
+	writeMetric(&sb, "remaining_seconds", "gauge", "Time left in the running session.", float64(status.RemainingSeconds))
+	writeMetric(&sb, "pomodoro_count", "gauge", "Pomodoros completed in the current cycle.", float64(status.PomodoroCount))
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write([]byte(sb.String()))
+}
+
+// writeMetric writes a metric without labels with its help and type lines.
+func writeMetric(sb *strings.Builder, name, kind, help string, value float64) {
+	fmt.Fprintf(sb, "# HELP %s %s\n# TYPE %s %s\n%s %g\n", name, help, name, kind, name, value)
+}
+
+// writeEnumMetric writes a gauge with a series per value of the label, which
+// is 1 for the current value and 0 for the others.
+func writeEnumMetric(sb *strings.Builder, name, help, label string, values []string, current string) {
+	fmt.Fprintf(sb, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	for _, value := range values {
+		set := 0
+		if value == current {
+			set = 1
+		}
+		fmt.Fprintf(sb, "%s{%s=%q} %d\n", name, label, value, set)
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// MQTTSettings configures publishing the timer to an MQTT broker.
+type MQTTSettings struct {
+	Broker   string `json:"broker"`   // Broker URL, e.g. "tcp://homeassistant.local:1883", empty to disable
+	Username string `json:"username"` // User name, empty if the broker allows anonymous clients
+	Password string `json:"password"` // Password of the user
+	Topic    string `json:"topic"`    // Base topic, "pomodoro-timer" if empty
+
+	HomeAssistant   bool   `json:"home_assistant"`   // Announce the timer to Home Assistant by MQTT discovery
+	DiscoveryPrefix string `json:"discovery_prefix"` // Home Assistant discovery prefix, "homeassistant" if empty
+}
+
+// mqttTimeout is how long to wait for the broker to accept a message.
+const mqttTimeout = 5 * time.Second
+
+// mqttSchemes are the broker URL schemes the MQTT client supports.
+var mqttSchemes = []string{"tcp", "mqtt", "ssl", "tls", "mqtts", "ws", "wss"}
+
+var (
+	mqttMu     sync.Mutex
+	mqttClient mqtt.Client     // Connected client, nil if disabled, guarded by mqttMu
+	mqttConfig MQTTSettings    // Settings the client was started with, guarded by mqttMu
+	mqttEvents chan timerEvent // Events published by the client, guarded by mqttMu
+)
+
+// topic returns the full topic of a subtopic, e.g. "pomodoro-timer/state".
+func (m MQTTSettings) topic(name string) string {
+	base := strings.Trim(m.Topic, "/")
+	if base == "" {
+		base = appDirName
+	}
+	return base + "/" + name
+}
+
+// validateMQTT checks the broker URL, returning the problems found. An
+// invalid broker is cleared, disabling MQTT.
+func validateMQTT(m *MQTTSettings) []string {
+	if m.Broker == "" {
+		return nil
+	}
+	u, err := url.Parse(m.Broker)
+	if err != nil || !containsString(mqttSchemes, u.Scheme) || u.Host == "" {
+		problem := fmt.Sprintf("mqtt.broker: %q is not a broker URL like tcp://host:1883; MQTT is disabled", m.Broker)
+		m.Broker = ""
+		return []string{problem}
+	}
+	return nil
+}
+
+// updateMQTT connects to, reconnects to or disconnects from the MQTT broker
+// to match the settings.
+func updateMQTT() {
+	mqttMu.Lock()
+	defer mqttMu.Unlock()
+	if settings.MQTT == mqttConfig {
+		return
+	}
+	if mqttClient != nil {
+		unsubscribeEvents(mqttEvents)
+		close(mqttEvents)
+		if mqttClient.IsConnectionOpen() {
+			if mqttConfig.HomeAssistant && !settings.MQTT.HomeAssistant {
+				removeHomeAssistantDiscovery(mqttClient, mqttConfig)
+			}
+			mqttClient.Publish(mqttConfig.topic("availability"), 1, true, "offline").WaitTimeout(mqttTimeout)
+		}
+		mqttClient.Disconnect(250)
+		mqttClient = nil
+	}
+	mqttConfig = settings.MQTT
+	if mqttConfig.Broker == "" {
+		return
+	}
+
+	config := mqttConfig
+	hostname, _ := os.Hostname()
+	opts := mqtt.NewClientOptions().
+		AddBroker(config.Broker).
+		SetClientID(appDirName+"-"+hostname).
+		SetUsername(config.Username).
+		SetPassword(config.Password).
+		SetWill(config.topic("availability"), "offline", 1, true).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetOnConnectHandler(func(client mqtt.Client) {
+			mqttConnected(client, config)
+		}).
+		SetConnectionLostHandler(func(client mqtt.Client, err error) {
+			slog.Error("MQTT connection lost", "err", err)
+		})
+	mqttClient = mqtt.NewClient(opts)
+	mqttClient.Connect() // Retries in the background until the broker is reachable
+	mqttEvents = subscribeEvents()
+	go publishMQTTEvents(mqttClient, config, mqttEvents)
+}
+
+// mqttConnected announces the app and its status and subscribes to the
+// command topic, after every (re)connection.
+func mqttConnected(client mqtt.Client, config MQTTSettings) {
+	slog.Info("Connected to MQTT broker", "broker", config.Broker)
+	if config.HomeAssistant {
+		publishHomeAssistantDiscovery(client, config)
+	}
+	publishMQTT(client, config.topic("availability"), true, "online")
+	mu.Lock()
+	status := currentTimerStatus()
+	mu.Unlock()
+	publishMQTTStatus(client, config, status)
+
+	client.Subscribe(config.topic("command"), 1, func(client mqtt.Client, msg mqtt.Message) {
+		args := strings.Fields(string(msg.Payload()))
+		if len(args) == 0 || !controlCommands[args[0]] {
+			slog.Warn("Unknown MQTT command", "command", string(msg.Payload()))
+			return
+		}
+		if status := runControlCommand(args); status.Error != "" {
+			slog.Error("MQTT command failed", "err", status.Error)
+		}
+	})
+}
+
+// publishMQTTEvents publishes the timer events until events is closed. The
+// status is published on every change, the remaining time every minute.
+func publishMQTTEvents(client mqtt.Client, config MQTTSettings, events chan timerEvent) {
+	for event := range events {
+		if event.name == eventTick {
+			if event.status.RemainingSeconds%60 == 0 {
+				publishMQTT(client, config.topic("remaining"), true, strconv.Itoa(remainingMinutes(event.status)))
+			}
+			continue
+		}
+		publishMQTT(client, config.topic("event"), false, event.name)
+		publishMQTTStatus(client, config, event.status)
+	}
+}
+
+// publishMQTTStatus publishes the status as JSON and the remaining minutes.
+func publishMQTTStatus(client mqtt.Client, config MQTTSettings, status timerStatus) {
+	data, _ := json.Marshal(status)
+	publishMQTT(client, config.topic("state"), true, string(data))
+	publishMQTT(client, config.topic("remaining"), true, strconv.Itoa(remainingMinutes(status)))
+}
+
+// remainingMinutes returns the minutes left in the running session, rounded
+// up, or 0 while stopped.
+func remainingMinutes(status timerStatus) int {
+	if status.State == "stopped" {
+		return 0
+	}
+	return (status.RemainingSeconds + 59) / 60
+}
+
+// publishMQTT publishes a message without waiting, logging failures. While
+// disconnected, messages are dropped.
+func publishMQTT(client mqtt.Client, topic string, retained bool, payload string) {
+	if !client.IsConnectionOpen() {
+		return
+	}
+	token := client.Publish(topic, 1, retained, payload)
+	go func() {
+		if token.WaitTimeout(mqttTimeout) && token.Error() != nil {
+			slog.Error("Failed to publish to MQTT", "err", token.Error())
+		}
+	}()
+}
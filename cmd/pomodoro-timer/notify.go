@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const notificationAppName = "Pomodoro Timer"
+
+// Actions offered on the session finished notification.
+const (
+	actionStartPomodoro = "start_pomodoro"
+	actionStartBreak    = "start_break"
+	actionSnooze        = "snooze"
+
+	actionDismissReminders = "dismiss_reminders"
+)
+
+const snoozeDuration = 5 * time.Minute
+
+// notificationAction is a button shown on a notification.
+type notificationAction struct {
+	ID    string // Identifier passed back when the button is clicked
+	Label string // Text of the button
+}
+
+// sendNotification shows a native desktop notification unless notifications
+// are disabled or meeting mode is on.
+func sendNotification(title, message string) {
+	if !settings.EnableNotifications || meetingMode.Load() {
+		return
+	}
+	if err := showNotification(title, message); err != nil {
+		slog.Error("Failed to show notification", "err", err)
+	}
+}
+
+// sendActionNotification shows a notification with action buttons and calls
+// handleNotificationAction with the clicked one. Platforms without action
+// support show a plain notification.
+func sendActionNotification(title, message string, actions []notificationAction) {
+	if !settings.EnableNotifications || meetingMode.Load() {
+		return
+	}
+	action, err := showActionNotification(title, message, actions)
+	if err != nil {
+		slog.Error("Failed to show notification", "err", err)
+		return
+	}
+	if action != "" {
+		handleNotificationAction(action, title, message, actions)
+	}
+}
+
+// notifySessionFinished notifies that a session has finished and what comes next.
+// The caller must hold mu, so the notification is sent asynchronously.
+func notifySessionFinished(wasPomodoro bool) {
+	var title, message string
+	var actions []notificationAction
+	if wasPomodoro {
+		title = tr("Pomodoro finished")
+		if engine.LongBreakNext() {
+			message = fmt.Sprintf(tr("Time for a %d minute long break"), int(longBreakDuration().Minutes()))
+		} else {
+			message = fmt.Sprintf(tr("Time for a %d minute break"), int(shortBreakDuration().Minutes()))
+		}
+		actions = []notificationAction{{actionStartBreak, tr("Start Break")}}
+	} else {
+		title = tr("Break finished")
+		message = fmt.Sprintf(tr("Time to focus for %d minutes"), int(pomodoroDuration().Minutes()))
+		actions = []notificationAction{{actionStartPomodoro, tr("Start Pomodoro")}}
+	}
+	actions = append(actions, notificationAction{actionSnooze, fmt.Sprintf(tr("Snooze %d min"), int(snoozeDuration.Minutes()))})
+	go sendActionNotification(title, message, actions)
+	go sendPushNotification(title, message)
+}
+
+// handleNotificationAction routes a clicked notification button into the timer.
+// Actions are ignored once another session has been started in the meantime.
+func handleNotificationAction(action, title, message string, actions []notificationAction) {
+	acknowledgeAlarm()
+
+	switch action {
+	case actionResumeInterrupted:
+		resumeInterruptedSession()
+		return
+	case actionRestartInterrupted:
+		restartInterruptedSession()
+		return
+	case actionConfirmStop:
+		confirmStop()
+		return
+	case actionCancelStop:
+		return
+	}
+
+	mu.Lock()
+	stopIconFlash()
+	running := engine.Running()
+	mu.Unlock()
+	if running {
+		return
+	}
+
+	switch action {
+	case actionDismissReminders:
+		mu.Lock()
+		stopBreakReminders()
+		mu.Unlock()
+	case actionStartPomodoro:
+		startPomodoro()
+	case actionStartBreak:
+		startBreak()
+	case actionSnooze:
+		// Remind again later, unless a session was started in the meantime
+		time.AfterFunc(snoozeDuration, func() {
+			mu.Lock()
+			running := engine.Running()
+			mu.Unlock()
+			if !running {
+				sendActionNotification(title, message, actions)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// showNotification shows a Notification Center notification through AppleScript.
+func showNotification(title, message string) error {
+	script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+	return exec.Command("osascript", "-e", script).Run()
+}
+
+// appleScriptString quotes text as an AppleScript string literal.
+func appleScriptString(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\\`)
+	text = strings.ReplaceAll(text, `"`, `\"`)
+	return `"` + text + `"`
+}
+
+// showActionNotification shows a plain notification, as AppleScript
+// notifications cannot carry action buttons.
+func showActionNotification(title, message string, actions []notificationAction) (string, error) {
+	return "", showNotification(title, message)
+}
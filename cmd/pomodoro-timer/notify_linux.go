@@ -0,0 +1,26 @@
+package main
+
+import (
+	"github.com/godbus/dbus/v5"
+)
+
+// showNotification shows a notification through the freedesktop.org
+// notification service on the D-Bus session bus, as libnotify does.
+func showNotification(title, message string) error {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return err
+	}
+
+	obj := conn.Object("org.freedesktop.Notifications", "/org/freedesktop/Notifications")
+	call := obj.Call("org.freedesktop.Notifications.Notify", 0,
+		notificationAppName, uint32(0), "", title, message,
+		[]string{}, map[string]dbus.Variant{}, int32(-1))
+	return call.Err
+}
+
+// showActionNotification shows a plain notification. Action buttons are only
+// supported on Windows.
+func showActionNotification(title, message string, actions []notificationAction) (string, error) {
+	return "", showNotification(title, message)
+}
@@ -0,0 +1,15 @@
+//go:build !windows && !darwin && !linux
+
+package main
+
+import "fmt"
+
+// showNotification reports that notifications are not supported on this platform.
+func showNotification(title, message string) error {
+	return fmt.Errorf("notifications are not supported on this platform")
+}
+
+// showActionNotification reports that notifications are not supported on this platform.
+func showActionNotification(title, message string, actions []notificationAction) (string, error) {
+	return "", showNotification(title, message)
+}
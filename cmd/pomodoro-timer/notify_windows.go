@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf16"
+)
+
+// toastAppID is the AppUserModelID the toast is shown under. Unpackaged
+// applications cannot register their own, so the PowerShell one is used.
+const toastAppID = `{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe`
+
+const toastScript = `
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+[Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom.XmlDocument, ContentType = WindowsRuntime] | Out-Null
+$xml = New-Object Windows.Data.Xml.Dom.XmlDocument
+$xml.LoadXml(@'
+%s
+'@)
+$toast = New-Object Windows.UI.Notifications.ToastNotification $xml
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('%s').Show($toast)
+`
+
+// actionToastScript shows a toast and waits until it is activated, dismissed or
+// expires. The arguments of the clicked button are written to the standard output.
+const actionToastScript = `
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+[Windows.Data.Xml.Dom.XmlDocument, Windows.Data.Xml.Dom.XmlDocument, ContentType = WindowsRuntime] | Out-Null
+$xml = New-Object Windows.Data.Xml.Dom.XmlDocument
+$xml.LoadXml(@'
+%s
+'@)
+$toast = New-Object Windows.UI.Notifications.ToastNotification $xml
+$toast.ExpirationTime = [DateTimeOffset]::Now.AddMinutes(%d)
+Register-ObjectEvent -InputObject $toast -EventName Activated -SourceIdentifier ToastActivated | Out-Null
+Register-ObjectEvent -InputObject $toast -EventName Dismissed -SourceIdentifier ToastDismissed | Out-Null
+Register-ObjectEvent -InputObject $toast -EventName Failed -SourceIdentifier ToastFailed | Out-Null
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('%s').Show($toast)
+while ($true) {
+	$received = Wait-Event -Timeout %d
+	if (-not $received) { break }
+	Remove-Event -EventIdentifier $received.EventIdentifier
+	if ($received.SourceIdentifier -eq 'ToastActivated') {
+		[Console]::Out.WriteLine(([Windows.UI.Notifications.ToastActivatedEventArgs]$received.SourceEventArgs).Arguments)
+		break
+	}
+	if ($received.SourceIdentifier -eq 'ToastFailed') { break }
+	if ($received.SourceIdentifier -eq 'ToastDismissed' -and $received.SourceEventArgs.Reason -ne 'TimedOut') { break }
+}
+`
+
+// actionToastLifetime is how long the buttons of an actionable toast stay usable.
+// The toast disappears from the Action Center when it expires.
+const actionToastLifetime = 10 * time.Minute
+
+// showNotification shows a Windows toast notification through PowerShell.
+func showNotification(title, message string) error {
+	toastXML := fmt.Sprintf(`<toast><visual><binding template="ToastGeneric"><text>%s</text><text>%s</text></binding></visual></toast>`,
+		escapeXML(title), escapeXML(message))
+	return runPowerShell(fmt.Sprintf(toastScript, toastXML, toastAppID))
+}
+
+// showActionNotification shows a Windows toast notification with action buttons
+// and returns the ID of the clicked button, or an empty string if none was clicked.
+func showActionNotification(title, message string, actions []notificationAction) (string, error) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<toast><visual><binding template="ToastGeneric"><text>%s</text><text>%s</text></binding></visual><actions>`,
+		escapeXML(title), escapeXML(message))
+	for _, action := range actions {
+		fmt.Fprintf(&sb, `<action content="%s" arguments="%s" activationType="foreground"/>`, escapeXML(action.Label), escapeXML(action.ID))
+	}
+	sb.WriteString(`</actions></toast>`)
+
+	minutes := int(actionToastLifetime.Minutes())
+	cmd := newPowerShellCommand(fmt.Sprintf(actionToastScript, sb.String(), minutes, toastAppID, minutes*60))
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	clicked := strings.TrimSpace(string(out))
+	for _, action := range actions {
+		if action.ID == clicked {
+			return clicked, nil
+		}
+	}
+	return "", nil
+}
+
+// escapeXML escapes text for use in the toast XML.
+func escapeXML(text string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(text))
+	return sb.String()
+}
+
+// runPowerShell runs a PowerShell script without showing a console window.
+func runPowerShell(script string) error {
+	cmd := newPowerShellCommand(script)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// newPowerShellCommand creates a hidden PowerShell command running the given script.
+// The script is passed base64-encoded to avoid any quoting issues.
+func newPowerShellCommand(script string) *exec.Cmd {
+	encoded := utf16.Encode([]rune(script))
+	buf := make([]byte, len(encoded)*2)
+	for i, c := range encoded {
+		buf[2*i] = byte(c)
+		buf[2*i+1] = byte(c >> 8)
+	}
+
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass",
+		"-EncodedCommand", base64.StdEncoding.EncodeToString(buf))
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	return cmd
+}
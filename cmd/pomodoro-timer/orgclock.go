@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// OrgClockSettings configures the CLOCK entries written to an Org file.
+type OrgClockSettings struct {
+	File    string `json:"file"`    // Org file the entries are written to; empty to disable
+	Heading string `json:"heading"` // Title of the heading the entries are clocked under, added at the end of the file if missing
+}
+
+var (
+	orgHeadingPattern  = regexp.MustCompile(`^\*+\s+(.*?)(?:\s+:[\w@#%:]+:)?\s*$`)  // An Org heading, capturing its title without tags
+	orgKeywordPattern  = regexp.MustCompile(`^(?:[A-Z]+\s+)?(?:\[#[A-Z0-9]\]\s+)?`) // The TODO keyword and priority of a title
+	orgPlanningPattern = regexp.MustCompile(`^\s*(SCHEDULED|DEADLINE|CLOSED):`)     // The planning line of a heading
+)
+
+// isOrgHeading reports whether line is a heading with the given title,
+// ignoring its TODO keyword, priority and tags.
+func isOrgHeading(line, title string) bool {
+	m := orgHeadingPattern.FindStringSubmatch(line)
+	return m != nil && (m[1] == title || orgKeywordPattern.ReplaceAllString(m[1], "") == title)
+}
+
+// orgTimestamp formats an inactive Org timestamp, e.g. "[2025-03-14 Fri 14:00]".
+func orgTimestamp(t time.Time) string {
+	return t.Format("[2006-01-02 Mon 15:04]")
+}
+
+// orgClockLine returns the CLOCK line of a completed Pomodoro as org-clock writes it.
+func orgClockLine(record SessionRecord) string {
+	start := record.Start.Truncate(time.Minute)
+	end := record.End.Truncate(time.Minute)
+	minutes := int(end.Sub(start).Minutes())
+	return fmt.Sprintf("CLOCK: %s--%s => %2d:%02d", orgTimestamp(start), orgTimestamp(end), minutes/60, minutes%60)
+}
+
+// logOrgClock writes a completed Pomodoro as a CLOCK entry into the LOGBOOK
+// drawer of the configured heading, so Org clock reports include it.
+func logOrgClock(record SessionRecord) {
+	org := settings.OrgClock
+	if org.File == "" || org.Heading == "" || !record.Completed {
+		return
+	}
+	data, err := ioutil.ReadFile(org.File)
+	if err != nil && !os.IsNotExist(err) {
+		slog.Error("Failed to read Org file", "err", err)
+		return
+	}
+	doc := insertOrgClock(string(data), org.Heading, orgClockLine(record))
+	if err := ioutil.WriteFile(org.File, []byte(doc), 0644); err != nil {
+		slog.Error("Failed to write Org file", "err", err)
+	}
+}
+
+// insertOrgClock adds a CLOCK line to the top of the LOGBOOK drawer of the
+// heading with the given title, creating the drawer or the heading if missing.
+func insertOrgClock(doc, heading, clock string) string {
+	lines := strings.Split(strings.TrimRight(doc, "\n"), "\n")
+	if doc == "" {
+		lines = nil
+	}
+	at := -1
+	for i, line := range lines {
+		if isOrgHeading(line, heading) {
+			at = i + 1
+			break
+		}
+	}
+	if at < 0 {
+		lines = append(lines, "* "+heading)
+		at = len(lines)
+	}
+
+	// The drawer follows the planning line and the properties drawer.
+	if at < len(lines) && orgPlanningPattern.MatchString(lines[at]) {
+		at++
+	}
+	if at < len(lines) && strings.TrimSpace(lines[at]) == ":PROPERTIES:" {
+		for at < len(lines) && strings.TrimSpace(lines[at]) != ":END:" {
+			at++
+		}
+		at++
+	}
+	insert := []string{":LOGBOOK:", clock, ":END:"}
+	if at < len(lines) && strings.TrimSpace(lines[at]) == ":LOGBOOK:" {
+		at++
+		insert = []string{clock}
+	}
+	if at > len(lines) {
+		at = len(lines)
+	}
+	lines = append(lines[:at], append(insert, lines[at:]...)...)
+	return strings.Join(lines, "\n") + "\n"
+}
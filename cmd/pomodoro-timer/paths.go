@@ -0,0 +1,153 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+const (
+	appDirName       = "pomodoro-timer" // Name of the app's directory in the platform's config, data and cache directories
+	portableFlagFile = "portable.flag"  // File next to the executable turning on portable mode
+	portableDirName  = "pomodoro-data"  // Directory next to the executable holding all files in portable mode
+)
+
+var portableDir string // Directory holding all files in portable mode, empty otherwise
+
+// legacyFiles maps the files formerly stored in the home directory to their
+// current paths.
+var legacyFiles = []struct {
+	name string
+	path func() string
+}{
+	{".pomodoro_settings.json", getSettingsPath},
+	{".pomodoro_history.jsonl", getHistoryPath},
+	{".pomodoro_tasks.json", getTasksPath},
+	{".pomodoro_teams_token.json", getTeamsTokenPath},
+}
+
+// initPortableMode turns on portable mode if the app was started with
+// -portable or a portable.flag file is next to the executable. All files are
+// then kept in a directory next to the executable instead of the user's
+// directories, e.g. to run the app from a USB stick.
+func initPortableMode() {
+	enabled := false
+	for _, arg := range os.Args[1:] {
+		if arg == "-portable" || arg == "--portable" {
+			enabled = true
+		}
+	}
+	exePath, err := os.Executable()
+	if err != nil {
+		return
+	}
+	exeDir := filepath.Dir(exePath)
+	if _, err := os.Stat(filepath.Join(exeDir, portableFlagFile)); err == nil {
+		enabled = true
+	}
+	if enabled {
+		portableDir = filepath.Join(exeDir, portableDirName)
+	}
+}
+
+// appDir returns the app's directory below base, creating it if needed. If
+// base is unknown, the home directory is used. In portable mode all files
+// are in the portable directory.
+func appDir(base string, err error) string {
+	if portableDir != "" {
+		base = portableDir
+	} else if err != nil || base == "" {
+		home, homeErr := os.UserHomeDir()
+		if homeErr != nil {
+			home = "."
+		}
+		base = filepath.Join(home, "."+appDirName)
+	} else {
+		base = filepath.Join(base, appDirName)
+	}
+	if err := os.MkdirAll(base, 0700); err != nil {
+		slog.Error("Failed to create directory", "err", err)
+	}
+	return base
+}
+
+// getConfigFilePath returns the path to a file in the config directory:
+// %APPDATA%, ~/Library/Application Support or ~/.config.
+func getConfigFilePath(name string) string {
+	return filepath.Join(appDir(os.UserConfigDir()), name)
+}
+
+// getDataFilePath returns the path to a file in the data directory: the same
+// as the config directory on Windows and macOS, ~/.local/share on Linux.
+func getDataFilePath(name string) string {
+	if runtime.GOOS != "windows" && runtime.GOOS != "darwin" {
+		if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+			return filepath.Join(appDir(dir, nil), name)
+		}
+		home, err := os.UserHomeDir()
+		return filepath.Join(appDir(filepath.Join(home, ".local", "share"), err), name)
+	}
+	return getConfigFilePath(name)
+}
+
+// getCacheFilePath returns the path to a file that can be recreated, in
+// %LOCALAPPDATA%, ~/Library/Caches or ~/.cache.
+func getCacheFilePath(name string) string {
+	return filepath.Join(appDir(os.UserCacheDir()), name)
+}
+
+// migrateLegacyFiles moves the files from the home directory, where older
+// versions stored them, to the platform's directories. Files that already
+// exist at the new path are left alone. Portable copies leave them alone.
+func migrateLegacyFiles() {
+	if portableDir != "" {
+		return
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return
+	}
+	for _, file := range legacyFiles {
+		oldPath := filepath.Join(home, file.name)
+		newPath := file.path()
+		if _, err := os.Stat(oldPath); err != nil {
+			continue
+		}
+		if _, err := os.Stat(newPath); err == nil {
+			continue
+		}
+		if err := moveFile(oldPath, newPath); err != nil {
+			slog.Error("Failed to move file", "from", oldPath, "to", newPath, "err", err)
+			continue
+		}
+		slog.Info("Moved file", "from", oldPath, "to", newPath)
+	}
+}
+
+// moveFile renames a file, copying it if the paths are on different volumes.
+func moveFile(oldPath, newPath string) error {
+	if err := os.Rename(oldPath, newPath); err == nil {
+		return nil
+	}
+	src, err := os.Open(oldPath)
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(newPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		src.Close()
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	src.Close()
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(newPath)
+		return err
+	}
+	return os.Remove(oldPath)
+}
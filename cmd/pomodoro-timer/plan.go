@@ -0,0 +1,243 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lutischan-ferenc/systray"
+)
+
+const planDateLayout = "2006-01-02"
+
+// PlanItem is a task with the Pomodoros planned for it.
+type PlanItem struct {
+	Task    string `json:"task"`    // Name of the task in the task list
+	Planned int    `json:"planned"` // Pomodoros planned for the day
+}
+
+// DayPlan is the plan made with "Plan My Day...". It is only used on its date.
+type DayPlan struct {
+	Date  string     `json:"date"`  // Day of the plan, e.g. 2026-10-17
+	Items []PlanItem `json:"items"` // Planned tasks, in the order entered
+}
+
+var (
+	planMu     sync.Mutex
+	dayPlan    DayPlan        // Plan of the day, guarded by planMu
+	planActual map[string]int // Pomodoros completed on the plan's date by task, "" without a task; guarded by planMu
+
+	mPlan *systray.MenuItem // Menu item for planning the day, showing the progress
+)
+
+// getPlanPath returns the path to the day plan file.
+func getPlanPath() string {
+	return getDataFilePath("plan.json")
+}
+
+// planToday returns the current date in the plan's format.
+func planToday() string {
+	return time.Now().Format(planDateLayout)
+}
+
+// loadPlan loads the day plan and counts the Pomodoros already completed on
+// its date from the history.
+func loadPlan() {
+	data, err := ioutil.ReadFile(getPlanPath())
+	if err != nil {
+		return
+	}
+	var plan DayPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		slog.Error("Failed to load the day plan", "err", err)
+		return
+	}
+	actual, err := completedOnDay(plan.Date)
+	if err != nil {
+		slog.Error("Failed to load history", "err", err)
+	}
+	planMu.Lock()
+	dayPlan = plan
+	planActual = actual
+	planMu.Unlock()
+}
+
+// savePlan saves the day plan to a file. The caller must hold planMu.
+func savePlan() {
+	data, err := json.MarshalIndent(dayPlan, "", "  ")
+	if err != nil {
+		slog.Error("Failed to save the day plan", "err", err)
+		return
+	}
+	if err := ioutil.WriteFile(getPlanPath(), data, 0644); err != nil {
+		slog.Error("Failed to write the day plan file", "err", err)
+	}
+}
+
+// completedOnDay counts the Pomodoros of the history completed on the given
+// date by task.
+func completedOnDay(date string) (map[string]int, error) {
+	records, err := loadHistory()
+	counts := map[string]int{}
+	for _, record := range records {
+		if record.Type == sessionPomodoro && record.Completed && record.Start.Local().Format(planDateLayout) == date {
+			counts[record.Task]++
+		}
+	}
+	return counts, err
+}
+
+// planTotals returns the Pomodoros planned and completed today, and whether
+// there is a plan for today. The caller must hold planMu.
+func planTotals() (planned, done int, ok bool) {
+	if dayPlan.Date != planToday() || len(dayPlan.Items) == 0 {
+		return 0, 0, false
+	}
+	for _, item := range dayPlan.Items {
+		planned += item.Planned
+	}
+	for _, count := range planActual {
+		done += count
+	}
+	return planned, done, true
+}
+
+// planProgressText returns the tooltip suffix comparing the Pomodoros
+// completed today with the plan, e.g. " - Plan 3/8".
+func planProgressText() string {
+	planMu.Lock()
+	defer planMu.Unlock()
+	planned, done, ok := planTotals()
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(tr(" - Plan %d/%d"), done, planned)
+}
+
+// planPomodoroCompleted counts a completed Pomodoro towards today's plan,
+// with a notification when the plan is fulfilled.
+func planPomodoroCompleted(s sessionInfo) {
+	planMu.Lock()
+	date := s.record.Start.Local().Format(planDateLayout)
+	if dayPlan.Date != date {
+		planMu.Unlock()
+		return
+	}
+	if planActual == nil {
+		planActual = map[string]int{}
+	}
+	planActual[s.record.Task]++
+	planned, done, ok := planTotals()
+	planMu.Unlock()
+
+	if ok && done == planned {
+		go sendNotification(tr("Day plan done"), fmt.Sprintf(tr("All %d planned Pomodoros are completed"), planned))
+	}
+	updatePlanMenu()
+}
+
+// addPlanMenu adds the menu item for planning the day.
+func addPlanMenu() {
+	mPlan = systray.AddMenuItem(tr("Plan My Day..."), tr("Plan the Pomodoros of today's tasks"))
+	mPlan.Click(func() {
+		openPlanEditor()
+	})
+	updatePlanMenu()
+}
+
+// updatePlanMenu shows today's progress in the plan menu item.
+func updatePlanMenu() {
+	if mPlan == nil {
+		return
+	}
+	planMu.Lock()
+	planned, done, ok := planTotals()
+	planMu.Unlock()
+	if ok {
+		mPlan.SetTitle(fmt.Sprintf(tr("Plan My Day (%d/%d)..."), done, planned))
+	} else {
+		mPlan.SetTitle(tr("Plan My Day..."))
+	}
+}
+
+// openPlanEditor opens today's plan in the default text editor, one
+// "task: Pomodoros" line per task. Without a plan for today, the tasks of the
+// task list are offered with 0 Pomodoros. Planned tasks missing from the
+// task list are added to it.
+func openPlanEditor() {
+	date := planToday()
+	actual, err := completedOnDay(date)
+	if err != nil {
+		slog.Error("Failed to load history", "err", err)
+	}
+
+	planMu.Lock()
+	items := dayPlan.Items
+	if dayPlan.Date != date {
+		items = nil
+	}
+	planMu.Unlock()
+	if items == nil {
+		tasksMu.Lock()
+		for _, task := range tasks.Tasks {
+			items = append(items, PlanItem{Task: task.Name})
+		}
+		tasksMu.Unlock()
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Plan for %s: one task per line followed by the planned Pomodoros, e.g.\n", time.Now().Format("Monday, January 2"))
+	sb.WriteString("# write report: 4\n")
+	sb.WriteString("# Tasks with 0 Pomodoros are left out of the plan.\n")
+	for _, item := range items {
+		fmt.Fprintf(&sb, "%s: %d\n", item.Task, item.Planned)
+	}
+	if len(actual) > 0 {
+		sb.WriteString("#\n# Completed today:\n")
+		for _, item := range items {
+			if actual[item.Task] > 0 {
+				fmt.Fprintf(&sb, "# %s: %d of %d\n", item.Task, actual[item.Task], item.Planned)
+			}
+		}
+	}
+
+	data, err := editInEditor("pomodoro_plan_*.txt", []byte(sb.String()))
+	if err != nil {
+		slog.Error("Failed to edit the day plan", "err", err)
+		return
+	}
+
+	var newItems []PlanItem
+	for _, line := range strings.Split(string(data), "\n") {
+		task, ok := parseTaskLine(line)
+		if !ok || task.Estimate == 0 {
+			continue
+		}
+		newItems = append(newItems, PlanItem{Task: task.Name, Planned: task.Estimate})
+	}
+
+	tasksMu.Lock()
+	added := false
+	for _, item := range newItems {
+		if findTask(item.Task) == nil {
+			tasks.Tasks = append(tasks.Tasks, Task{Name: item.Task, Estimate: item.Planned})
+			added = true
+		}
+	}
+	if added {
+		saveTasks()
+	}
+	tasksMu.Unlock()
+
+	planMu.Lock()
+	dayPlan = DayPlan{Date: date, Items: newItems}
+	planActual = actual
+	savePlan()
+	planMu.Unlock()
+	updateTaskMenu()
+	updatePlanMenu()
+}
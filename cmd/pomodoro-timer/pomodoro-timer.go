@@ -1,30 +1,23 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	_ "embed"
 	"encoding/json"
 	"fmt"
-	"image"
-	"image/color"
-	"io"
 	"io/ioutil"
-	"math"
+	"log/slog"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"runtime"
 	"sync"
 	"time"
 
-	"github.com/ebitengine/oto/v3"
-	"github.com/hajimehoshi/go-mp3"
 	"github.com/lutischan-ferenc/systray"
-	"golang.org/x/image/font"
 	"golang.org/x/image/font/opentype"
-	"golang.org/x/image/math/fixed"
-	"golang.org/x/sys/windows/registry"
-	"image/png"
+	"pomodoro-timer/internal/audio"
+	"pomodoro-timer/internal/icon"
+	"pomodoro-timer/pkg/pomodoro"
 )
 
 var (
@@ -34,23 +27,11 @@ var (
 	//go:embed assets/clock.mp3
 	clockSoundMP3 []byte
 
-	// MP3 player params
-	mp3Decoder    *mp3.Decoder
-	clockPlayer   *oto.Player
-	clockStopCh   chan struct{}
-	clockMutex    sync.Mutex
-	clockSoundPCM []byte
-
-	err           error
-	context       *oto.Context
-	pomodoroCount int           // Tracks the number of completed Pomodoro sessions
-	isRunning     bool          // Indicates if the timer is currently running
-	isInPomodoro  bool          // Indicates if the current session is a Pomodoro
-	remainingTime time.Duration // Tracks the remaining time for the current session
-	stopCh        chan struct{} // Channel to stop the timer
-	mu            sync.Mutex    // Mutex for thread-safe operations
-
-	ticker         *time.Ticker
+	audioPlayer audio.Player             // Plays the sounds, silent if there is no sound device
+	mu          sync.Mutex               // Mutex for thread-safe operations
+	engine      = pomodoro.New(&mu, nil) // Runs the timer, calling its events with mu held
+	stopEngine  context.CancelFunc       // Ends the countdown of the engine on exit
+
 	oldDisplayText string
 	settings       TimerSettings // Stores Pomodoro timer settings
 
@@ -58,253 +39,365 @@ var (
 	mBreak     *systray.MenuItem // Menu item for starting a break
 	mLongBreak *systray.MenuItem // Menu item for starting a long break
 	mAutoStart *systray.MenuItem
-	baseImage  *image.RGBA // Base image for the system tray icon
-	fontFace   font.Face   // Font face for rendering text on the icon
+
+	mNotifications *systray.MenuItem // Menu item for toggling notifications
 )
 
 // main is the entry point of the application.
 func main() {
-	initMp3Player()
+	initPortableMode()
+	if len(os.Args) > 1 && controlCommands[os.Args[1]] {
+		os.Exit(runControlClient(os.Args[1:]))
+	}
+	parseFlags()
+	initLogging()
+	if !lockInstance() {
+		os.Exit(forwardToInstance(os.Args[1:]))
+	}
+	startEngine()
 	initResources()
 	initAudio()
-	stopCh = make(chan struct{})
+	migrateLegacyFiles()
 	loadSettings()
-	systray.Run(onReady, nil)
-}
-
-func initMp3Player() {
-	reader := bytes.NewReader(clockSoundMP3)
-	mp3Decoder, err = mp3.NewDecoder(reader)
-	if err != nil {
-		fmt.Println("Error init sound player:", err)
+	initLanguage()
+	loadCustomSounds()
+	loadTasks()
+	loadPlan()
+	initTodayCount()
+	initRecentSessions()
+	startTelegramBot()
+	if launch.headless {
+		runHeadless()
 		return
 	}
+	systray.Run(onReady, onExit)
+}
 
-	// Decode MP3 to PCM
-	var pcmData []byte
-	buf := make([]byte, 1024)
-	for {
-		n, err := mp3Decoder.Read(buf)
-		if n > 0 {
-			pcmData = append(pcmData, buf[:n]...)
-		}
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			fmt.Println("Error decoding MP3:", err)
-			return
-		}
-	}
-	clockSoundPCM = pcmData
+// startEngine counts the sessions of the engine down until onExit.
+func startEngine() {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopEngine = cancel
+	go engine.Run(ctx)
 }
 
 // TimerSettings stores the durations for Pomodoro, short break, and long break.
 type TimerSettings struct {
-	PomodoroDuration   int  `json:"pomodoro_duration"`    // Duration of a Pomodoro session in minutes
-	ShortBreakDuration int  `json:"short_break_duration"` // Duration of a short break in minutes
-	LongBreakDuration  int  `json:"long_break_duration"`  // Duration of a long break in minutes
-	EnableClockSound   bool `json:"enable_clock_sound"`
+	PomodoroDuration    int    `json:"pomodoro_duration"`    // Duration of a Pomodoro session in minutes
+	ShortBreakDuration  int    `json:"short_break_duration"` // Duration of a short break in minutes
+	LongBreakDuration   int    `json:"long_break_duration"`  // Duration of a long break in minutes
+	EnableClockSound    bool   `json:"enable_clock_sound"`   // Play the background sound during Pomodoros
+	BackgroundSound     string `json:"background_sound"`     // "clock", "white_noise", "rain", "cafe" or "binaural"
+	EnableNotifications bool   `json:"enable_notifications"` // Show a desktop notification when a session finishes
+
+	ClockSoundPath       string `json:"clock_sound_path"`        // Audio file played instead of the embedded clock sound
+	PomodoroEndSoundPath string `json:"pomodoro_end_sound_path"` // Audio file played instead of the chime when a Pomodoro ends
+	BreakEndSoundPath    string `json:"break_end_sound_path"`    // Audio file played instead of the chime when a break ends
+
+	MasterVolume int  `json:"master_volume"` // Volume of all sounds, 0-100
+	ClockVolume  int  `json:"clock_volume"`  // Volume of the ticking sound, 0-100
+	AlarmVolume  int  `json:"alarm_volume"`  // Volume of the beeps and end-of-session sounds, 0-100
+	Muted        bool `json:"muted"`         // Silence all sounds
+
+	InsistentAlarm        bool `json:"insistent_alarm"`          // Repeat the end-of-session sound, getting louder, until acknowledged
+	FlashIconWhenFinished bool `json:"flash_icon_when_finished"` // Flash the tray icon after a session finishes until acknowledged
+
+	FocusAssist string `json:"focus_assist"` // Focus Assist during Pomodoros (Windows): "off", "priority" or "alarms"
+	DesktopDND  bool   `json:"desktop_dnd"`  // Turn on the desktop's Do Not Disturb during Pomodoros (GNOME, KDE Plasma)
+	PauseMedia  string `json:"pause_media"`  // Pause the playing music during "breaks" or "pomodoros", resuming it when the other starts, or "off"
+
+	Distractions DistractionSettings `json:"distractions"` // Processes to warn about, minimize or end during Pomodoros
+
+	ClockFadeMilliseconds int `json:"clock_fade_ms"` // Fade the background sound in and out over this many milliseconds, 0 to disable
+
+	AutoMuteInMeetings bool `json:"auto_mute_in_meetings"` // Silence the session's sounds while the microphone, camera or a conferencing app is in use
+
+	PreEndWarningMinutes      int  `json:"pre_end_warning_minutes"`      // Warn this many minutes before a Pomodoro ends, 0 to disable
+	PreEndWarningNotification bool `json:"pre_end_warning_notification"` // Show a notification as the pre-end warning
+	PreEndWarningChime        bool `json:"pre_end_warning_chime"`        // Play a chime as the pre-end warning
+
+	FinalCountdown          string  `json:"final_countdown"`           // "beeps", "chime" for a single chime at the 1-minute mark, or "off"
+	FinalCountdownSeconds   int     `json:"final_countdown_seconds"`   // Beep every second for this many seconds before a session ends
+	FinalCountdownFrequency float64 `json:"final_countdown_frequency"` // Frequency of the beeps in Hz
+
+	BinauralCarrierFrequency float64 `json:"binaural_carrier_frequency"` // Tone of the binaural beat background sound in Hz
+	BinauralBeatFrequency    float64 `json:"binaural_beat_frequency"`    // Difference between the ears of the binaural beat in Hz
+
+	IntervalChimeMinutes int `json:"interval_chime_minutes"` // Chime softly every this many minutes of a Pomodoro, 0 to disable
+
+	BreakReminderMinutes int `json:"break_reminder_minutes"` // Remind every this many minutes that a finished break is over, 0 to disable
+
+	EyeRest        bool `json:"eye_rest"`         // Remind to look away from the screen during Pomodoros (20-20-20 rule)
+	EyeRestMinutes int  `json:"eye_rest_minutes"` // Remind after this many minutes of Pomodoros
+	EyeRestSeconds int  `json:"eye_rest_seconds"` // Look away for this many seconds
+
+	IconSecondsMinutes int `json:"icon_seconds_minutes"` // Show the icon as m:ss when less than this many minutes remain, 0 to disable
+
+	ConfirmStop bool `json:"confirm_stop"` // A click on the tray icon only stops a running Pomodoro when confirmed
+
+	LeftClickAction   string `json:"left_click_action"`   // What a click on the tray icon does: "start_stop", "pause", "menu", "skip", "dashboard" or "none"
+	DoubleClickAction string `json:"double_click_action"` // What a double click does; other than "none", a click waits briefly for the second click
+	MiddleClickAction string `json:"middle_click_action"` // What a middle click does (Windows)
+
+	ScrollAdjustsTime bool `json:"scroll_adjusts_time"` // Scrolling over the tray icon adds or takes away a minute (Windows)
+
+	TooltipUpdateSeconds int `json:"tooltip_update_seconds"` // Update the tooltip of the running session every this many seconds
+
+	TooltipFormat string `json:"tooltip_format"` // Template of the tooltip while a session runs, e.g. "{{.Phase}} {{.Remaining}}", empty for the built-in one
+
+	TaskbarProgress bool `json:"taskbar_progress"` // Show the session progress on a taskbar button (Windows)
+
+	CheckForUpdates bool `json:"check_for_updates"` // Look for a new release on GitHub once a week
+
+	Language string `json:"language"` // Language of the menus and notifications: "en", "de", "es", "fr", "hu", or "auto" for the system's
+
+	Achievements bool `json:"achievements"` // Announce milestones such as a 5-day streak and list them in the Achievements submenu
+
+	IconStyle      string `json:"icon_style"`      // "digits", or "ring" or "pie" for a depleting progress indicator
+	IconTheme      string `json:"icon_theme"`      // "classic", "tomato", "dark", "light", "high_contrast", or "auto" to contrast with the taskbar
+	IconBackground string `json:"icon_background"` // Hex color overriding the theme's icon background, e.g. "#8B0000"
+
+	IconTextColor string `json:"icon_text_color"` // Hex color overriding the theme's text color
+	IconDotColor  string `json:"icon_dot_color"`  // Hex color overriding the theme's Pomodoro dot color
+
+	IconPhaseColors     bool   `json:"icon_phase_colors"`     // Use different icon backgrounds for Pomodoros, breaks and while stopped
+	IconBreakBackground string `json:"icon_break_background"` // Hex color overriding the theme's break background
+	IconIdleBackground  string `json:"icon_idle_background"`  // Hex color overriding the theme's background while stopped
+
+	Tags       []string `json:"tags"`        // Tags offered in the Tag submenu, without the leading '#'
+	CurrentTag string   `json:"current_tag"` // Tag applied to new sessions
+
+	Hotkeys HotkeySettings `json:"hotkeys"` // System-wide keyboard shortcuts
+	API     APISettings    `json:"api"`     // Local REST API
+	MQTT    MQTTSettings   `json:"mqtt"`    // MQTT publishing for home automation
+
+	Schedule []ScheduleOverride `json:"schedule"` // Durations and background sound for some days of the week
+
+	QuickTimers []QuickTimer `json:"quick_timers"` // Named countdowns offered in the Quick Timers submenu
+
+	MenuLayout []string `json:"menu_layout"` // Entries of the tray menu in order, e.g. "start_pomodoro", "separator" or "pomodoro:50"; empty for the default menu
+
+	Profiles map[string]map[string]json.RawMessage `json:"profiles"` // Named profiles with their own durations, sounds and icon settings, by JSON key
+	Profile  string                                `json:"profile"`  // Active profile, empty if none
+
+	Webhooks []Webhook `json:"webhooks"` // URLs POSTed on timer events
+
+	OnPomodoroStart string `json:"on_pomodoro_start"` // Shell command run when a Pomodoro starts
+	OnPomodoroEnd   string `json:"on_pomodoro_end"`   // Shell command run when a Pomodoro finishes
+	OnBreakStart    string `json:"on_break_start"`    // Shell command run when a break starts
+	OnBreakEnd      string `json:"on_break_end"`      // Shell command run when a break finishes
+	OnStop          string `json:"on_stop"`           // Shell command run when a session is stopped early
+
+	Jira     JiraSettings     `json:"jira"`     // Jira worklog integration
+	Slack    SlackSettings    `json:"slack"`    // Slack status and Do Not Disturb integration
+	Telegram TelegramSettings `json:"telegram"` // Telegram bot notifications and remote control
+	Push     PushSettings     `json:"push"`     // Phone notifications through ntfy or Pushover when a session finishes
+	Teams    TeamsSettings    `json:"teams"`    // Microsoft Teams presence integration
+	Hue      HueSettings      `json:"hue"`      // Philips Hue lights colored by the session
+	Toggl    TogglSettings    `json:"toggl"`    // Toggl Track time entries for Pomodoros
+	Clockify ClockifySettings `json:"clockify"` // Clockify time entries for completed Pomodoros
+	GitHub   GitHubSettings   `json:"github"`   // Comments on the GitHub issues worked on
+
+	DailyNotes DailyNotesSettings `json:"daily_notes"` // Focus journal in Markdown daily notes, e.g. Obsidian
+	OrgClock   OrgClockSettings   `json:"org_clock"`   // CLOCK entries in an Org file for Emacs
+
+	Influx InfluxSettings `json:"influx"` // Completed sessions written to InfluxDB or a line protocol file
+
+	GoogleCalendar GoogleCalendarSettings `json:"google_calendar"` // Google Calendar Pomodoro log and busy blocks
+	Calendar       CalendarSettings       `json:"calendar"`        // Warnings about meetings a Pomodoro would run into
 }
 
-// initResources initializes the base image and font for the system tray icon.
+// initResources parses the font for the system tray icon.
 func initResources() {
-	baseImage = image.NewRGBA(image.Rect(0, 0, 64, 64))
-	darkRed := color.RGBA{139, 0, 0, 255} // Dark red background color
-	for y := 0; y < 64; y++ {
-		for x := 0; x < 64; x++ {
-			baseImage.Set(x, y, darkRed)
-		}
-	}
-
 	// Load and parse the embedded font
 	fnt, err := opentype.Parse(numbersTtf)
 	if err != nil {
-		fmt.Println("Error parsing font:", err)
-		return
-	}
-	fontFace, err = opentype.NewFace(fnt, &opentype.FaceOptions{
-		Size:    46,
-		DPI:     72,
-		Hinting: font.HintingFull,
-	})
-	if err != nil {
-		fmt.Println("Error creating font face:", err)
+		slog.Error("Error parsing font", "err", err)
 		return
 	}
+	iconRenderer = icon.New(fnt)
 }
 
-// initAudio initializes the audio context.
-func initAudio() error {
-	op := &oto.NewContextOptions{
-		SampleRate:   mp3Decoder.SampleRate(),
-		ChannelCount: 2,
-		Format:       oto.FormatSignedInt16LE,
-	}
+// getSettingsPath returns the path to the settings file, which may be JSON,
+// YAML or TOML.
+func getSettingsPath() string {
+	return findSettingsFile()
+}
 
-	var err error
-	var ready chan struct{}
-	context, ready, err = oto.NewContext(op)
-	if err != nil {
-		return fmt.Errorf("failed to create audio context: %v", err)
-	}
+// defaultSettings returns the settings used for fields missing from the settings file.
+func defaultSettings() TimerSettings {
+	return TimerSettings{
+		PomodoroDuration:    25,
+		ShortBreakDuration:  5,
+		LongBreakDuration:   15,
+		EnableClockSound:    true,
+		BackgroundSound:     backgroundClock,
+		EnableNotifications: true,
 
-	// Wait for the context to be ready
-	<-ready
-	return nil
-}
+		MasterVolume: 100,
+		ClockVolume:  100,
+		AlarmVolume:  100,
 
-// playTickSound plays a short beep sound.
-func playTickSound() {
-	if context == nil {
-		fmt.Println("Audio context not initialized")
-		return
-	}
+		ClockFadeMilliseconds: 1000,
 
-	freq := 440.0 // Frequency of the sound in Hz (A4 note)
-	duration := 200 * time.Millisecond
-	amplitude := 0.3 // Amplitude of the sound
+		AutoMuteInMeetings: true,
 
-	// Create a sine wave for the sound
-	sineWave := NewSineWave(freq, duration, 1, oto.FormatSignedInt16LE, amplitude)
+		PreEndWarningMinutes:      0,
+		PreEndWarningNotification: true,
+		PreEndWarningChime:        true,
 
-	// Create a new player for the sound
-	player := context.NewPlayer(sineWave)
-	player.Play()
+		FinalCountdown:          countdownBeeps,
+		FinalCountdownSeconds:   10,
+		FinalCountdownFrequency: 440,
 
-	// Wait for the sound to finish playing
-	time.Sleep(duration)
-	player.Close() // Close the player after the sound is done
-}
+		BinauralCarrierFrequency: 200,
+		BinauralBeatFrequency:    10,
 
-// NewSineWave creates a sine wave for the given frequency, duration, and format.
-func NewSineWave(freq float64, duration time.Duration, channelCount int, format oto.Format, amplitude float64) *SineWave {
-	sampleRate := 44100 // Sample rate
-	length := int64(float64(sampleRate) * float64(duration) / float64(time.Second))
-	return &SineWave{
-		freq:         freq,
-		length:       length,
-		channelCount: channelCount,
-		format:       format,
-		amplitude:    amplitude,
-		sampleRate:   sampleRate,
-	}
-}
+		IntervalChimeMinutes: 0,
 
-// SineWave implements io.Reader to generate a sine wave.
-type SineWave struct {
-	freq         float64
-	length       int64
-	pos          int64
-	channelCount int
-	format       oto.Format
-	amplitude    float64
-	sampleRate   int
-}
+		BreakReminderMinutes: 0,
 
-// Read generates the sine wave data.
-func (s *SineWave) Read(buf []byte) (int, error) {
-	if s.pos >= s.length {
-		return 0, io.EOF
-	}
+		Achievements: true,
 
-	eof := false
-	if s.pos+int64(len(buf)) > s.length {
-		buf = buf[:s.length-s.pos]
-		eof = true
-	}
+		Language: languageAuto,
 
-	length := float64(s.sampleRate) / s.freq
-	num := formatByteLength(s.format) * s.channelCount
-	p := s.pos / int64(num)
+		LeftClickAction:   clickStartStop,
+		DoubleClickAction: clickNone,
+		MiddleClickAction: clickNone,
+		ScrollAdjustsTime: true,
 
-	switch s.format {
-	case oto.FormatSignedInt16LE:
-		for i := 0; i < len(buf)/num; i++ {
-			const max = 32767
-			b := int16(math.Sin(2*math.Pi*float64(p)/length) * s.amplitude * max)
-			for ch := 0; ch < s.channelCount; ch++ {
-				buf[num*i+2*ch] = byte(b)
-				buf[num*i+1+2*ch] = byte(b >> 8)
-			}
-			p++
-		}
-	}
+		TooltipUpdateSeconds: 1,
 
-	s.pos += int64(len(buf))
+		EyeRest:        false,
+		EyeRestMinutes: 20,
+		EyeRestSeconds: 20,
 
-	if eof {
-		return len(buf), io.EOF
-	}
-	return len(buf), nil
-}
+		QuickTimers: []QuickTimer{
+			{Name: "Tea", Duration: "3:00"},
+			{Name: "Laundry", Duration: "45:00"},
+		},
 
-// formatByteLength returns the byte length of the given format.
-func formatByteLength(format oto.Format) int {
-	switch format {
-	case oto.FormatFloat32LE:
-		return 4
-	case oto.FormatUnsignedInt8:
-		return 1
-	case oto.FormatSignedInt16LE:
-		return 2
-	default:
-		panic(fmt.Sprintf("unexpected format: %d", format))
+		FocusAssist: focusAssistOff,
+		PauseMedia:  pauseMediaOff,
+
+		Distractions: DistractionSettings{
+			Action: distractionWarn,
+		},
+
+		Calendar: CalendarSettings{
+			MinMinutes: 10,
+		},
+
+		IconStyle: string(icon.Digits),
+		IconTheme: "classic",
+
+		IconPhaseColors: true,
+
+		API: APISettings{
+			Port: 7625,
+		},
+
+		Hue: HueSettings{
+			Brightness:    100,
+			PomodoroColor: "#FF0000",
+			BreakColor:    "#00FF00",
+		},
+
+		Slack: SlackSettings{
+			StatusEmoji: ":tomato:",
+			StatusText:  "Focusing until %s",
+			EnableDND:   true,
+		},
+
+		Toggl: TogglSettings{
+			Description: "Pomodoro",
+		},
+
+		Clockify: ClockifySettings{
+			Description: "Pomodoro",
+		},
+
+		DailyNotes: DailyNotesSettings{
+			FileName: "YYYY-MM-DD",
+		},
+
+		OrgClock: OrgClockSettings{
+			Heading: "Pomodoros",
+		},
+
+		Push: PushSettings{
+			NtfyServer: "https://ntfy.sh",
+		},
+
+		Influx: InfluxSettings{
+			Measurement: "pomodoro",
+		},
 	}
+
 }
 
-// getSettingsPath returns the path to the settings file.
-func getSettingsPath() string {
-	homeDir, err := os.UserHomeDir()
+// readSettingsFile reads and validates the settings file, see parseSettings.
+func readSettingsFile() (TimerSettings, []string, error) {
+	path := getSettingsPath()
+	data, err := ioutil.ReadFile(path)
+	if err == nil {
+		data, err = settingsToJSON(path, data)
+	}
 	if err != nil {
-		homeDir = "."
+		return defaultSettings(), nil, err
 	}
-	return filepath.Join(homeDir, ".pomodoro_settings.json")
+	return parseSettings(data)
 }
 
 // loadSettings loads the timer settings from a file or uses defaults.
+// Problems in the file are reported and replaced with defaults.
 func loadSettings() {
-	settings = TimerSettings{
-		PomodoroDuration:   25,
-		ShortBreakDuration: 5,
-		LongBreakDuration:  15,
-		EnableClockSound:   true,
-	}
-
-	filePath := getSettingsPath()
-	data, err := ioutil.ReadFile(filePath)
-	if err == nil {
-		err = json.Unmarshal(data, &settings)
-		if err != nil {
-			fmt.Println("Failed to load settings:", err)
+	newSettings, problems, err := readSettingsFile()
+	if err != nil && !os.IsNotExist(err) {
+		problems = []string{err.Error() + "; using the default settings"}
+	}
+	save := false
+	if launch.profile != "" {
+		if withLaunchProfile, err := withProfile(newSettings, launch.profile); err != nil {
+			problems = append(problems, err.Error()+"; using the settings file")
+		} else {
+			problems = append(problems, validateSettings(&withLaunchProfile)...)
+			save = withLaunchProfile.Profile != newSettings.Profile
+			newSettings = withLaunchProfile
 		}
 	}
+	applyLaunchFlags(&newSettings)
+	settings = newSettings
+	reportSettingsProblems(problems)
+	if save {
+		saveSettings()
+	}
 }
 
-// saveSettings saves the current timer settings to a file.
+// saveSettings saves the current timer settings to a file, keeping the file's
+// values for settings overridden by command-line flags.
 func saveSettings() {
 	filePath := getSettingsPath()
-	data, err := json.MarshalIndent(settings, "", "  ")
+	old, _ := ioutil.ReadFile(filePath) // Its comments are kept
+	data, err := encodeSettings(filePath, settingsToSave(), old)
 	if err != nil {
-		fmt.Println("Failed to save settings:", err)
+		slog.Error("Failed to save settings", "err", err)
 		return
 	}
 	err = ioutil.WriteFile(filePath, data, 0644)
 	if err != nil {
-		fmt.Println("Failed to write settings file:", err)
+		slog.Error("Failed to write settings file", "err", err)
 	}
 }
 
-// openSettingsEditor opens the settings file in the default text editor.
-func openSettingsEditor() {
-	tempFile, err := ioutil.TempFile("", "pomodoro_settings_*.json")
+// editInEditor writes data to a temp file, opens it in the default text editor
+// and returns the file contents once the editor is closed.
+func editInEditor(pattern string, data []byte) ([]byte, error) {
+	tempFile, err := ioutil.TempFile("", pattern)
 	if err != nil {
-		fmt.Println("Error creating temp file:", err)
-		return
+		return nil, fmt.Errorf("failed to create temp file: %v", err)
 	}
 	defer os.Remove(tempFile.Name())
 
-	data, _ := json.MarshalIndent(settings, "", "  ")
 	tempFile.Write(data)
 	tempFile.Close()
 
@@ -320,89 +413,135 @@ func openSettingsEditor() {
 
 	err = cmd.Start()
 	if err != nil {
-		fmt.Println("Failed to open editor:", err)
-		return
+		return nil, fmt.Errorf("failed to open editor: %v", err)
 	}
 
 	cmd.Wait()
 
-	updatedData, err := ioutil.ReadFile(tempFile.Name())
+	return ioutil.ReadFile(tempFile.Name())
+}
+
+// openSettingsEditor opens the settings file in the default text editor.
+func openSettingsEditor() {
+	data, _ := json.MarshalIndent(currentSettings(), "", "  ")
+	updatedData, err := editInEditor("pomodoro_settings_*.json", data)
 	if err != nil {
-		fmt.Println("Failed to read updated settings:", err)
+		slog.Error("Failed to edit settings", "err", err)
 		return
 	}
 
-	var newSettings TimerSettings
-	err = json.Unmarshal(updatedData, &newSettings)
+	newSettings, problems, err := parseSettings(updatedData)
 	if err != nil {
-		fmt.Println("Invalid JSON format:", err)
+		slog.Error("Invalid settings", "err", err)
+		sendNotification(tr("Settings not saved"), err.Error())
 		return
 	}
 
+	applySettings(newSettings)
+	reportSettingsProblems(problems)
+}
+
+// currentSettings returns a copy of the settings, e.g. to change and pass to
+// applySettings. The caller must not hold mu.
+func currentSettings() TimerSettings {
+	mu.Lock()
+	defer mu.Unlock()
+	return settings
+}
+
+// applySettings replaces the settings, saves them and applies them to the
+// running app. New durations take effect with the next session. The caller
+// must not hold mu.
+func applySettings(newSettings TimerSettings) {
+	mu.Lock()
 	settings = newSettings
 	saveSettings()
+	mu.Unlock()
+	refreshSettings()
+}
+
+// refreshSettings applies the current settings to the menus, sounds and icon.
+func refreshSettings() {
+	initLanguage()
+	loadCustomSounds()
+	updateVolumeMenu()
+	updateBackgroundSoundMenu()
+	updateTagMenu()
+	updateProfileMenu()
+	updateHotkeys()
+	updateAPIServer()
+	updateMQTT()
+	updateJiraMenu()
+	updateHueMenu()
+	updateAchievementsMenu()
+	updateScrollWatch()
+	if mNotifications != nil {
+		if settings.EnableNotifications {
+			mNotifications.Check()
+		} else {
+			mNotifications.Uncheck()
+		}
+	}
+	dark := systemUsesDarkTheme()
+	mu.Lock()
+	systemDark = dark
+	applyIconTheme()
+	redrawIcon()
+	updateTaskbarProgress()
+	updateQuickTimerMenu()
+	mu.Unlock()
+	updateThemeMenu()
 }
 
 // onReady sets up the system tray interface.
 func onReady() {
 	systray.SetTitle("Pomodoro Timer")
-	systray.SetTooltip("Click to start Pomodoro")
-	systray.SetIconFromMemory(generateIconWithDots("▶", pomodoroCount))
+	setTooltip(tr("Click to start Pomodoro"))
+	if settings.IconTheme == iconThemeAuto {
+		systemDark = systemUsesDarkTheme()
+	}
+	applyIconTheme()
+	setTrayIcon("▶", engine.Count())
+	startServices()
 
 	// Handle direct tray icon clicks
-	systray.SetOnClick(func(menu systray.IMenu) {
-		handleTrayClick()
-	})
-
-	mWeb := systray.AddMenuItem("Pomodoro Timer v1.4.0", "Open the website in browser")
-	mWeb.Click(func() {
-		openBrowser("https://github.com/lutischan-ferenc/pomodoro-timer")
-	})
-	systray.AddSeparator()
-	mPomodoro = systray.AddMenuItem("Start Pomodoro", "Start a new Pomodoro session")
-	mPomodoro.Click(func() {
-		mu.Lock()
-		isInPomodoro = true
-		mu.Unlock()
-		handleTimerClick(time.Duration(settings.PomodoroDuration) * time.Minute)
-	})
-	mBreak = systray.AddMenuItem("Start Break", "Take a break")
-	mBreak.Click(func() {
-		mu.Lock()
-		isInPomodoro = false
-		mu.Unlock()
-		handleTimerClick(time.Duration(settings.ShortBreakDuration) * time.Minute)
-	})
-	mLongBreak = systray.AddMenuItem("Start Long Break", "Take a long break")
-	mLongBreak.Click(func() {
-		mu.Lock()
-		isInPomodoro = false
-		mu.Unlock()
-		handleTimerClick(time.Duration(settings.LongBreakDuration) * time.Minute)
-	})
-
-	addAutoStartMenuOnWin()
-	mClockSound := systray.AddMenuItemCheckbox("Clock sound", "Play ticking sound during Pomodoro", settings.EnableClockSound)
-	mClockSound.Click(func() {
-		settings.EnableClockSound = !settings.EnableClockSound
-		if settings.EnableClockSound {
-			mClockSound.Check()
-		} else {
-			mClockSound.Uncheck()
-		}
-		saveSettings()
-	})
+	addTrayClickHandlers()
+	updateScrollWatch()
+
+	buildMenu()
+	go watchUpdates()
 
-	systray.AddSeparator()
-	mSettings := systray.AddMenuItem("Settings", "Configure timers")
-	mSettings.Click(func() {
-		openSettingsEditor()
-	})
-	systray.AddSeparator()
-	mQuit := systray.AddMenuItem("Exit", "Exit the application")
-	mQuit.Click(func() {
-		systray.Quit()
-	})
+	startFirstSession()
+}
+
+// startServices starts the background work shared by the tray and headless
+// runs: the settings watcher, the remote controls and the integrations.
+func startServices() {
+	go watchSettingsFile()
+	go serveControl()
+	go startDBusService()
+	if portableDir == "" && !launch.headless {
+		go registerURLScheme()
+	}
+	go watchCalendar()
+	go retryClockify()
+	go runHueLights()
+	go initAchievements()
+	go handleSignals()
+	updateHotkeys()
+	updateAPIServer()
+	updateMQTT()
+}
+
+// startFirstSession starts a Pomodoro if asked to on the command line, or
+// restores the timer saved on exit, then opens the link the app was started with.
+func startFirstSession() {
+	if launch.start {
+		startPomodoro()
+	} else {
+		restoreTimerState()
+	}
+	handleLaunchURL()
 }
 
 // handleTrayClick handles clicks on the system tray icon
@@ -410,210 +549,229 @@ func handleTrayClick() {
 	mu.Lock()
 	defer mu.Unlock()
 
-	if isRunning {
-		// Stop the running timer
-		close(stopCh)
-		stopCh = make(chan struct{})
-		isRunning = false
-		systray.SetIconFromMemory(generateIconWithDots("▶", pomodoroCount))
-		if isInPomodoro {
-			systray.SetTooltip("Pomodoro stopped - Click to start Break")
-		} else {
-			systray.SetTooltip("Break stopped - Click to start Pomodoro")
-		}
+	if engine.Running() {
+		stopTimer()
 	} else {
 		// Start the next appropriate timer
-		if isInPomodoro {
-			var breakDuration time.Duration
-			if pomodoroCount == 4 {
-				breakDuration = time.Duration(settings.LongBreakDuration) * time.Minute
-			} else {
-				breakDuration = time.Duration(settings.ShortBreakDuration) * time.Minute
-			}
-			isInPomodoro = false // Set before starting break
-			startTimer(breakDuration)
+		if engine.Next() == pomodoro.Break {
+			startTimer(pomodoro.Break, nextBreakDuration())
 		} else {
-			isInPomodoro = true // Set before starting Pomodoro
-			startTimer(time.Duration(settings.PomodoroDuration) * time.Minute)
+			startTimer(pomodoro.Pomodoro, pomodoroDuration())
 		}
 	}
 }
 
-// handleTimerClick starts a timer with the specified duration (used by menu items)
-func handleTimerClick(duration time.Duration) {
-	mu.Lock()
-	defer mu.Unlock()
-
-	if isRunning {
-		close(stopCh)
-		stopCh = make(chan struct{})
-		isRunning = false
+// preEndWarning warns that the running Pomodoro ends in the given number of minutes.
+func preEndWarning(minutes int) {
+	if settings.PreEndWarningChime {
+		go playWarningChime()
+	}
+	if settings.PreEndWarningNotification {
+		title := fmt.Sprintf(tr("%d minutes remaining"), minutes)
+		if minutes == 1 {
+			title = tr("1 minute remaining")
+		}
+		go sendNotification(title, tr("Time to wrap up your current thought"))
 	}
-	startTimer(duration)
 }
 
-// startTimer starts the countdown timer.
-func startTimer(duration time.Duration) {
-	isRunning = true
-	remainingTime = duration
-	if isInPomodoro && settings.EnableClockSound {
-		playClockSound()
-	}
-	go func() {
-		defer stopClockSound()
-		ticker = time.NewTicker(time.Second)
-		defer ticker.Stop()
-
-		for remainingTime > 0 {
-			select {
-			case <-ticker.C:
-				mu.Lock()
-				remainingTime -= time.Second
-				if remainingTime <= 0 {
-					isRunning = false
-					if isInPomodoro {
-						pomodoroCount++
-						if pomodoroCount > 4 {
-							pomodoroCount = 1
-						}
-						systray.SetTooltip("Finished pomodoro - Click to start break")
-					} else {
-						systray.SetTooltip("Finished break - Click to start pomodoro")
-					}
-					systray.SetIconFromMemory(generateIconWithDots("▶", pomodoroCount))
-					playTickSound()
-					mu.Unlock()
-					return
-				}
-				if remainingTime < 11*time.Second {
-					playTickSound()
-				}
-				var displayText string
-				if remainingTime < time.Minute {
-					displayText = fmt.Sprintf("%d", int(remainingTime.Seconds()))
-				} else {
-					displayText = fmt.Sprintf("%d", int(remainingTime.Minutes()))
-				}
-				if displayText != oldDisplayText {
-					systray.SetIconFromMemory(generateIconWithDots(displayText, pomodoroCount))
-					oldDisplayText = displayText
-				}
-				systray.SetTooltip(fmt.Sprintf("%02d:%02d", int(remainingTime.Minutes()), int(remainingTime.Seconds())%60))
-				mu.Unlock()
-			case <-stopCh:
-				ticker.Stop()
-				return
-			}
+// Final countdown behaviors.
+const (
+	countdownBeeps = "beeps"
+	countdownChime = "chime"
+	countdownOff   = "off"
+)
+
+// finalCountdown plays the configured countdown sound for the remaining time of a session.
+func finalCountdown(s sessionInfo) {
+	switch settings.FinalCountdown {
+	case countdownOff:
+	case countdownChime:
+		if s.crossed(time.Minute) {
+			go playWarningChime()
 		}
-	}()
+	default:
+		if s.timer.Remaining <= time.Duration(settings.FinalCountdownSeconds)*time.Second {
+			playTickSound()
+		}
+	}
 }
 
-type loopReader struct {
-	r io.ReadSeeker
+// startPomodoro starts a new Pomodoro session, stopping any running timer.
+func startPomodoro() {
+	handleTimerClick(pomodoro.Pomodoro, pomodoroDuration())
 }
 
-func (lr *loopReader) Read(p []byte) (int, error) {
-	n, err := lr.r.Read(p)
-	if err == io.EOF {
-		_, seekErr := lr.r.Seek(0, io.SeekStart)
-		if seekErr != nil {
-			return n, seekErr
-		}
-		return n, nil
-	}
-	return n, err
+// startBreak starts the break following the last Pomodoro, stopping any running timer.
+func startBreak() {
+	mu.Lock()
+	duration := nextBreakDuration()
+	mu.Unlock()
+	handleTimerClick(pomodoro.Break, duration)
 }
 
-func playClockSound() {
-	clockMutex.Lock()
-	defer clockMutex.Unlock()
+// stopTimer stops the running timer. The caller must hold mu.
+func stopTimer() {
+	engine.Stop()
+	endSession(false)
+}
 
-	if !settings.EnableClockSound {
-		return
+// timerStatusText describes the state of the timer in a single line.
+// The caller must hold mu.
+func timerStatusText() string {
+	if !engine.Running() {
+		return fmt.Sprintf("Stopped, %d/4 pomodoros in this cycle", engine.Count())
 	}
-	if context == nil {
-		return
+	phase := "Break"
+	if engine.InPomodoro() {
+		phase = "Pomodoro"
 	}
-	if clockPlayer != nil {
-		return // vagy megfelelően kezelje
+	state := "running"
+	if engine.Paused() {
+		state = "paused"
 	}
+	text := fmt.Sprintf("%s %s, %02d:%02d left", phase, state, int(engine.Remaining().Minutes()), int(engine.Remaining().Seconds())%60)
+	if currentSession != nil && currentSession.Task != "" {
+		text += " - " + currentSession.Task
+	}
+	return text
+}
 
-	clockStopCh = make(chan struct{})
-
-	lr := &loopReader{r: bytes.NewReader(clockSoundPCM)}
-	clockPlayer = context.NewPlayer(lr)
-	clockPlayer.Play()
-
-	go func() {
-		<-clockStopCh
-		clockPlayer.Close()
-		clockMutex.Lock()
-		clockPlayer = nil
-		clockMutex.Unlock()
-	}()
+// nextBreakDuration returns the duration of the break following the last Pomodoro:
+// a long break after every fourth Pomodoro, a short one otherwise.
+func nextBreakDuration() time.Duration {
+	if engine.LongBreakNext() {
+		return longBreakDuration()
+	}
+	return shortBreakDuration()
 }
 
-func stopClockSound() {
-	clockMutex.Lock()
-	defer clockMutex.Unlock()
+// handleTimerClick starts a session of the given kind and duration,
+// stopping the running one (used by menu items).
+func handleTimerClick(kind pomodoro.Kind, duration time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
 
-	if clockPlayer != nil {
-		close(clockStopCh)
+	if engine.Stop() {
+		endSession(false)
 	}
+	startTimer(kind, duration)
 }
 
-// generateIconWithDots generates an icon with the remaining time and Pomodoro count dots.
-func generateIconWithDots(text string, dotCount int) []byte {
-	img := image.NewRGBA(baseImage.Bounds())
-	copy(img.Pix, baseImage.Pix)
+// startTimer starts a session of the given kind and duration. The caller must hold mu.
+func startTimer(kind pomodoro.Kind, duration time.Duration) {
+	duration = scrolledDuration(kind, duration)
+	if kind == pomodoro.Pomodoro {
+		duration = fitToCalendar(duration)
+	}
+	previous := duration
+	engine.Tick = func(s pomodoro.Session) {
+		publish(sessionTick, sessionInfo{timer: s, record: currentSession, previous: previous})
+		previous = s.Remaining
+	}
+	engine.PausedTick = func(s pomodoro.Session) {
+		if currentSession != nil {
+			currentSession.PausedSeconds = int(s.Paused.Seconds())
+		}
+	}
+	engine.Finished = func(pomodoro.Session) {
+		endSession(true)
+	}
+	engine.Start(kind, duration)
+	beginSession()
+}
 
-	bounds, _ := font.BoundString(fontFace, text)
-	textWidth := (bounds.Max.X - bounds.Min.X).Ceil()
-	textHeight := (bounds.Max.Y - bounds.Min.Y).Ceil()
+// traySessionStarted shows the length of the new session on the icon.
+func traySessionStarted(s sessionInfo) {
+	oldDisplayText = iconText(s.timer.Duration)
+	setTrayIcon(oldDisplayText, engine.Count())
+	prerenderSessionIcons(s.timer.Duration)
+	updateTaskbarProgress()
+	updatePauseMenu()
+	updateInterruptionMenu()
+}
 
-	x := (64 - textWidth) / 2
-	y := (64+textHeight)/2 - 5
+// trayTick updates the icon every second of the running session, and the
+// tooltip every tooltip_update_seconds.
+func trayTick(s sessionInfo) {
+	updateTaskbarProgress()
+	displayText := iconText(s.timer.Remaining)
+	if displayText != oldDisplayText || progressIconStyle() {
+		setTrayIcon(displayText, engine.Count())
+		oldDisplayText = displayText
+	}
+	if int(s.timer.Remaining/time.Second)%settings.TooltipUpdateSeconds == 0 {
+		setTooltip(runningTooltip(s))
+	}
+}
 
-	col := color.White // White text color
-	point := fixed.Point26_6{X: fixed.I(x), Y: fixed.I(y)}
+// traySessionEnded resets the icon and tells in the tooltip what a click starts next.
+func traySessionEnded(s sessionInfo) {
+	setTrayIcon("▶", engine.Count())
+	updateTaskbarProgress()
+	updatePauseMenu()
+	updateInterruptionMenu()
+	switch {
+	case s.record.Completed && s.pomodoro():
+		setTooltip(tr("Finished pomodoro - Click to start break") + taskProgressText(s.record.Task) + planProgressText())
+	case s.record.Completed:
+		setTooltip(tr("Finished break - Click to start pomodoro"))
+	case s.pomodoro():
+		setTooltip(tr("Pomodoro stopped - Click to start Break"))
+	default:
+		setTooltip(tr("Break stopped - Click to start Pomodoro"))
+	}
+}
 
-	d := &font.Drawer{
-		Dst:  img,
-		Src:  image.NewUniform(col),
-		Face: fontFace,
-		Dot:  point,
+// preEndWarningTick warns when the running Pomodoro reaches the configured
+// minutes before its end.
+func preEndWarningTick(s sessionInfo) {
+	warning := time.Duration(settings.PreEndWarningMinutes) * time.Minute
+	if warning > 0 && warning < s.timer.Duration && s.crossed(warning) {
+		preEndWarning(settings.PreEndWarningMinutes)
 	}
-	d.DrawString(text)
+}
 
-	dotColor := color.RGBA{144, 238, 144, 255} // Light green dots
-	dotRadius := 6
-	dotSpacing := 5
-	startX := 5
+// intervalChimeTick chimes every interval_chime_minutes of the running
+// Pomodoro, except at its end, where the alarm sounds.
+func intervalChimeTick(s sessionInfo) {
+	interval := time.Duration(settings.IntervalChimeMinutes) * time.Minute
+	if interval <= 0 || s.timer.Remaining <= 0 {
+		return
+	}
+	// Chime when the elapsed time passed a multiple of the interval since the previous tick
+	elapsed := s.timer.Duration - s.timer.Remaining
+	if elapsed/interval > (s.timer.Duration-s.previous)/interval {
+		go playIntervalChime()
+	}
+}
 
-	for i := 0; i < dotCount; i++ {
-		dotX := startX + i*(dotRadius*2+dotSpacing)
-		dotY := 56
-		drawCircle(img, dotX, dotY, dotRadius, dotColor)
+// clockFadeDuration returns the length of the fade-in and fade-out of the background sound.
+func clockFadeDuration() time.Duration {
+	if settings.ClockFadeMilliseconds <= 0 {
+		return 0
 	}
+	return time.Duration(settings.ClockFadeMilliseconds) * time.Millisecond
+}
 
-	var pngBuf bytes.Buffer
-	err := png.Encode(&pngBuf, img)
-	if err != nil {
-		return []byte{0x00}
+// clockSoundSessionStarted plays the background sound during Pomodoros, if enabled.
+func clockSoundSessionStarted(s sessionInfo) {
+	if s.pomodoro() && scheduledSettings(s.timer.Start).EnableClockSound {
+		playClockSound()
 	}
+}
 
-	return pngBuf.Bytes()
+// playClockSound starts the background sound, unless it is already playing.
+func playClockSound() {
+	soundsMu.Lock()
+	sound := backgroundSource()
+	soundsMu.Unlock()
+	audioPlayer.Loop(sound, clockFadeDuration())
 }
 
-// drawCircle draws a circle on the image.
-func drawCircle(img *image.RGBA, x, y, radius int, col color.RGBA) {
-	for i := -radius; i <= radius; i++ {
-		for j := -radius; j <= radius; j++ {
-			if i*i+j*j <= radius*radius {
-				img.Set(x+i, y+j, col)
-			}
-		}
-	}
+// stopClockSound fades out the background sound.
+func stopClockSound() {
+	audioPlayer.Stop()
 }
 
 // openBrowser opens the specified URL in the default browser.
@@ -632,18 +790,16 @@ func openBrowser(url string) {
 	}
 
 	if err != nil {
-		fmt.Println("Failed to open browser:", err)
+		slog.Error("Failed to open browser", "err", err)
 	}
 }
 
-const AUTO_START_NAME = "PomodoroTimer"
-
-func addAutoStartMenuOnWin() {
-	// Add auto-start menu item for Windows only
-	if runtime.GOOS == "windows" {
+// addAutoStartMenu adds the auto-start menu item on the platforms supporting it.
+func addAutoStartMenu() {
+	if autoStartSupported {
 		systray.AddSeparator()
-		mAutoStart = systray.AddMenuItemCheckbox("Start on System Startup", "Auto-start on System Startup", false)
-		// Check the current state of auto-start in the registry
+		mAutoStart = systray.AddMenuItemCheckbox(tr("Start on System Startup"), tr("Auto-start on System Startup"), false)
+		// Check the current state of auto-start
 		if isAutoStartEnabled() {
 			mAutoStart.Check()
 		}
@@ -652,14 +808,14 @@ func addAutoStartMenuOnWin() {
 			if mAutoStart.Checked() {
 				// Disable auto-start
 				if err := setAutoStart(false); err != nil {
-					fmt.Println("Failed to disable auto-start:", err)
+					slog.Error("Failed to disable auto-start", "err", err)
 				} else {
 					mAutoStart.Uncheck()
 				}
 			} else {
 				// Enable auto-start
 				if err := setAutoStart(true); err != nil {
-					fmt.Println("Failed to enable auto-start:", err)
+					slog.Error("Failed to enable auto-start", "err", err)
 				} else {
 					mAutoStart.Check()
 				}
@@ -667,70 +823,3 @@ func addAutoStartMenuOnWin() {
 		})
 	}
 }
-
-// setAutoStart sets or removes the application from the Windows startup registry.
-func setAutoStart(enable bool) error {
-	if runtime.GOOS != "windows" {
-		return fmt.Errorf("auto-start is only supported on Windows")
-	}
-
-	// Get the path to the current executable
-	exePath, err := os.Executable()
-	if err != nil {
-		return fmt.Errorf("failed to get executable path: %v", err)
-	}
-
-	// Open the registry key for auto-start programs
-	key, err := registry.OpenKey(registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Run`, registry.SET_VALUE|registry.QUERY_VALUE)
-	if err != nil {
-		return fmt.Errorf("failed to open registry key: %v", err)
-	}
-	defer key.Close()
-
-	// Set or remove the auto-start entry
-	if enable {
-		if err := key.SetStringValue(AUTO_START_NAME, exePath); err != nil {
-			return fmt.Errorf("failed to set registry value: %v", err)
-		}
-	} else {
-		if err := key.DeleteValue(AUTO_START_NAME); err != nil && err != registry.ErrNotExist {
-			return fmt.Errorf("failed to delete registry value: %v", err)
-		}
-	}
-
-	return nil
-}
-
-// isAutoStartEnabled checks if the application is set to auto-start in the Windows registry.
-func isAutoStartEnabled() bool {
-	if runtime.GOOS != "windows" {
-		return false
-	}
-
-	// Get the path to the current executable
-	exePath, err := os.Executable()
-	if err != nil {
-		fmt.Println("Failed to get executable path:", err)
-		return false
-	}
-
-	// Open the registry key for auto-start programs
-	key, err := registry.OpenKey(registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Run`, registry.QUERY_VALUE)
-	if err != nil {
-		fmt.Println("Failed to open registry key:", err)
-		return false
-	}
-	defer key.Close()
-
-	// Check if the registry value exists and matches the current executable path
-	value, _, err := key.GetStringValue(AUTO_START_NAME)
-	if err != nil {
-		if err == registry.ErrNotExist {
-			return false
-		}
-		fmt.Println("Failed to read registry value:", err)
-		return false
-	}
-
-	return value == exePath
-}
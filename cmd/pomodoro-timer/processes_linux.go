@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// runningProcesses lists the running processes. The name is that of the
+// executable if readable, e.g. for the user's own processes, and the
+// process name, truncated to 15 characters by the kernel, otherwise.
+func runningProcesses() ([]process, error) {
+	entries, err := ioutil.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+	var processes []process
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		dir := filepath.Join("/proc", entry.Name())
+		if exe, err := os.Readlink(filepath.Join(dir, "exe")); err == nil {
+			processes = append(processes, process{pid: pid, name: filepath.Base(strings.TrimSuffix(exe, " (deleted)"))})
+		} else if comm, err := ioutil.ReadFile(filepath.Join(dir, "comm")); err == nil {
+			processes = append(processes, process{pid: pid, name: strings.TrimSpace(string(comm))})
+		}
+	}
+	return processes, nil
+}
+
+// minimizeProcessWindows reports that minimizing is only supported on Windows.
+func minimizeProcessWindows(pid int) error {
+	return fmt.Errorf("minimizing windows is only supported on Windows")
+}
@@ -0,0 +1,37 @@
+//go:build !windows && !linux
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// runningProcesses lists the running processes with ps.
+func runningProcesses() ([]process, error) {
+	out, err := exec.Command("ps", "-axo", "pid=,comm=").Output()
+	if err != nil {
+		return nil, err
+	}
+	var processes []process
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		processes = append(processes, process{pid: pid, name: filepath.Base(strings.TrimSpace(fields[1]))})
+	}
+	return processes, nil
+}
+
+// minimizeProcessWindows reports that minimizing is only supported on Windows.
+func minimizeProcessWindows(pid int) error {
+	return fmt.Errorf("minimizing windows is only supported on Windows")
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var procIsWindowVisible = user32.NewProc("IsWindowVisible")
+
+// runningProcesses lists the running processes.
+func runningProcesses() ([]process, error) {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer windows.CloseHandle(snapshot)
+
+	var processes []process
+	entry := windows.ProcessEntry32{Size: uint32(unsafe.Sizeof(windows.ProcessEntry32{}))}
+	for err = windows.Process32First(snapshot, &entry); err == nil; err = windows.Process32Next(snapshot, &entry) {
+		processes = append(processes, process{pid: int(entry.ProcessID), name: windows.UTF16ToString(entry.ExeFile[:])})
+	}
+	if err != windows.ERROR_NO_MORE_FILES {
+		return nil, err
+	}
+	return processes, nil
+}
+
+// minimizeProcessWindows minimizes the visible top-level windows of a process.
+func minimizeProcessWindows(pid int) error {
+	found := false
+	callback := syscall.NewCallback(func(hwnd windows.HWND, _ uintptr) uintptr {
+		var owner uint32
+		windows.GetWindowThreadProcessId(hwnd, &owner)
+		if int(owner) == pid {
+			if visible, _, _ := procIsWindowVisible.Call(uintptr(hwnd)); visible != 0 {
+				procShowWindow.Call(uintptr(hwnd), swShowMinNoActive)
+				found = true
+			}
+		}
+		return 1 // Continue enumerating
+	})
+	if err := windows.EnumWindows(callback, nil); err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("process %d has no windows", pid)
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/lutischan-ferenc/systray"
+)
+
+const maxProfileMenuItems = 10
+
+// profileKeys are the JSON keys of the settings that each profile has its own
+// value for. All other settings are shared by the profiles.
+var profileKeys = []string{
+	"pomodoro_duration", "short_break_duration", "long_break_duration",
+	"enable_clock_sound", "background_sound", "clock_fade_ms",
+	"clock_sound_path", "pomodoro_end_sound_path", "break_end_sound_path",
+	"master_volume", "clock_volume", "alarm_volume", "muted",
+	"final_countdown", "final_countdown_seconds", "final_countdown_frequency",
+	"icon_style", "icon_theme", "icon_background", "icon_text_color", "icon_dot_color",
+	"icon_phase_colors", "icon_break_background", "icon_idle_background",
+}
+
+var (
+	mProfiles        *systray.MenuItem   // Submenu showing the active profile
+	mProfileItems    []*systray.MenuItem // Menu items for the profiles
+	mProfilesMissing *systray.MenuItem   // Hint shown while no profiles are defined
+	profileMenuKeys  []string            // Profiles currently shown in mProfileItems
+)
+
+// profileNames returns the names of the profiles in s, sorted.
+func profileNames(s *TimerSettings) []string {
+	var names []string
+	for name := range s.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// captureProfile returns the values of the profile settings in s.
+func captureProfile(s *TimerSettings) map[string]json.RawMessage {
+	profile := map[string]json.RawMessage{}
+	for _, key := range profileKeys {
+		if data, err := json.Marshal(settingsFieldValue(s, key).Interface()); err == nil {
+			profile[key] = data
+		}
+	}
+	return profile
+}
+
+// withProfile returns s with the named profile active. The values of the
+// active profile are first stored in it, so changes made since switching to
+// it are kept. Settings missing from the new profile keep their values.
+func withProfile(s TimerSettings, name string) (TimerSettings, error) {
+	profile, ok := s.Profiles[name]
+	if !ok {
+		return s, fmt.Errorf("profile %q does not exist", name)
+	}
+
+	profiles := make(map[string]map[string]json.RawMessage, len(s.Profiles))
+	for k, v := range s.Profiles {
+		profiles[k] = v
+	}
+	if _, ok := profiles[s.Profile]; ok {
+		profiles[s.Profile] = captureProfile(&s)
+	}
+	s.Profiles = profiles
+
+	for _, key := range profileKeys {
+		data, ok := profile[key]
+		if !ok {
+			continue
+		}
+		field := settingsFieldValue(&s, key)
+		if err := json.Unmarshal(data, field.Addr().Interface()); err != nil {
+			return s, fmt.Errorf("profile %q: %s: %v", name, key, err)
+		}
+	}
+	s.Profile = name
+	return s, nil
+}
+
+// selectProfile switches to the named profile and saves the settings.
+func selectProfile(name string) {
+	mu.Lock()
+	saved := settingsToSave()
+	mu.Unlock()
+	if name == saved.Profile {
+		return
+	}
+	newSettings, err := withProfile(saved, name)
+	if err != nil {
+		slog.Error("Failed to switch profile", "err", err)
+		return
+	}
+	applyLaunchFlags(&newSettings)
+	reportSettingsProblems(validateSettings(&newSettings))
+	applySettings(newSettings)
+}
+
+// addProfileMenu adds the profile submenu to the system tray.
+func addProfileMenu() {
+	mProfiles = systray.AddMenuItem(tr("Profile"), tr("Switch between sets of durations, sounds and icon settings"))
+	mProfileNew := mProfiles.AddSubMenuItem(tr("New Profile..."), tr("Save the current durations, sounds and icon settings as a profile"))
+	mProfileNew.Click(func() {
+		openNewProfileEditor()
+	})
+	mProfilesMissing = mProfiles.AddSubMenuItem(tr("No profiles yet"), "")
+	mProfilesMissing.Disable()
+
+	profileMenuKeys = make([]string, maxProfileMenuItems)
+	for i := 0; i < maxProfileMenuItems; i++ {
+		slot := i
+		item := mProfiles.AddSubMenuItemCheckbox("", tr("Switch to this profile"), false)
+		item.Click(func() {
+			if profileMenuKeys[slot] != "" {
+				selectProfile(profileMenuKeys[slot])
+			}
+		})
+		item.Hide()
+		mProfileItems = append(mProfileItems, item)
+	}
+	updateProfileMenu()
+}
+
+// updateProfileMenu refreshes the profile submenu from the current settings.
+func updateProfileMenu() {
+	if mProfiles == nil {
+		return
+	}
+	if settings.Profile == "" {
+		mProfiles.SetTitle(tr("Profile"))
+	} else {
+		mProfiles.SetTitle(fmt.Sprintf(tr("Profile: %s"), settings.Profile))
+	}
+
+	names := profileNames(&settings)
+	if len(names) == 0 {
+		mProfilesMissing.Show()
+	} else {
+		mProfilesMissing.Hide()
+	}
+	for i, item := range mProfileItems {
+		if i >= len(names) {
+			profileMenuKeys[i] = ""
+			item.Hide()
+			continue
+		}
+		profileMenuKeys[i] = names[i]
+		item.SetTitle(names[i])
+		if names[i] == settings.Profile {
+			item.Check()
+		} else {
+			item.Uncheck()
+		}
+		item.Show()
+	}
+}
+
+// openNewProfileEditor asks for a name in the default text editor and saves
+// the current durations, sounds and icon settings as a new, active profile.
+func openNewProfileEditor() {
+	data, err := editInEditor("pomodoro_profile_*.txt", []byte("# Name of the new profile, e.g. study\n\n"))
+	if err != nil {
+		slog.Error("Failed to create profile", "err", err)
+		return
+	}
+
+	var name string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			name = line
+			break
+		}
+	}
+	if name == "" {
+		return
+	}
+
+	mu.Lock()
+	saved := settingsToSave()
+	newSettings := settings
+	mu.Unlock()
+	if _, exists := saved.Profiles[name]; exists {
+		sendNotification(tr("Profile not created"), fmt.Sprintf(tr("A profile named %q already exists"), name))
+		return
+	}
+	newSettings.Profiles = map[string]map[string]json.RawMessage{}
+	for k, v := range saved.Profiles {
+		newSettings.Profiles[k] = v
+	}
+	if _, ok := newSettings.Profiles[saved.Profile]; ok {
+		newSettings.Profiles[saved.Profile] = captureProfile(&saved)
+	}
+	newSettings.Profiles[name] = captureProfile(&saved)
+	newSettings.Profile = name
+	applySettings(newSettings)
+}
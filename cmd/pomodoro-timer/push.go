@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// PushSettings configures the notifications sent to phones through ntfy or
+// Pushover when a session finishes.
+type PushSettings struct {
+	NtfyServer    string `json:"ntfy_server"`    // ntfy server, e.g. https://ntfy.sh or a self-hosted one
+	NtfyTopic     string `json:"ntfy_topic"`     // Topic subscribed to in the ntfy app, empty to not use ntfy
+	NtfyToken     string `json:"ntfy_token"`     // Access token of a protected topic, empty for none
+	PushoverToken string `json:"pushover_token"` // API token of the Pushover application, empty to not use Pushover
+	PushoverUser  string `json:"pushover_user"`  // User or group key to notify
+}
+
+const pushoverAPIURL = "https://api.pushover.net/1/messages.json"
+
+var pushClient = &http.Client{Timeout: 15 * time.Second}
+
+// sendPushNotification sends a notification to the configured ntfy topic and
+// Pushover user, unless meeting mode is on. Unlike desktop notifications, they
+// are sent even if notifications are turned off, to reach you away from the desk.
+func sendPushNotification(title, message string) {
+	if meetingMode.Load() {
+		return
+	}
+	push := settings.Push
+	if push.NtfyTopic != "" {
+		if err := sendNtfy(push, title, message); err != nil {
+			slog.Error("Failed to send ntfy notification", "err", err)
+		}
+	}
+	if push.PushoverToken != "" && push.PushoverUser != "" {
+		if err := sendPushover(push, title, message); err != nil {
+			slog.Error("Failed to send Pushover notification", "err", err)
+		}
+	}
+}
+
+// sendNtfy publishes a message to the ntfy topic.
+func sendNtfy(push PushSettings, title, message string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"topic":   push.NtfyTopic,
+		"title":   title,
+		"message": message,
+		"tags":    []string{"tomato"},
+	})
+	if err != nil {
+		return err
+	}
+	server := push.NtfyServer
+	if server == "" {
+		server = defaultSettings().Push.NtfyServer
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimRight(server, "/"), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if push.NtfyToken != "" {
+		req.Header.Set("Authorization", "Bearer "+push.NtfyToken)
+	}
+	return doPushRequest(req)
+}
+
+// sendPushover sends a message to the Pushover user.
+func sendPushover(push PushSettings, title, message string) error {
+	form := url.Values{
+		"token":   {push.PushoverToken},
+		"user":    {push.PushoverUser},
+		"title":   {title},
+		"message": {message},
+	}
+	req, err := http.NewRequest(http.MethodPost, pushoverAPIURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return doPushRequest(req)
+}
+
+// doPushRequest sends a request to a push service and checks the status.
+func doPushRequest(req *http.Request) error {
+	resp, err := pushClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// callQueueSize is the number of calls a queue holds before dropping the
+// oldest call that is not a restore.
+const callQueueSize = 16
+
+// callQueues lists the queues of the integrations, flushed on exit.
+var callQueues []*callQueue
+
+// callQueue runs the calls of an integration in the background, one at a
+// time in the order they were queued, so e.g. clearing a status never
+// overtakes setting it. The worker is started by the first call. Queuing
+// never blocks, as it is usually done with mu held.
+type callQueue struct {
+	name    string // Integration named in the log
+	mu      sync.Mutex
+	pending []queuedCall  // Calls waiting for the worker, guarded by mu
+	wake    chan struct{} // Signals the worker that calls are pending
+	once    sync.Once
+}
+
+// queuedCall is a call waiting in a callQueue.
+type queuedCall struct {
+	run     func()
+	restore bool // Undoes the calls before it, e.g. clearing a status; never dropped
+}
+
+// newCallQueue returns an empty queue for the named integration.
+func newCallQueue(name string) *callQueue {
+	q := &callQueue{name: name, wake: make(chan struct{}, 1)}
+	callQueues = append(callQueues, q)
+	return q
+}
+
+// enqueue queues a call changing the integration for a session, e.g.
+// setting a status. While the integration does not keep up and the queue is
+// full, the oldest such call is dropped; the restore following it still runs.
+func (q *callQueue) enqueue(call func()) {
+	q.push(queuedCall{run: call})
+}
+
+// enqueueRestore queues a call undoing the changes of the calls before it,
+// e.g. clearing a status, so the integration does not stay changed after the
+// session. It is never dropped.
+func (q *callQueue) enqueueRestore(call func()) {
+	q.push(queuedCall{run: call, restore: true})
+}
+
+// flush waits until the calls queued so far have run and reports whether
+// they did before ctx was done.
+func (q *callQueue) flush(ctx context.Context) bool {
+	done := make(chan struct{})
+	q.enqueueRestore(func() { close(done) })
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// push adds a call to the queue, dropping the oldest call that is not a
+// restore if it is full, and wakes the worker.
+func (q *callQueue) push(call queuedCall) {
+	q.once.Do(func() {
+		go q.work()
+	})
+	q.mu.Lock()
+	if len(q.pending) >= callQueueSize {
+		for i, pending := range q.pending {
+			if !pending.restore {
+				q.pending = append(q.pending[:i], q.pending[i+1:]...)
+				slog.Warn("Integration is not keeping up; dropping a call", "integration", q.name)
+				break
+			}
+		}
+	}
+	q.pending = append(q.pending, call)
+	q.mu.Unlock()
+	select {
+	case q.wake <- struct{}{}:
+	default: // The worker is already signalled
+	}
+}
+
+// work runs the pending calls in order whenever it is woken.
+func (q *callQueue) work() {
+	for range q.wake {
+		for {
+			q.mu.Lock()
+			if len(q.pending) == 0 {
+				q.mu.Unlock()
+				break
+			}
+			call := q.pending[0]
+			q.pending = q.pending[1:]
+			q.mu.Unlock()
+			call.run()
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCallQueueKeepsRestores(t *testing.T) {
+	q := &callQueue{name: "test", wake: make(chan struct{}, 1)}
+	block := make(chan struct{})
+	q.enqueue(func() { <-block }) // Keeps the worker busy while the queue fills up
+
+	var ran []string
+	for i := 0; i < callQueueSize; i++ {
+		q.enqueue(func() { ran = append(ran, "start") })
+		q.enqueueRestore(func() { ran = append(ran, "restore") })
+	}
+	close(block)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if !q.flush(ctx) {
+		t.Fatal("the queue was not flushed")
+	}
+
+	restores := 0
+	for i, name := range ran {
+		if name == "restore" {
+			restores++
+		} else if i+1 < len(ran) && ran[i+1] != "restore" {
+			t.Errorf("call %d: a start is not followed by its restore: %v", i, ran)
+		}
+	}
+	if restores != callQueueSize {
+		t.Errorf("%d of %d restores ran", restores, callQueueSize)
+	}
+	if len(ran) >= 2*callQueueSize {
+		t.Errorf("all %d calls ran, though the queue was full", len(ran))
+	}
+	if ran[len(ran)-1] != "restore" {
+		t.Errorf("the last call run is a %s, want the last restore", ran[len(ran)-1])
+	}
+}
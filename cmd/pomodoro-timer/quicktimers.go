@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lutischan-ferenc/systray"
+	"pomodoro-timer/pkg/pomodoro"
+)
+
+const maxQuickTimerMenuItems = 10
+
+// QuickTimer is a named countdown that runs alongside the Pomodoro cycle,
+// e.g. for tea or the laundry.
+type QuickTimer struct {
+	Name     string `json:"name"`     // Shown in the menu and the notification, e.g. "Tea"
+	Duration string `json:"duration"` // Length as m:ss or minutes, e.g. "3:00" or "45"
+}
+
+// quickTimerRun is a running quick timer. Each has its own engine, so any
+// number of them count down at the same time as the Pomodoro.
+type quickTimerRun struct {
+	engine *pomodoro.Engine
+	cancel context.CancelFunc
+}
+
+var (
+	quickTimerRuns = map[string]*quickTimerRun{} // Running quick timers by name, guarded by mu
+
+	mQuickTimers     *systray.MenuItem   // Submenu for starting and cancelling quick timers
+	mQuickTimerItems []*systray.MenuItem // Menu items for the configured quick timers
+	quickTimerKeys   []string            // Names of the quick timers currently shown in mQuickTimerItems
+)
+
+// parseQuickTimerDuration parses the length of a quick timer: "m:ss", or a
+// number of minutes.
+func parseQuickTimerDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	var d time.Duration
+	if minutes, seconds, ok := strings.Cut(s, ":"); ok {
+		m, err1 := strconv.Atoi(minutes)
+		sec, err2 := strconv.Atoi(seconds)
+		if err1 != nil || err2 != nil || len(seconds) != 2 || sec >= 60 {
+			return 0, fmt.Errorf("%q is not a length like 3:00", s)
+		}
+		d = time.Duration(m)*time.Minute + time.Duration(sec)*time.Second
+	} else {
+		m, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a length like 3:00 or 45", s)
+		}
+		d = time.Duration(m * float64(time.Minute)).Round(time.Second)
+	}
+	if d < time.Second || d > 24*time.Hour {
+		return 0, fmt.Errorf("%q is not between 0:01 and 24 hours", s)
+	}
+	return d, nil
+}
+
+// validateQuickTimers drops quick timers without a name, with a name used
+// twice, or with an invalid length.
+func validateQuickTimers(s *TimerSettings) []string {
+	var problems []string
+	var valid []QuickTimer
+	seen := map[string]bool{}
+	for i, t := range s.QuickTimers {
+		t.Name = strings.TrimSpace(t.Name)
+		problem := ""
+		if _, err := parseQuickTimerDuration(t.Duration); err != nil {
+			problem = err.Error()
+		}
+		if seen[t.Name] {
+			problem = fmt.Sprintf("%q is used twice", t.Name)
+		}
+		if t.Name == "" {
+			problem = "no name is given"
+		}
+		if problem != "" {
+			problems = append(problems, fmt.Sprintf("quick_timers %d: %s; ignoring it", i+1, problem))
+			continue
+		}
+		seen[t.Name] = true
+		valid = append(valid, t)
+	}
+	if len(problems) > 0 {
+		s.QuickTimers = valid
+	}
+	return problems
+}
+
+// formatQuickTimer formats the time left of a quick timer as m:ss, or h:mm:ss.
+func formatQuickTimer(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d >= time.Hour {
+		return fmt.Sprintf("%d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// addQuickTimerMenu adds the submenu starting the configured quick timers.
+// Clicking a running one cancels it.
+func addQuickTimerMenu() {
+	mQuickTimers = systray.AddMenuItem(tr("Quick Timers"), tr("Countdowns running alongside the Pomodoro, e.g. for tea"))
+	quickTimerKeys = make([]string, maxQuickTimerMenuItems)
+	for i := 0; i < maxQuickTimerMenuItems; i++ {
+		slot := i
+		item := mQuickTimers.AddSubMenuItemCheckbox("", tr("Start this timer, or cancel it while it runs"), false)
+		item.Click(func() {
+			mu.Lock()
+			defer mu.Unlock()
+			if quickTimerKeys[slot] != "" {
+				toggleQuickTimer(quickTimerKeys[slot])
+			}
+		})
+		item.Hide()
+		mQuickTimerItems = append(mQuickTimerItems, item)
+	}
+	mu.Lock()
+	updateQuickTimerMenu()
+	mu.Unlock()
+}
+
+// updateQuickTimerMenu refreshes the quick timer submenu from the settings
+// and the running timers. Running timers removed from the settings are
+// cancelled. The caller must hold mu.
+func updateQuickTimerMenu() {
+	configured := map[string]bool{}
+	for _, t := range settings.QuickTimers {
+		configured[t.Name] = true
+	}
+	for name := range quickTimerRuns {
+		if !configured[name] {
+			cancelQuickTimer(name)
+		}
+	}
+
+	if mQuickTimers == nil {
+		return
+	}
+	if len(settings.QuickTimers) == 0 {
+		mQuickTimers.Hide()
+	} else {
+		mQuickTimers.Show()
+	}
+	for i, item := range mQuickTimerItems {
+		if i >= len(settings.QuickTimers) {
+			quickTimerKeys[i] = ""
+			item.Hide()
+			continue
+		}
+		t := settings.QuickTimers[i]
+		quickTimerKeys[i] = t.Name
+		if run, ok := quickTimerRuns[t.Name]; ok {
+			item.SetTitle(fmt.Sprintf(tr("%s: %s left"), t.Name, formatQuickTimer(run.engine.Remaining())))
+			item.Check()
+		} else {
+			d, _ := parseQuickTimerDuration(t.Duration)
+			item.SetTitle(fmt.Sprintf("%s (%s)", t.Name, formatQuickTimer(d)))
+			item.Uncheck()
+		}
+		item.Show()
+	}
+}
+
+// toggleQuickTimer starts the quick timer with the given name, or cancels it
+// if it is running. The caller must hold mu.
+func toggleQuickTimer(name string) {
+	if _, ok := quickTimerRuns[name]; ok {
+		cancelQuickTimer(name)
+	} else {
+		startQuickTimer(name)
+	}
+	updateQuickTimerMenu()
+}
+
+// startQuickTimer starts the configured quick timer with the given name. The
+// caller must hold mu.
+func startQuickTimer(name string) {
+	var timer *QuickTimer
+	for i := range settings.QuickTimers {
+		if settings.QuickTimers[i].Name == name {
+			timer = &settings.QuickTimers[i]
+		}
+	}
+	if timer == nil {
+		return
+	}
+	d, err := parseQuickTimerDuration(timer.Duration)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	run := &quickTimerRun{engine: pomodoro.New(&mu, nil), cancel: cancel}
+	run.engine.Tick = func(pomodoro.Session) {
+		updateQuickTimerMenu()
+	}
+	run.engine.Finished = func(pomodoro.Session) {
+		cancelQuickTimer(name)
+		updateQuickTimerMenu()
+		go sendNotification(fmt.Sprintf(tr("%s is done"), name), fmt.Sprintf(tr("The %s timer has run out"), formatQuickTimer(d)))
+		go playWarningChime()
+	}
+	go run.engine.Run(ctx)
+	// The kind only matters for the Pomodoro count, which quick timers do not use
+	run.engine.Start(pomodoro.Break, d)
+	quickTimerRuns[name] = run
+}
+
+// cancelQuickTimer stops the running quick timer with the given name. The
+// caller must hold mu.
+func cancelQuickTimer(name string) {
+	run, ok := quickTimerRuns[name]
+	if !ok {
+		return
+	}
+	run.engine.Stop()
+	run.cancel()
+	delete(quickTimerRuns, name)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseQuickTimerDuration(t *testing.T) {
+	for _, test := range []struct {
+		s    string
+		want time.Duration
+	}{
+		{"3:00", 3 * time.Minute},
+		{" 0:30 ", 30 * time.Second},
+		{"90:05", 90*time.Minute + 5*time.Second},
+		{"45", 45 * time.Minute},
+		{"1.5", 90 * time.Second},
+		{"1440", 24 * time.Hour},
+	} {
+		if got, err := parseQuickTimerDuration(test.s); err != nil || got != test.want {
+			t.Errorf("parseQuickTimerDuration(%q) = %v, %v; want %v", test.s, got, err, test.want)
+		}
+	}
+}
+
+func TestParseQuickTimerDurationErrors(t *testing.T) {
+	for _, s := range []string{"", "abc", "3:5", "3:60", "3:xx", "1:00:00", "0", "0:00", "-5", "1441"} {
+		if d, err := parseQuickTimerDuration(s); err == nil {
+			t.Errorf("parseQuickTimerDuration(%q) = %v, want an error", s, d)
+		}
+	}
+}
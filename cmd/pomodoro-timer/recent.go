@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lutischan-ferenc/systray"
+	"pomodoro-timer/pkg/pomodoro"
+)
+
+const maxRecentSessions = 5
+
+// recentSession is the configuration of a recently started Pomodoro.
+type recentSession struct {
+	Duration int    // Length in minutes
+	Task     string // Task the Pomodoro was spent on, empty without one
+	Tag      string // Tag of the Pomodoro, without the leading '#'
+}
+
+var (
+	recentSessions []recentSession // Distinct recent Pomodoros, newest first, guarded by mu
+
+	mRecent      *systray.MenuItem   // Submenu restarting recent Pomodoros
+	mRecentItems []*systray.MenuItem // Menu items for recentSessions
+)
+
+// label returns the menu title of the configuration, e.g. "50m - thesis #writing".
+func (r recentSession) label() string {
+	label := fmt.Sprintf("%dm", r.Duration)
+	if r.Task != "" {
+		label += " - " + r.Task
+	}
+	if r.Tag != "" {
+		label += " #" + r.Tag
+	}
+	return label
+}
+
+// initRecentSessions finds the configurations of the last Pomodoros in the history.
+func initRecentSessions() {
+	records, err := loadHistory()
+	if err != nil {
+		slog.Error("Failed to load history", "err", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	recentSessions = nil
+	for i := len(records) - 1; i >= 0 && len(recentSessions) < maxRecentSessions; i-- {
+		if records[i].Type == sessionPomodoro && records[i].Duration > 0 {
+			addRecentSession(recentSession{records[i].Duration, records[i].Task, records[i].Tag}, false)
+		}
+	}
+}
+
+// addRecentSession adds a configuration to the recent sessions, moving it to
+// the front if it is already listed, at the front or at the back. The caller
+// must hold mu.
+func addRecentSession(r recentSession, front bool) {
+	for i, listed := range recentSessions {
+		if listed == r {
+			if !front {
+				return
+			}
+			recentSessions = append(recentSessions[:i], recentSessions[i+1:]...)
+			break
+		}
+	}
+	if front {
+		recentSessions = append([]recentSession{r}, recentSessions...)
+	} else {
+		recentSessions = append(recentSessions, r)
+	}
+	if len(recentSessions) > maxRecentSessions {
+		recentSessions = recentSessions[:maxRecentSessions]
+	}
+}
+
+// recentSessionStarted puts the configuration of a started Pomodoro at the
+// front of the recent sessions.
+func recentSessionStarted(s sessionInfo) {
+	addRecentSession(recentSession{s.record.Duration, s.record.Task, s.record.Tag}, true)
+	updateRecentMenu()
+}
+
+// addRecentMenu adds the submenu restarting recent Pomodoros with their
+// length, task and tag.
+func addRecentMenu() {
+	mRecent = systray.AddMenuItem(tr("Recent"), tr("Start a Pomodoro like a recent one"))
+	for i := 0; i < maxRecentSessions; i++ {
+		slot := i
+		item := mRecent.AddSubMenuItem("", tr("Start a Pomodoro with this length, task and tag"))
+		item.Click(func() {
+			startRecentSession(slot)
+		})
+		item.Hide()
+		mRecentItems = append(mRecentItems, item)
+	}
+	mu.Lock()
+	updateRecentMenu()
+	mu.Unlock()
+}
+
+// updateRecentMenu refreshes the recent sessions submenu. The caller must hold mu.
+func updateRecentMenu() {
+	if mRecent == nil {
+		return
+	}
+	if len(recentSessions) == 0 {
+		mRecent.Hide()
+	} else {
+		mRecent.Show()
+	}
+	for i, item := range mRecentItems {
+		if i >= len(recentSessions) {
+			item.Hide()
+			continue
+		}
+		item.SetTitle(recentSessions[i].label())
+		item.Show()
+	}
+}
+
+// startRecentSession selects the task and tag of the recent session in the
+// given slot and starts a Pomodoro of its length, stopping any running timer.
+func startRecentSession(slot int) {
+	mu.Lock()
+	if slot >= len(recentSessions) {
+		mu.Unlock()
+		return
+	}
+	r := recentSessions[slot]
+	mu.Unlock()
+
+	selectTask(r.Task)
+	selectTag(r.Tag)
+	handleTimerClick(pomodoro.Pomodoro, time.Duration(r.Duration)*time.Minute)
+}
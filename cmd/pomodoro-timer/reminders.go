@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lutischan-ferenc/systray"
+)
+
+const maxReminderBeeps = 5
+
+var (
+	breakEndedAt       time.Time   // When the last break finished, guarded by mu
+	breakReminderTimer *time.Timer // Timer of the next break-over reminder, guarded by mu
+	breakReminderCount int         // Number of reminders sent since the break ended, guarded by mu
+
+	mDismissReminders *systray.MenuItem // Menu item for dismissing break-over reminders
+)
+
+// addBreakReminderMenu adds the menu item for dismissing break-over reminders.
+// It is only visible while reminders are active.
+func addBreakReminderMenu() {
+	mDismissReminders = systray.AddMenuItem(tr("Dismiss Break Reminders"), tr("Stop reminding me that the break is over"))
+	mDismissReminders.Click(func() {
+		mu.Lock()
+		stopBreakReminders()
+		mu.Unlock()
+	})
+	mDismissReminders.Hide()
+}
+
+// startBreakReminders schedules reminders after a break has finished.
+// The caller must hold mu.
+func startBreakReminders() {
+	stopBreakReminders()
+	if settings.BreakReminderMinutes <= 0 {
+		return
+	}
+	breakEndedAt = time.Now()
+	breakReminderCount = 0
+	scheduleBreakReminder()
+	if mDismissReminders != nil {
+		mDismissReminders.Show()
+	}
+}
+
+// stopBreakReminders cancels the pending break-over reminder. The caller must hold mu.
+func stopBreakReminders() {
+	if breakReminderTimer != nil {
+		breakReminderTimer.Stop()
+		breakReminderTimer = nil
+	}
+	if mDismissReminders != nil {
+		mDismissReminders.Hide()
+	}
+}
+
+// scheduleBreakReminder schedules the next reminder. The caller must hold mu.
+func scheduleBreakReminder() {
+	var timer *time.Timer
+	timer = time.AfterFunc(time.Duration(settings.BreakReminderMinutes)*time.Minute, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if breakReminderTimer != timer || engine.Running() {
+			return // Dismissed or a session was started in the meantime
+		}
+		sendBreakReminder()
+		scheduleBreakReminder()
+	})
+	breakReminderTimer = timer
+}
+
+// sendBreakReminder reminds that the break is over, with more beeps for every
+// reminder that was ignored. The caller must hold mu.
+func sendBreakReminder() {
+	breakReminderCount++
+	minutes := int(time.Since(breakEndedAt).Round(time.Minute).Minutes())
+	message := fmt.Sprintf(tr("Break ended %d minutes ago"), minutes)
+	if minutes == 1 {
+		message = tr("Break ended 1 minute ago")
+	}
+	setTooltip(fmt.Sprintf(tr("%s - Click to start pomodoro"), message))
+
+	beeps := breakReminderCount
+	if beeps > maxReminderBeeps {
+		beeps = maxReminderBeeps
+	}
+	go func() {
+		for i := 0; i < beeps; i++ {
+			playTickSound()
+			time.Sleep(100 * time.Millisecond)
+		}
+	}()
+
+	go sendActionNotification(tr("Time to focus"), message, []notificationAction{
+		{actionStartPomodoro, tr("Start Pomodoro")},
+		{actionDismissReminders, tr("Dismiss")},
+	})
+}
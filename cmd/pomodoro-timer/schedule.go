@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ScheduleOverride changes settings on some days of the week. The settings
+// are looked up when a session starts, so a session running past midnight
+// keeps its duration.
+type ScheduleOverride struct {
+	Days               []string `json:"days"`                           // "mon" to "sun", "weekdays" or "weekend"
+	PomodoroDuration   *int     `json:"pomodoro_duration,omitempty"`    // Duration of a Pomodoro session in minutes on these days
+	ShortBreakDuration *int     `json:"short_break_duration,omitempty"` // Duration of a short break in minutes on these days
+	LongBreakDuration  *int     `json:"long_break_duration,omitempty"`  // Duration of a long break in minutes on these days
+	EnableClockSound   *bool    `json:"enable_clock_sound,omitempty"`   // Play the background sound during Pomodoros on these days
+}
+
+// weekdayNames are the names of the days in ScheduleOverride.Days.
+var weekdayNames = map[string][]time.Weekday{
+	"mon":      {time.Monday},
+	"tue":      {time.Tuesday},
+	"wed":      {time.Wednesday},
+	"thu":      {time.Thursday},
+	"fri":      {time.Friday},
+	"sat":      {time.Saturday},
+	"sun":      {time.Sunday},
+	"weekdays": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"weekend":  {time.Saturday, time.Sunday},
+}
+
+// appliesOn reports whether the override applies on the weekday.
+func (o ScheduleOverride) appliesOn(day time.Weekday) bool {
+	for _, name := range o.Days {
+		for _, d := range weekdayNames[strings.ToLower(name)] {
+			if d == day {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scheduledSettings returns the settings with the schedule overrides for the
+// day of t applied. Later overrides win over earlier ones.
+func scheduledSettings(t time.Time) TimerSettings {
+	s := settings
+	for _, o := range settings.Schedule {
+		if !o.appliesOn(t.Weekday()) {
+			continue
+		}
+		if o.PomodoroDuration != nil {
+			s.PomodoroDuration = *o.PomodoroDuration
+		}
+		if o.ShortBreakDuration != nil {
+			s.ShortBreakDuration = *o.ShortBreakDuration
+		}
+		if o.LongBreakDuration != nil {
+			s.LongBreakDuration = *o.LongBreakDuration
+		}
+		if o.EnableClockSound != nil {
+			s.EnableClockSound = *o.EnableClockSound
+		}
+	}
+	return s
+}
+
+// pomodoroDuration returns the duration of a Pomodoro started now.
+func pomodoroDuration() time.Duration {
+	return time.Duration(scheduledSettings(time.Now()).PomodoroDuration) * time.Minute
+}
+
+// shortBreakDuration returns the duration of a short break started now.
+func shortBreakDuration() time.Duration {
+	return time.Duration(scheduledSettings(time.Now()).ShortBreakDuration) * time.Minute
+}
+
+// longBreakDuration returns the duration of a long break started now.
+func longBreakDuration() time.Duration {
+	return time.Duration(scheduledSettings(time.Now()).LongBreakDuration) * time.Minute
+}
+
+// validateSchedule drops schedule overrides with unknown days and durations
+// outside 1 to 600 minutes, and returns a description of each.
+func validateSchedule(s *TimerSettings) []string {
+	var problems []string
+	var valid []ScheduleOverride
+	for i, o := range s.Schedule {
+		problem := ""
+		for _, name := range o.Days {
+			if _, ok := weekdayNames[strings.ToLower(name)]; !ok {
+				problem = fmt.Sprintf("%q is not a day like mon, weekdays or weekend", name)
+			}
+		}
+		for _, d := range []*int{o.PomodoroDuration, o.ShortBreakDuration, o.LongBreakDuration} {
+			if d != nil && (*d < 1 || *d > 600) {
+				problem = fmt.Sprintf("%d is not between 1 and 600 minutes", *d)
+			}
+		}
+		if len(o.Days) == 0 {
+			problem = "no days are given"
+		}
+		if problem != "" {
+			problems = append(problems, fmt.Sprintf("schedule %d: %s; ignoring it", i+1, problem))
+			continue
+		}
+		valid = append(valid, o)
+	}
+	if len(problems) > 0 {
+		s.Schedule = valid
+	}
+	return problems
+}
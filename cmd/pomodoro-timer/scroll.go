@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"pomodoro-timer/pkg/pomodoro"
+)
+
+// scrollStep is the time one notch of the mouse wheel over the tray icon
+// adds or takes away.
+const scrollStep = time.Minute
+
+// maxScrollDuration is the longest session scrolling sets, like the duration settings.
+const maxScrollDuration = 600 * time.Minute
+
+var (
+	nextKind     pomodoro.Kind // Kind of session nextDuration is for, guarded by mu
+	nextDuration time.Duration // Scrolled length of the next session, 0 for the configured one, guarded by mu
+)
+
+// updateScrollWatch starts watching the mouse wheel over the tray icon when
+// scroll_adjusts_time is enabled.
+func updateScrollWatch() {
+	if settings.ScrollAdjustsTime {
+		watchScroll(trayScrolled)
+	}
+}
+
+// trayScrolled adds a minute to the running session per notch scrolled up,
+// and takes one away per notch scrolled down. While no session runs, it
+// changes the length of the session a click starts next instead.
+func trayScrolled(notches int) {
+	if !settings.ScrollAdjustsTime || notches == 0 {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	d := time.Duration(notches) * scrollStep
+	if engine.Running() {
+		// Keep at least a minute, so that scrolling down never ends the session.
+		if remaining := engine.Remaining(); remaining+d < time.Minute {
+			d = time.Minute - remaining
+			if d >= 0 {
+				return
+			}
+		}
+		if engine.Duration()+d > maxScrollDuration {
+			return
+		}
+		extendRunning(d)
+		setTooltip(fmt.Sprintf(tr("%d minutes left"), int(engine.Remaining().Minutes())))
+		return
+	}
+
+	kind := engine.Next()
+	if nextDuration == 0 || nextKind != kind {
+		nextKind = kind
+		nextDuration = pomodoroDuration()
+		if kind == pomodoro.Break {
+			nextDuration = nextBreakDuration()
+		}
+	}
+	nextDuration += d
+	if nextDuration < time.Minute {
+		nextDuration = time.Minute
+	} else if nextDuration > maxScrollDuration {
+		nextDuration = maxScrollDuration
+	}
+	minutes := int(nextDuration.Minutes())
+	setTrayIcon(fmt.Sprint(minutes), engine.Count())
+	if kind == pomodoro.Break {
+		setTooltip(fmt.Sprintf(tr("Next break: %d minutes - Click to start"), minutes))
+	} else {
+		setTooltip(fmt.Sprintf(tr("Next Pomodoro: %d minutes - Click to start"), minutes))
+	}
+}
+
+// scrolledDuration returns the scrolled length of a session of the given kind
+// started now, or duration if none was scrolled, and forgets it. The caller
+// must hold mu.
+func scrolledDuration(kind pomodoro.Kind, duration time.Duration) time.Duration {
+	if nextDuration > 0 && nextKind == kind {
+		duration = nextDuration
+	}
+	nextDuration = 0
+	return duration
+}
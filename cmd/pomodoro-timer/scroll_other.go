@@ -0,0 +1,7 @@
+//go:build !windows
+
+package main
+
+// watchScroll does nothing, as the systray package does not report scrolling
+// over the tray icon, and it is only watched on Windows.
+func watchScroll(fn func(notches int)) {}
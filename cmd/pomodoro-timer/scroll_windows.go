@@ -0,0 +1,117 @@
+package main
+
+import (
+	"log/slog"
+	"runtime"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	shell32 = windows.NewLazySystemDLL("shell32.dll")
+
+	procSetWindowsHookExW      = user32.NewProc("SetWindowsHookExW")
+	procCallNextHookEx         = user32.NewProc("CallNextHookEx")
+	procShellNotifyIconGetRect = shell32.NewProc("Shell_NotifyIconGetRect")
+
+	scrollWatchOnce sync.Once
+	onScroll        func(notches int) // Called with the notches scrolled over the tray icon
+	scrollTrayHwnd  uintptr           // Window of the tray icon
+)
+
+const (
+	whMouseLL    = 14
+	wmMouseWheel = 0x020A
+	wheelDelta   = 120 // Wheel movement of one notch
+	trayIconID   = 100 // ID the systray package adds its icon with
+)
+
+// msllHookStruct is the MSLLHOOKSTRUCT of a low-level mouse event.
+type msllHookStruct struct {
+	X, Y      int32
+	MouseData uint32
+	Flags     uint32
+	Time      uint32
+	ExtraInfo uintptr
+}
+
+// notifyIconIdentifier is the NOTIFYICONIDENTIFIER of a tray icon.
+type notifyIconIdentifier struct {
+	Size     uint32
+	Hwnd     uintptr
+	ID       uint32
+	GuidItem windows.GUID
+}
+
+// watchScroll calls fn with the notches scrolled over the tray icon, positive
+// when scrolled up. Tray icons do not receive wheel messages, so a low-level
+// mouse hook checks every wheel movement against the rectangle of the icon.
+// The hook stays installed once set; fn checks the settings itself.
+func watchScroll(fn func(notches int)) {
+	if launch.headless {
+		return
+	}
+	scrollWatchOnce.Do(func() {
+		hwnd := findTrayWindow()
+		if hwnd == 0 {
+			slog.Error("Failed to find the tray window, scrolling is ignored")
+			return
+		}
+		scrollTrayHwnd = hwnd
+		onScroll = fn
+		go runScrollHook()
+	})
+}
+
+// runScrollHook installs the mouse hook and runs the message loop its
+// thread needs to receive the events.
+func runScrollHook() {
+	runtime.LockOSThread()
+	hook, _, err := procSetWindowsHookExW.Call(whMouseLL, windows.NewCallback(scrollHookProc), 0, 0)
+	if hook == 0 {
+		slog.Error("Failed to watch scrolling over the tray icon", "err", err)
+		return
+	}
+	var m struct {
+		Hwnd    uintptr
+		Message uint32
+		WParam  uintptr
+		LParam  uintptr
+		Time    uint32
+		Pt      struct{ X, Y int32 }
+	}
+	for {
+		ret, _, _ := procGetMessageW.Call(uintptr(unsafe.Pointer(&m)), 0, 0, 0)
+		if int32(ret) <= 0 {
+			return
+		}
+	}
+}
+
+// scrollHookProc reports wheel movements over the tray icon and passes every
+// mouse event on.
+func scrollHookProc(code, wParam uintptr, event *msllHookStruct) uintptr {
+	if int32(code) >= 0 && wParam == wmMouseWheel {
+		if pointOverTrayIcon(event.X, event.Y) {
+			if notches := int(int16(event.MouseData>>16)) / wheelDelta; notches != 0 {
+				go onScroll(notches)
+			}
+		}
+	}
+	ret, _, _ := procCallNextHookEx.Call(0, code, wParam, uintptr(unsafe.Pointer(event)))
+	return ret
+}
+
+// pointOverTrayIcon reports whether the screen point is on the tray icon,
+// also when the icon is in the overflow area.
+func pointOverTrayIcon(x, y int32) bool {
+	id := notifyIconIdentifier{Hwnd: scrollTrayHwnd, ID: trayIconID}
+	id.Size = uint32(unsafe.Sizeof(id))
+	var r windows.Rect
+	if hr, _, _ := procShellNotifyIconGetRect.Call(uintptr(unsafe.Pointer(&id)), uintptr(unsafe.Pointer(&r))); hr != 0 {
+		return false
+	}
+	return x >= r.Left && x < r.Right && y >= r.Top && y < r.Bottom
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"pomodoro-timer/internal/icon"
+)
+
+//go:embed assets/settings.html
+var settingsFormHTML string
+
+// settingsField is a field of the settings form, addressed by its JSON key.
+type settingsField struct {
+	Key     string
+	Label   string
+	Min     float64  // Minimum of a number
+	Max     float64  // Maximum of a number
+	Options []string // Allowed values of a text field, shown as a list
+	Sound   bool     // The value is the path of a sound file, which must load
+}
+
+// settingsSection groups the fields of the settings form.
+type settingsSection struct {
+	Title  string
+	Fields []settingsField
+}
+
+var settingsForm = []settingsSection{
+	{"Durations", []settingsField{
+		{Key: "pomodoro_duration", Label: "Pomodoro (minutes)", Min: 1, Max: 600},
+		{Key: "short_break_duration", Label: "Short break (minutes)", Min: 1, Max: 600},
+		{Key: "long_break_duration", Label: "Long break (minutes)", Min: 1, Max: 600},
+	}},
+	{"Sounds", []settingsField{
+		{Key: "enable_clock_sound", Label: "Play a background sound during Pomodoros"},
+		{Key: "background_sound", Label: "Background sound", Options: backgroundSoundIDs()},
+		{Key: "clock_fade_ms", Label: "Background fade (milliseconds)", Min: 0, Max: 10000},
+		{Key: "binaural_carrier_frequency", Label: "Binaural beat tone (Hz)", Min: 40, Max: 1000},
+		{Key: "binaural_beat_frequency", Label: "Binaural beat frequency (Hz)", Min: 0.5, Max: 40},
+		{Key: "clock_sound_path", Label: "Clock sound file", Sound: true},
+		{Key: "pomodoro_end_sound_path", Label: "Pomodoro end sound file", Sound: true},
+		{Key: "break_end_sound_path", Label: "Break end sound file", Sound: true},
+		{Key: "master_volume", Label: "Master volume", Min: 0, Max: 100},
+		{Key: "clock_volume", Label: "Clock volume", Min: 0, Max: 100},
+		{Key: "alarm_volume", Label: "Alarm volume", Min: 0, Max: 100},
+		{Key: "muted", Label: "Mute all sounds"},
+		{Key: "auto_mute_in_meetings", Label: "Mute during meetings"},
+		{Key: "pause_media", Label: "Pause music during", Options: []string{pauseMediaOff, pauseMediaBreaks, pauseMediaPomodoros}},
+		{Key: "insistent_alarm", Label: "Repeat the alarm until acknowledged"},
+		{Key: "final_countdown", Label: "Final countdown", Options: []string{countdownBeeps, countdownChime, countdownOff}},
+		{Key: "final_countdown_seconds", Label: "Countdown beeps (seconds)", Min: 0, Max: 60},
+		{Key: "final_countdown_frequency", Label: "Beep pitch (Hz)", Min: 50, Max: 5000},
+		{Key: "interval_chime_minutes", Label: "Chime during Pomodoros every (minutes, 0 = off)", Min: 0, Max: 60},
+	}},
+	{"Notifications", []settingsField{
+		{Key: "enable_notifications", Label: "Notify when a session finishes"},
+		{Key: "pre_end_warning_minutes", Label: "Warn before a Pomodoro ends (minutes, 0 = off)", Min: 0, Max: 60},
+		{Key: "pre_end_warning_notification", Label: "Warning notification"},
+		{Key: "pre_end_warning_chime", Label: "Warning chime"},
+		{Key: "break_reminder_minutes", Label: "Remind after a break every (minutes, 0 = off)", Min: 0, Max: 120},
+		{Key: "eye_rest", Label: "Remind to rest the eyes during Pomodoros"},
+		{Key: "eye_rest_minutes", Label: "Eye rest every (minutes)", Min: 1, Max: 120},
+		{Key: "eye_rest_seconds", Label: "Eye rest length (seconds)", Min: 5, Max: 300},
+		{Key: "flash_icon_when_finished", Label: "Flash the icon when a session finishes"},
+		{Key: "focus_assist", Label: "Focus Assist during Pomodoros (Windows)", Options: []string{focusAssistOff, focusAssistPriority, focusAssistAlarms}},
+		{Key: "desktop_dnd", Label: "Do Not Disturb during Pomodoros (GNOME, KDE)"},
+		{Key: "check_for_updates", Label: "Check for updates weekly"},
+		{Key: "language", Label: "Language of the menus and notifications", Options: append([]string{languageAuto}, languages...)},
+		{Key: "achievements", Label: "Achievements"},
+	}},
+	{"Icon", []settingsField{
+		{Key: "icon_style", Label: "Style", Options: []string{string(icon.Digits), string(icon.Ring), string(icon.Pie)}},
+		{Key: "icon_theme", Label: "Theme", Options: iconThemeIDs()},
+		{Key: "icon_seconds_minutes", Label: "Show m:ss below (minutes, 0 = off)", Min: 0, Max: 60},
+		{Key: "tooltip_update_seconds", Label: "Update the tooltip every (seconds)", Min: 1, Max: 60},
+		{Key: "tooltip_format", Label: "Tooltip template (empty = time left and task)"},
+		{Key: "icon_phase_colors", Label: "Color by Pomodoro, break and stopped"},
+		{Key: "taskbar_progress", Label: "Taskbar progress (Windows)"},
+		{Key: "left_click_action", Label: "Click", Options: clickActions},
+		{Key: "confirm_stop", Label: "Confirm before a click stops a Pomodoro"},
+		{Key: "double_click_action", Label: "Double click", Options: clickActions},
+		{Key: "middle_click_action", Label: "Middle click (Windows)", Options: clickActions},
+		{Key: "scroll_adjusts_time", Label: "Scroll over the icon to change the time (Windows)"},
+	}},
+}
+
+// settingsFormField is a field of the settings form prepared for the template.
+type settingsFormField struct {
+	Key, Label, Kind, Value, Step, Error string
+	Checked                              bool
+	Min, Max                             float64
+	Options                              []string
+}
+
+// settingsFormSection is a section of the settings form prepared for the template.
+type settingsFormSection struct {
+	Title  string
+	Fields []settingsFormField
+}
+
+var (
+	settingsServerOnce sync.Once
+	settingsServerAddr string // Address of the settings server, empty if it failed to start
+	settingsToken      string // Secret required by the settings server
+	settingsTemplate   = template.Must(template.New("settings").Parse(settingsFormHTML))
+)
+
+// backgroundSoundIDs returns the IDs of the background sounds.
+func backgroundSoundIDs() []string {
+	var ids []string
+	for _, sound := range backgroundSounds {
+		ids = append(ids, sound.id)
+	}
+	return ids
+}
+
+// iconThemeIDs returns the IDs of the icon themes, including the automatic one.
+func iconThemeIDs() []string {
+	ids := []string{iconThemeAuto}
+	for _, theme := range iconThemes {
+		ids = append(ids, theme.id)
+	}
+	return ids
+}
+
+// settingsFieldValue returns the settings field with the JSON key.
+func settingsFieldValue(s *TimerSettings, key string) reflect.Value {
+	v := reflect.ValueOf(s).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if strings.Split(v.Type().Field(i).Tag.Get("json"), ",")[0] == key {
+			return v.Field(i)
+		}
+	}
+	return reflect.Value{}
+}
+
+// openSettingsForm opens the settings form in the browser. The form is served
+// on a random local port and protected by a secret token. If the server
+// cannot start, the settings file is opened in the text editor instead.
+func openSettingsForm() {
+	settingsServerOnce.Do(startSettingsServer)
+	if settingsServerAddr == "" {
+		openSettingsEditor()
+		return
+	}
+	openBrowser(fmt.Sprintf("http://%s/settings?token=%s", settingsServerAddr, settingsToken))
+}
+
+// startSettingsServer starts the HTTP server of the settings form.
+func startSettingsServer() {
+	secret := make([]byte, 16)
+	if _, err := rand.Read(secret); err != nil {
+		slog.Error("Failed to create settings token", "err", err)
+		return
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		slog.Error("Failed to start settings server", "err", err)
+		return
+	}
+	settingsToken = hex.EncodeToString(secret)
+	settingsServerAddr = listener.Addr().String()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/settings", handleSettingsForm)
+	go func() {
+		if err := http.Serve(listener, mux); err != nil {
+			slog.Error("Settings server stopped", "err", err)
+		}
+	}()
+}
+
+// handleSettingsForm shows the settings form and applies submitted settings.
+func handleSettingsForm(w http.ResponseWriter, r *http.Request) {
+	serveSettingsForm(w, r, settingsToken)
+}
+
+// serveSettingsForm serves the settings form to requests carrying token.
+func serveSettingsForm(w http.ResponseWriter, r *http.Request, token string) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	given := r.Form.Get("token")
+	if given == "" {
+		given = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+		http.Error(w, "invalid token", http.StatusForbidden)
+		return
+	}
+
+	newSettings := currentSettings()
+	errors := map[string]string{}
+	message := ""
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		errors = parseSettingsForm(r, &newSettings)
+		if len(errors) == 0 {
+			applySettings(newSettings)
+			message = "Settings saved."
+		} else {
+			message = "Settings not saved, please correct the marked fields."
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	data := struct {
+		Token    string
+		Message  string
+		Failed   bool
+		Sections []settingsFormSection
+	}{token, message, len(errors) > 0, buildSettingsForm(&newSettings, r, errors)}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := settingsTemplate.Execute(w, data); err != nil {
+		slog.Error("Failed to render settings form", "err", err)
+	}
+}
+
+// parseSettingsForm validates the submitted fields and stores them in s.
+// It returns the error message of each invalid field by key.
+func parseSettingsForm(r *http.Request, s *TimerSettings) map[string]string {
+	errors := map[string]string{}
+	for _, section := range settingsForm {
+		for _, field := range section.Fields {
+			value := settingsFieldValue(s, field.Key)
+			input := strings.TrimSpace(r.PostForm.Get(field.Key))
+			switch value.Kind() {
+			case reflect.Bool:
+				value.SetBool(input != "")
+			case reflect.Int, reflect.Float64:
+				n, err := strconv.ParseFloat(input, 64)
+				switch {
+				case err != nil:
+					errors[field.Key] = "Enter a number."
+				case n < field.Min || n > field.Max:
+					errors[field.Key] = fmt.Sprintf("Enter a value between %g and %g.", field.Min, field.Max)
+				case value.Kind() == reflect.Int:
+					if n != float64(int(n)) {
+						errors[field.Key] = "Enter a whole number."
+					} else {
+						value.SetInt(int64(n))
+					}
+				default:
+					value.SetFloat(n)
+				}
+			case reflect.String:
+				if field.Options != nil && !containsString(field.Options, input) {
+					errors[field.Key] = "Choose one of: " + strings.Join(field.Options, ", ") + "."
+					continue
+				}
+				if field.Sound && input != "" {
+					if _, err := decodeSoundFile(input); err != nil {
+						errors[field.Key] = "Cannot load the sound: " + err.Error()
+						continue
+					}
+				}
+				value.SetString(input)
+			}
+		}
+	}
+	if err := checkTooltipFormat(s.TooltipFormat); err != nil {
+		errors["tooltip_format"] = "Cannot use the template: " + err.Error()
+	}
+	return errors
+}
+
+// buildSettingsForm prepares the fields of s for the template. Invalid fields
+// keep the submitted text so it can be corrected.
+func buildSettingsForm(s *TimerSettings, r *http.Request, errors map[string]string) []settingsFormSection {
+	var sections []settingsFormSection
+	for _, section := range settingsForm {
+		formSection := settingsFormSection{Title: section.Title}
+		for _, field := range section.Fields {
+			value := settingsFieldValue(s, field.Key)
+			f := settingsFormField{Key: field.Key, Label: field.Label, Min: field.Min, Max: field.Max, Options: field.Options, Error: errors[field.Key]}
+			switch value.Kind() {
+			case reflect.Bool:
+				f.Kind = "bool"
+				f.Checked = value.Bool()
+			case reflect.Int:
+				f.Kind, f.Step = "number", "1"
+				f.Value = strconv.FormatInt(value.Int(), 10)
+			case reflect.Float64:
+				f.Kind, f.Step = "number", "any"
+				f.Value = strconv.FormatFloat(value.Float(), 'g', -1, 64)
+			default:
+				f.Kind = "text"
+				if field.Options != nil {
+					f.Kind = "select"
+				}
+				f.Value = value.String()
+			}
+			if f.Error != "" {
+				f.Value = r.PostForm.Get(field.Key)
+			}
+			formSection.Fields = append(formSection.Fields, f)
+		}
+		sections = append(sections, formSection)
+	}
+	return sections
+}
+
+// containsString reports whether the list contains s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
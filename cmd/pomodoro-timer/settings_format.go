@@ -0,0 +1,354 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// settingsFileNames are the names the settings file may have, in order of
+// preference. The format is detected from the extension.
+var settingsFileNames = []string{"settings.yaml", "settings.yml", "settings.toml", "settings.json"}
+
+// findSettingsFile returns the path of the first existing settings file, or
+// the JSON file if there is none.
+func findSettingsFile() string {
+	for _, name := range settingsFileNames {
+		path := getConfigFilePath(name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return getConfigFilePath("settings.json")
+}
+
+// settingsToJSON converts the contents of a YAML or TOML settings file to
+// JSON, so that all formats share the JSON keys and validation.
+func settingsToJSON(path string, data []byte) ([]byte, error) {
+	var values map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return nil, err
+		}
+	case ".toml":
+		if err := toml.Unmarshal(data, &values); err != nil {
+			return nil, err
+		}
+	default:
+		return data, nil
+	}
+	if values == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(values)
+}
+
+// encodeSettings encodes settings in the format of the file at path. The
+// comments of old, the current contents of a YAML or TOML file, are kept.
+func encodeSettings(path string, s TimerSettings, old []byte) ([]byte, error) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		// JSON is YAML, so decoding it into a node keeps the field order.
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return nil, err
+		}
+		blockStyle(&node)
+		var oldNode yaml.Node
+		if yaml.Unmarshal(old, &oldNode) == nil && oldNode.Kind == yaml.DocumentNode {
+			node = *mergeYAML(&oldNode, &node)
+		}
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(&node); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case ".toml":
+		var values map[string]interface{}
+		if err := json.Unmarshal(data, &values); err != nil {
+			return nil, err
+		}
+		tomlValues(values)
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(values); err != nil {
+			return nil, err
+		}
+		return keepTOMLComments(old, buf.Bytes()), nil
+	}
+	return data, nil
+}
+
+// mergeYAML returns node, the new settings, merged into old, the node decoded
+// from the settings file. Unchanged values, the order of the keys and the
+// comments of old are kept.
+func mergeYAML(old, node *yaml.Node) *yaml.Node {
+	if old.Kind != node.Kind {
+		node.HeadComment, node.LineComment, node.FootComment = old.HeadComment, old.LineComment, old.FootComment
+		return node
+	}
+	switch old.Kind {
+	case yaml.ScalarNode:
+		if old.Value != node.Value || old.ShortTag() != node.ShortTag() {
+			old.Value, old.Tag, old.Style = node.Value, node.Tag, node.Style
+		}
+	case yaml.MappingNode:
+		values := map[string]*yaml.Node{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			values[node.Content[i].Value] = node.Content[i+1]
+		}
+		// Keys missing from the new settings are removed, new ones appended
+		var content []*yaml.Node
+		for i := 0; i+1 < len(old.Content); i += 2 {
+			key := old.Content[i].Value
+			if value, ok := values[key]; ok {
+				content = append(content, old.Content[i], mergeYAML(old.Content[i+1], value))
+				delete(values, key)
+			}
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if _, ok := values[node.Content[i].Value]; ok {
+				content = append(content, node.Content[i], node.Content[i+1])
+			}
+		}
+		old.Content = content
+	default:
+		for i, child := range node.Content {
+			if i < len(old.Content) {
+				node.Content[i] = mergeYAML(old.Content[i], child)
+			}
+		}
+		old.Content = node.Content
+	}
+	return old
+}
+
+// blockStyle makes a YAML node and its children use the block style.
+func blockStyle(node *yaml.Node) {
+	node.Style &^= yaml.FlowStyle | yaml.DoubleQuotedStyle
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// tomlValues prepares values decoded from JSON for TOML: null values, which
+// TOML cannot represent, are removed and whole numbers become integers.
+func tomlValues(values map[string]interface{}) {
+	for key, value := range values {
+		if value == nil {
+			delete(values, key)
+			continue
+		}
+		values[key] = tomlValue(value)
+	}
+}
+
+// tomlValue prepares a single value decoded from JSON for TOML.
+func tomlValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+	case map[string]interface{}:
+		tomlValues(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = tomlValue(v[i])
+		}
+	}
+	return value
+}
+
+// keepTOMLComments adds the comments of old, a TOML settings file, to data,
+// the settings encoded again. As the encoder sorts the keys, the comments
+// before the first key stay at the top and the ones after the last key at
+// the end; the others stay above and at the end of the line of their key or
+// table.
+func keepTOMLComments(old, data []byte) []byte {
+	above := map[string][]string{}
+	after := map[string]string{}
+	var head, comments []string
+	_, oldInfo := scanTOML(old)
+	for _, info := range oldInfo {
+		switch {
+		case info.key != "" && len(after) == 0:
+			head, after[info.key], comments = comments, info.comment, nil
+		case info.key != "":
+			above[info.key], after[info.key], comments = comments, info.comment, nil
+		case !info.continued && info.comment != "":
+			comments = append(comments, info.comment)
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, comment := range head {
+		buf.WriteString(comment + "\n")
+	}
+	lines, info := scanTOML(data)
+	for i, line := range lines {
+		if key := info[i].key; key != "" {
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			for _, comment := range above[key] {
+				buf.WriteString(indent + comment + "\n")
+			}
+			if after[key] != "" {
+				line += " " + after[key]
+			}
+		}
+		buf.WriteString(line + "\n")
+	}
+	for _, comment := range comments {
+		buf.WriteString(comment + "\n")
+	}
+	return buf.Bytes()
+}
+
+// tomlLine describes a line of a TOML file.
+type tomlLine struct {
+	key       string // Path of the key or table set on the line, if any
+	comment   string // Comment at the end of the line, or the whole line
+	continued bool   // Whether the line continues a multi-line value
+}
+
+// scanTOML splits a TOML file into lines and describes each. Keys are
+// identified by their path, such as "jira.url", so that a key is matched
+// however it is written; the tables of an array get their index.
+func scanTOML(data []byte) ([]string, []tomlLine) {
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	result := make([]tomlLine, len(lines))
+	table := ""
+	arrays := map[string]int{}
+	open, depth := "", 0 // The multi-line string or array continued by the next line
+	for i, line := range lines {
+		text := strings.TrimSpace(line)
+		if open != "" || depth > 0 {
+			var comment int
+			open, depth, comment = scanTOMLText(text, open, depth)
+			if comment >= 0 {
+				result[i].comment = text[comment:]
+			}
+			result[i].continued = true
+			continue
+		}
+		switch {
+		case strings.HasPrefix(text, "#"):
+			result[i].comment = text
+		case strings.HasPrefix(text, "["):
+			if _, _, comment := scanTOMLText(text, "", 0); comment >= 0 {
+				result[i].comment, text = text[comment:], strings.TrimSpace(text[:comment])
+			}
+			if strings.HasPrefix(text, "[[") {
+				name := tomlKey(strings.TrimSuffix(strings.TrimPrefix(text, "[["), "]]"))
+				table = fmt.Sprintf("%s[%d]", name, arrays[name])
+				arrays[name]++
+			} else {
+				table = tomlKey(strings.TrimSuffix(strings.TrimPrefix(text, "["), "]"))
+			}
+			result[i].key = table
+		case text != "":
+			equals := tomlEquals(text)
+			if equals < 0 {
+				continue
+			}
+			result[i].key = tomlKey(text[:equals])
+			if table != "" {
+				result[i].key = table + "." + result[i].key
+			}
+			var comment int
+			open, depth, comment = scanTOMLText(text[equals+1:], "", 0)
+			if comment >= 0 {
+				result[i].comment = text[equals+1+comment:]
+			}
+		}
+	}
+	return lines, result
+}
+
+// scanTOMLText scans a line of a TOML value, which starts in the string
+// closed by open, if any, and depth arrays or inline tables deep. It returns
+// the same at the end of the line and the index of its comment, or -1.
+func scanTOMLText(text, open string, depth int) (string, int, int) {
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case open != "":
+			if c == '\\' && open[0] == '"' {
+				i++
+			} else if strings.HasPrefix(text[i:], open) {
+				i += len(open) - 1
+				open = ""
+			}
+		case c == '#':
+			return open, depth, i
+		case c == '"' || c == '\'':
+			open = string(c)
+			if strings.HasPrefix(text[i:], strings.Repeat(open, 3)) {
+				open = strings.Repeat(open, 3)
+				i += 2
+			}
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		}
+	}
+	if len(open) == 1 {
+		open = "" // Only multi-line strings continue on the next line
+	}
+	return open, depth, -1
+}
+
+// tomlEquals returns the index of the = between the key and the value of a
+// line, or -1.
+func tomlEquals(text string) int {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '=':
+			return i
+		}
+	}
+	return -1
+}
+
+// tomlKey returns the path of a dotted, possibly quoted TOML key.
+func tomlKey(key string) string {
+	var parts []string
+	var part strings.Builder
+	var quote byte
+	for i := 0; i < len(key); i++ {
+		switch c := key[i]; {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			part.WriteByte(c)
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '.':
+			parts = append(parts, part.String())
+			part.Reset()
+		case c != ' ' && c != '\t':
+			part.WriteByte(c)
+		}
+	}
+	return strings.Join(append(parts, part.String()), ".")
+}
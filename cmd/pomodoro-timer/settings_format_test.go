@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEncodeSettingsKeepsComments(t *testing.T) {
+	for _, path := range []string{"settings.yaml", "settings.toml"} {
+		s := defaultSettings()
+		data, err := encodeSettings(path, s, nil)
+		if err != nil {
+			t.Fatalf("encodeSettings(%q) failed: %v", path, err)
+		}
+
+		// Comment the file as a user would
+		lines := []string{"# My settings"}
+		for _, line := range strings.Split(string(data), "\n") {
+			text := strings.TrimSpace(line)
+			switch {
+			case strings.HasPrefix(text, "master_volume"):
+				line += " # Not too loud"
+			case strings.HasPrefix(text, "issue_key"):
+				lines = append(lines, line[:len(line)-len(text)]+"# The current sprint")
+			}
+			lines = append(lines, line)
+		}
+		old := strings.Join(lines, "\n")
+
+		s.MasterVolume = 50
+		s.Jira.IssueKey = "ABC-1"
+		data, err = encodeSettings(path, s, []byte(old))
+		if err != nil {
+			t.Fatalf("encodeSettings(%q) failed: %v", path, err)
+		}
+		got := string(data)
+		for _, want := range []string{"# My settings\n", "master_volume", "50 # Not too loud\n", "# The current sprint\n", "ABC-1"} {
+			if !strings.Contains(got, want) {
+				t.Errorf("encodeSettings(%q) = %q, want it to contain %q", path, got, want)
+			}
+		}
+		var decoded TimerSettings
+		if data, err = settingsToJSON(path, data); err == nil {
+			err = json.Unmarshal(data, &decoded)
+		}
+		if err != nil {
+			t.Errorf("encodeSettings(%q) = %q, which cannot be decoded: %v", path, got, err)
+		} else if decoded.MasterVolume != 50 || decoded.Jira.IssueKey != "ABC-1" {
+			t.Errorf("encodeSettings(%q) = %q, want master_volume 50 and issue_key ABC-1", path, got)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/lutischan-ferenc/systray"
+)
+
+var (
+	settingsProblemsMu sync.Mutex
+	settingsProblems   []string          // Problems found in the settings file, with the fallback used
+	mSettingsProblems  *systray.MenuItem // Shown while the settings file has problems
+)
+
+// parseSettings parses settings JSON over the defaults and validates the
+// result. Fields with the wrong type or an invalid value fall back to the
+// default and are returned as problems. An error is returned if data is not
+// valid JSON, since then none of it can be used.
+func parseSettings(data []byte) (TimerSettings, []string, error) {
+	s := defaultSettings()
+	var problems []string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxErr):
+			line, column := lineColumn(data, syntaxErr.Offset)
+			return defaultSettings(), nil, fmt.Errorf("invalid JSON at line %d, column %d: %v", line, column, err)
+		case errors.As(err, &typeErr):
+			// Unmarshal skips the field and reports only the first such error.
+			problems = append(problems, fmt.Sprintf("%s: expected a %s, not a %s; using the default", typeErr.Field, typeErr.Type, typeErr.Value))
+		default:
+			return defaultSettings(), nil, err
+		}
+	}
+	return s, append(problems, validateSettings(&s)...), nil
+}
+
+// lineColumn returns the 1-based line and column of a byte offset in data.
+func lineColumn(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
+
+// validateSettings replaces values that make no sense with the defaults and
+// returns a description of each replacement. Numbers and choices are checked
+// against the limits of the settings form.
+func validateSettings(s *TimerSettings) []string {
+	var problems []string
+	defaults := defaultSettings()
+	for _, section := range settingsForm {
+		for _, field := range section.Fields {
+			v := settingsFieldValue(s, field.Key)
+			def := settingsFieldValue(&defaults, field.Key)
+			switch {
+			case v.Kind() == reflect.Int || v.Kind() == reflect.Float64:
+				n := v.Convert(reflect.TypeOf(float64(0))).Float()
+				if n < field.Min || n > field.Max {
+					problems = append(problems, fmt.Sprintf("%s: %v is not between %v and %v; using %v", field.Key, v.Interface(), field.Min, field.Max, def.Interface()))
+					v.Set(def)
+				}
+			case field.Options != nil && !containsString(field.Options, v.String()):
+				problems = append(problems, fmt.Sprintf("%s: %q is not one of %s; using %q", field.Key, v.String(), strings.Join(field.Options, ", "), def.String()))
+				v.Set(def)
+			case field.Sound && v.String() != "":
+				if _, err := os.Stat(v.String()); err != nil {
+					problems = append(problems, fmt.Sprintf("%s: %s was not found; using the built-in sound", field.Key, v.String()))
+				}
+			}
+		}
+	}
+
+	colors := []struct {
+		key   string
+		value *string
+	}{
+		{"icon_background", &s.IconBackground},
+		{"icon_text_color", &s.IconTextColor},
+		{"icon_dot_color", &s.IconDotColor},
+		{"icon_break_background", &s.IconBreakBackground},
+		{"icon_idle_background", &s.IconIdleBackground},
+	}
+	for _, c := range colors {
+		if *c.value == "" {
+			continue
+		}
+		if _, err := parseHexColor(*c.value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %q is not a hex color like #8B0000; using the theme's color", c.key, *c.value))
+			*c.value = ""
+		}
+	}
+	problems = append(problems, validateSchedule(s)...)
+	problems = append(problems, validateQuickTimers(s)...)
+	problems = append(problems, validateMenuLayout(s)...)
+	problems = append(problems, validateTooltipFormat(s)...)
+	problems = append(problems, validateHotkeys(&s.Hotkeys)...)
+	if s.API.Port < 1 || s.API.Port > 65535 {
+		problems = append(problems, fmt.Sprintf("api.port: %d is not between 1 and 65535; using %d", s.API.Port, defaultSettings().API.Port))
+		s.API.Port = defaultSettings().API.Port
+	}
+	problems = append(problems, validateMQTT(&s.MQTT)...)
+	problems = append(problems, validateHue(&s.Hue)...)
+	problems = append(problems, validateDistractions(&s.Distractions)...)
+	problems = append(problems, validateCalendar(&s.Calendar)...)
+	if _, ok := s.Profiles[s.Profile]; s.Profile != "" && !ok {
+		problems = append(problems, fmt.Sprintf("profile: %q does not exist; using no profile", s.Profile))
+		s.Profile = ""
+	}
+	return problems
+}
+
+// reportSettingsProblems records the problems found in the settings file,
+// shows or hides the menu indicator and notifies about new problems.
+func reportSettingsProblems(problems []string) {
+	settingsProblemsMu.Lock()
+	changed := strings.Join(problems, "\n") != strings.Join(settingsProblems, "\n")
+	settingsProblems = problems
+	settingsProblemsMu.Unlock()
+
+	updateSettingsProblemsMenu()
+	if !changed || len(problems) == 0 {
+		return
+	}
+	for _, problem := range problems {
+		slog.Warn("Settings problem", "problem", problem)
+	}
+	message := problems[0]
+	if len(problems) > 1 {
+		message += fmt.Sprintf(" (and %d more, see the menu)", len(problems)-1)
+	}
+	sendNotification(tr("Problem in the settings file"), message)
+}
+
+// updateSettingsProblemsMenu shows the menu indicator while there are problems.
+func updateSettingsProblemsMenu() {
+	if mSettingsProblems == nil {
+		return
+	}
+	settingsProblemsMu.Lock()
+	count := len(settingsProblems)
+	settingsProblemsMu.Unlock()
+	if count == 0 {
+		mSettingsProblems.Hide()
+		return
+	}
+	mSettingsProblems.SetTitle(fmt.Sprintf(tr("⚠ Settings Problems (%d)..."), count))
+	mSettingsProblems.Show()
+}
+
+// showSettingsProblems opens the list of settings problems in the text editor.
+func showSettingsProblems() {
+	settingsProblemsMu.Lock()
+	var report strings.Builder
+	report.WriteString("Problems in " + getSettingsPath() + ":\n\n")
+	for _, problem := range settingsProblems {
+		report.WriteString("- " + problem + "\n")
+	}
+	settingsProblemsMu.Unlock()
+	report.WriteString("\nFix them with Settings... or Edit Settings File...\n")
+
+	if _, err := editInEditor("pomodoro_settings_problems_*.txt", []byte(report.String())); err != nil {
+		slog.Error("Failed to show settings problems", "err", err)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// settingsReloadDelay waits for editors and sync tools to finish writing
+// before the settings file is read.
+const settingsReloadDelay = 300 * time.Millisecond
+
+// watchSettingsFile reloads the settings whenever the settings file changes.
+// The directory is watched, as many editors replace the file instead of writing it.
+func watchSettingsFile() {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		slog.Error("Failed to watch settings file", "err", err)
+		return
+	}
+	defer watcher.Close()
+
+	path := filepath.Clean(getSettingsPath())
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		slog.Error("Failed to watch settings file", "err", err)
+		return
+	}
+
+	var reload *time.Timer
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != path || event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			if reload != nil {
+				reload.Stop()
+			}
+			reload = time.AfterFunc(settingsReloadDelay, reloadSettings)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			slog.Error("Settings file watcher error", "err", err)
+		}
+	}
+}
+
+// reloadSettings applies the settings file if it differs from the current
+// settings, e.g. after it was edited outside the app.
+func reloadSettings() {
+	newSettings, problems, err := readSettingsFile()
+	if err != nil {
+		if !os.IsNotExist(err) {
+			reportSettingsProblems([]string{err.Error() + "; keeping the current settings"})
+		}
+		return
+	}
+	reportSettingsProblems(problems)
+	applyLaunchFlags(&newSettings)
+
+	updated, _ := json.Marshal(newSettings)
+	mu.Lock()
+	current, _ := json.Marshal(settings)
+	if bytes.Equal(current, updated) {
+		mu.Unlock()
+		return // Our own save, or no effective change
+	}
+	settings = newSettings
+	mu.Unlock()
+	slog.Info("Settings file changed, applying the new settings")
+	refreshSettings()
+}
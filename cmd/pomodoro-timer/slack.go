@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SlackSettings configures the Slack status and Do Not Disturb integration.
+type SlackSettings struct {
+	Token       string `json:"token"`        // User OAuth token (xoxp-...) with users.profile:write and dnd:write scopes
+	StatusEmoji string `json:"status_emoji"` // Emoji shown as status during a Pomodoro
+	StatusText  string `json:"status_text"`  // Status text, "%s" is replaced by the end time of the Pomodoro
+	EnableDND   bool   `json:"enable_dnd"`   // Snooze Slack notifications during a Pomodoro
+}
+
+const slackAPIURL = "https://slack.com/api/"
+
+var (
+	slackClient = &http.Client{Timeout: 15 * time.Second}
+	slackQueue  = newCallQueue("Slack") // Slack calls, so clearing the status never overtakes setting it
+)
+
+// slackFocusStarted sets the Slack status and enables Do Not Disturb for a Pomodoro.
+func slackFocusStarted(duration time.Duration) {
+	slack := settings.Slack
+	if slack.Token == "" {
+		return
+	}
+	end := time.Now().Add(duration)
+	slackQueue.enqueue(func() {
+		text := slack.StatusText
+		if strings.Contains(text, "%s") {
+			text = fmt.Sprintf(text, end.Format("15:04"))
+		}
+		profile := map[string]interface{}{
+			"status_text":       text,
+			"status_emoji":      slack.StatusEmoji,
+			"status_expiration": end.Unix(),
+		}
+		if err := slackCallJSON(slack.Token, "users.profile.set", map[string]interface{}{"profile": profile}); err != nil {
+			slog.Error("Failed to set Slack status", "err", err)
+		}
+		if slack.EnableDND {
+			minutes := int((duration + time.Minute - 1) / time.Minute)
+			if err := slackCallForm(slack.Token, "dnd.setSnooze", url.Values{"num_minutes": {fmt.Sprint(minutes)}}); err != nil {
+				slog.Error("Failed to enable Slack Do Not Disturb", "err", err)
+			}
+		}
+	})
+}
+
+// slackFocusEnded clears the Slack status and Do Not Disturb set for a Pomodoro.
+func slackFocusEnded() {
+	slack := settings.Slack
+	if slack.Token == "" {
+		return
+	}
+	slackQueue.enqueueRestore(func() {
+		profile := map[string]interface{}{
+			"status_text":       "",
+			"status_emoji":      "",
+			"status_expiration": 0,
+		}
+		if err := slackCallJSON(slack.Token, "users.profile.set", map[string]interface{}{"profile": profile}); err != nil {
+			slog.Error("Failed to clear Slack status", "err", err)
+		}
+		if slack.EnableDND {
+			if err := slackCallForm(slack.Token, "dnd.endSnooze", url.Values{}); err != nil {
+				slog.Error("Failed to end Slack Do Not Disturb", "err", err)
+			}
+		}
+	})
+}
+
+// slackCallJSON calls a Slack Web API method with a JSON body.
+func slackCallJSON(token, method string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, slackAPIURL+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	return slackDo(token, req)
+}
+
+// slackCallForm calls a Slack Web API method with form-encoded arguments.
+func slackCallForm(token, method string, args url.Values) error {
+	req, err := http.NewRequest(http.MethodPost, slackAPIURL+method, strings.NewReader(args.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return slackDo(token, req)
+}
+
+// slackDo sends a Slack Web API request and checks the "ok" field of the response.
+func slackDo(token string, req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := slackClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+	if !result.OK {
+		return fmt.Errorf("slack error: %s", result.Error)
+	}
+	return nil
+}
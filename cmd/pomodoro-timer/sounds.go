@@ -0,0 +1,221 @@
+package main
+
+import (
+	"log/slog"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lutischan-ferenc/systray"
+	"pomodoro-timer/internal/audio"
+)
+
+// Insistent alarm: the end sound starts quiet and gets louder with every repeat.
+const (
+	insistentAlarmPause       = 3 * time.Second  // Silence between repeats
+	insistentAlarmMaxDuration = 10 * time.Minute // Give up after this long without acknowledgement
+	insistentAlarmStartVolume = 0.3              // Fraction of the alarm volume of the first repeat
+	insistentAlarmVolumeStep  = 0.1              // Volume added with every repeat
+)
+
+var (
+	soundsMu          sync.Mutex   // Mutex for clockSound and ambientCache
+	clockSound        audio.Source // Clock sound played during Pomodoros
+	defaultClockSound audio.Source // Embedded clock sound, decoded while it plays
+
+	alarmMu     sync.Mutex    // Mutex for alarmStopCh
+	alarmStopCh chan struct{} // Closed to acknowledge the insistent alarm, nil if it is not sounding
+
+	pomodoroEndSoundPCM audio.Sound // Custom sound played when a Pomodoro ends, nil for the built-in melody
+	breakEndSoundPCM    audio.Sound // Custom sound played when a break ends, nil for the built-in melody
+
+	noSoundDevice atomic.Bool       // The sound device failed, so the sounds are off
+	mNoSound      *systray.MenuItem // Shown while there is no sound device
+)
+
+// Built-in melodies: a descending chime signals the end of focus time, an
+// ascending one calls you back to work, and a short rising pair warns before the end.
+var (
+	pomodoroEndMelody = []audio.Note{
+		{Freq: 1047, Duration: 180 * time.Millisecond},
+		{Freq: 784, Duration: 180 * time.Millisecond},
+		{Freq: 659, Duration: 360 * time.Millisecond},
+	}
+	breakEndMelody = []audio.Note{
+		{Freq: 659, Duration: 150 * time.Millisecond},
+		{Freq: 784, Duration: 150 * time.Millisecond},
+		{Freq: 1047, Duration: 150 * time.Millisecond},
+		{Freq: 0, Duration: 80 * time.Millisecond},
+		{Freq: 1047, Duration: 300 * time.Millisecond},
+	}
+	warningMelody = []audio.Note{
+		{Freq: 660, Duration: 250 * time.Millisecond},
+		{Freq: 880, Duration: 250 * time.Millisecond},
+	}
+	intervalChimeMelody = []audio.Note{
+		{Freq: 1319, Duration: 150 * time.Millisecond},
+	}
+)
+
+// initAudio prepares the player at the sample rate of the embedded clock
+// sound, which is decoded while it plays. The sound device is opened when the first sound plays; without
+// one the sounds are skipped and the menu says so.
+func initAudio() {
+	sampleRate := audio.DefaultSampleRate
+	if rate, err := audio.MP3SampleRate(clockSoundMP3); err != nil {
+		slog.Error("Failed to decode the clock sound", "err", err)
+	} else {
+		sampleRate = rate
+	}
+	sound, err := audio.MP3Stream(clockSoundMP3, sampleRate)
+	if err != nil {
+		slog.Error("Failed to decode the clock sound", "err", err)
+		sound = audio.Sound(nil)
+	}
+	clockSound = sound
+	defaultClockSound = sound
+
+	audioPlayer = audio.Lazy(sampleRate, audioFailed)
+	audioPlayer.SetVolume(alarmGain, clockGain)
+}
+
+// audioFailed records that the sound device cannot be used, so the sounds
+// are off until the app restarts.
+func audioFailed(err error) {
+	slog.Error("Failed to open the sound device, sounds are off", "err", err)
+	noSoundDevice.Store(true)
+	updateNoSoundMenu()
+}
+
+// addNoSoundMenu adds the indicator shown while there is no sound device.
+func addNoSoundMenu() {
+	mNoSound = systray.AddMenuItem(tr("⚠ No Sound Device"), tr("Sounds are off until the app restarts, see the log file"))
+	mNoSound.Click(func() {
+		openLogFile()
+	})
+	updateNoSoundMenu()
+}
+
+// updateNoSoundMenu shows the indicator if the sound device failed.
+func updateNoSoundMenu() {
+	if mNoSound == nil {
+		return
+	}
+	if noSoundDevice.Load() {
+		mNoSound.Show()
+	} else {
+		mNoSound.Hide()
+	}
+}
+
+// decodeSoundFile decodes an audio file at the sample rate of the player.
+func decodeSoundFile(path string) (audio.Sound, error) {
+	return audio.DecodeFile(path, audioPlayer.SampleRate())
+}
+
+// loadCustomSounds decodes the sound files configured in the settings. Sounds
+// that are not configured or fail to load fall back to the built-in ones.
+func loadCustomSounds() {
+	load := func(path, name string) audio.Sound {
+		if path == "" {
+			return nil
+		}
+		pcm, err := decodeSoundFile(path)
+		if err != nil {
+			slog.Error("Failed to load sound", "sound", name, "path", path, "err", err)
+			return nil
+		}
+		return pcm
+	}
+
+	clock := defaultClockSound
+	if path := settings.ClockSoundPath; path != "" {
+		if sound, err := audio.StreamFile(path, audioPlayer.SampleRate()); err != nil {
+			slog.Error("Failed to load sound", "sound", "clock", "path", path, "err", err)
+		} else {
+			clock = sound
+		}
+	}
+	soundsMu.Lock()
+	clockSound = clock
+	soundsMu.Unlock()
+
+	pomodoroEndSoundPCM = load(settings.PomodoroEndSoundPath, "Pomodoro end")
+	breakEndSoundPCM = load(settings.BreakEndSoundPath, "break end")
+}
+
+// playTickSound plays a short beep, e.g. in the final countdown.
+func playTickSound() {
+	freq := settings.FinalCountdownFrequency // Frequency of the sound in Hz
+	if freq <= 0 {
+		freq = 440.0 // A4 note
+	}
+	audioPlayer.Play(audio.Tone(freq, 200*time.Millisecond, 0.3, audioPlayer.SampleRate()))
+}
+
+// playWarningChime plays a rising two-tone chime, distinct from the tick beep.
+func playWarningChime() {
+	audioPlayer.Play(audio.Melody(warningMelody, 0.3, audioPlayer.SampleRate()))
+}
+
+// playIntervalChime plays a short, soft chime marking the passing minutes.
+func playIntervalChime() {
+	audioPlayer.Play(audio.Melody(intervalChimeMelody, 0.12, audioPlayer.SampleRate()))
+}
+
+// playEndSound plays the sound for the end of a Pomodoro or a break: the
+// configured sound file, or a built-in melody that differs for Pomodoros and breaks.
+func playEndSound(wasPomodoro bool) {
+	pcm, melody := breakEndSoundPCM, breakEndMelody
+	if wasPomodoro {
+		pcm, melody = pomodoroEndSoundPCM, pomodoroEndMelody
+	}
+	if pcm == nil {
+		pcm = audio.Melody(melody, 0.3, audioPlayer.SampleRate())
+	}
+	if settings.InsistentAlarm {
+		playInsistentAlarm(pcm)
+	} else {
+		audioPlayer.Play(pcm)
+	}
+}
+
+// playInsistentAlarm repeats the sound with slowly increasing volume until the
+// alarm is acknowledged or insistentAlarmMaxDuration has passed.
+func playInsistentAlarm(pcm audio.Sound) {
+	alarmMu.Lock()
+	if alarmStopCh != nil {
+		close(alarmStopCh)
+	}
+	stop := make(chan struct{})
+	alarmStopCh = stop
+	alarmMu.Unlock()
+
+	deadline := time.Now().Add(insistentAlarmMaxDuration)
+	for scale := insistentAlarmStartVolume; time.Now().Before(deadline); scale = math.Min(1, scale+insistentAlarmVolumeStep) {
+		audioPlayer.Play(pcm.Scaled(scale))
+		select {
+		case <-stop:
+			return
+		case <-time.After(insistentAlarmPause):
+		}
+	}
+
+	alarmMu.Lock()
+	if alarmStopCh == stop {
+		alarmStopCh = nil
+	}
+	alarmMu.Unlock()
+}
+
+// acknowledgeAlarm silences the insistent alarm if it is sounding.
+func acknowledgeAlarm() {
+	alarmMu.Lock()
+	defer alarmMu.Unlock()
+
+	if alarmStopCh != nil {
+		close(alarmStopCh)
+		alarmStopCh = nil
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sessionStats aggregates the Pomodoros of a group of sessions.
+type sessionStats struct {
+	pomodoros             int           // Number of completed Pomodoros
+	stopped               int           // Number of Pomodoros stopped early
+	focus                 time.Duration // Time spent in completed Pomodoros
+	internalInterruptions int
+	externalInterruptions int
+}
+
+// add adds a session record to the statistics. Breaks are ignored.
+func (s *sessionStats) add(record SessionRecord) {
+	if record.Type != sessionPomodoro {
+		return
+	}
+	if record.Completed {
+		s.pomodoros++
+		s.focus += record.End.Sub(record.Start)
+	} else {
+		s.stopped++
+	}
+	for _, interruption := range record.Interruptions {
+		if interruption.Kind == interruptionExternal {
+			s.externalInterruptions++
+		} else {
+			s.internalInterruptions++
+		}
+	}
+}
+
+// String formats the statistics as a single report line.
+func (s sessionStats) String() string {
+	return fmt.Sprintf("%d pomodoros, %s focus, %d stopped, %d internal / %d external interruptions",
+		s.pomodoros, formatFocusTime(s.focus), s.stopped, s.internalInterruptions, s.externalInterruptions)
+}
+
+// formatFocusTime formats a duration as hours and minutes, e.g. "2h05m".
+func formatFocusTime(d time.Duration) string {
+	minutes := int(d.Round(time.Minute).Minutes())
+	return fmt.Sprintf("%dh%02dm", minutes/60, minutes%60)
+}
+
+// buildStatisticsReport builds a text report of the session history broken down by tag.
+func buildStatisticsReport(records []SessionRecord, now time.Time) string {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	periods := []struct {
+		title string
+		since time.Time
+	}{
+		{"Today", today},
+		{"Last 7 days", today.AddDate(0, 0, -6)},
+		{"All time", time.Time{}},
+	}
+
+	var sb strings.Builder
+	for _, period := range periods {
+		var total sessionStats
+		byTag := map[string]*sessionStats{}
+		for _, record := range records {
+			if record.Start.Before(period.since) {
+				continue
+			}
+			total.add(record)
+			if record.Type != sessionPomodoro {
+				continue
+			}
+			tag := record.Tag
+			if byTag[tag] == nil {
+				byTag[tag] = &sessionStats{}
+			}
+			byTag[tag].add(record)
+		}
+
+		fmt.Fprintf(&sb, "%s\n", period.title)
+		fmt.Fprintf(&sb, "  Total: %s\n", total)
+
+		tags := make([]string, 0, len(byTag))
+		for tag := range byTag {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
+		for _, tag := range tags {
+			name := "(untagged)"
+			if tag != "" {
+				name = "#" + tag
+			}
+			fmt.Fprintf(&sb, "  %s: %s\n", name, byTag[tag])
+		}
+		sb.WriteString("\n")
+	}
+
+	byIssue := map[string]*sessionStats{}
+	for _, record := range records {
+		if issue, ok := parseGitHubIssue(record.Task); ok && record.Type == sessionPomodoro {
+			if byIssue[issue.String()] == nil {
+				byIssue[issue.String()] = &sessionStats{}
+			}
+			byIssue[issue.String()].add(record)
+		}
+	}
+	if len(byIssue) > 0 {
+		issues := make([]string, 0, len(byIssue))
+		for issue := range byIssue {
+			issues = append(issues, issue)
+		}
+		sort.Strings(issues)
+		sb.WriteString("GitHub issues, all time\n")
+		for _, issue := range issues {
+			fmt.Fprintf(&sb, "  %s: %s\n", issue, byIssue[issue])
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// openStatistics shows the statistics report in the default text editor.
+func openStatistics() {
+	records, err := loadHistory()
+	if err != nil {
+		slog.Error("Failed to load history", "err", err)
+		return
+	}
+
+	report := buildStatisticsReport(records, time.Now())
+	if _, err := editInEditor("pomodoro_statistics_*.txt", []byte(report)); err != nil {
+		slog.Error("Failed to show statistics", "err", err)
+	}
+}
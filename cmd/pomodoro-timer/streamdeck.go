@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"pomodoro-timer/internal/icon"
+)
+
+const streamDeckKeySize = 144 // Size of a Stream Deck key image in pixels, at high DPI
+
+// keyImage draws the state of the timer for a button such as a Stream Deck
+// key: a progress ring with the remaining time as m:ss, or ▶ while stopped.
+func keyImage(size int) []byte {
+	mu.Lock()
+	defer mu.Unlock()
+	text := "▶"
+	progress := 0.0
+	if engine.Running() {
+		text = fmt.Sprintf("%d:%02d", int(engine.Remaining().Minutes()), int(engine.Remaining().Seconds())%60)
+		if engine.Duration() > 0 {
+			progress = float64(engine.Remaining()) / float64(engine.Duration())
+		}
+	}
+	return iconRenderer.PNG(icon.Spec{Style: icon.Ring, Palette: currentPalette(), Size: size, Text: text, Dots: engine.Count(), Progress: progress})
+}
+
+// handleAPIImage returns the key image as PNG. "size" sets its size in
+// pixels, and "format=base64" returns it as a data URL for plugins that
+// expect text.
+func handleAPIImage(w http.ResponseWriter, r *http.Request) {
+	size := streamDeckKeySize
+	if s := r.URL.Query().Get("size"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 16 || n > 512 {
+			writeAPIJSON(w, http.StatusBadRequest, map[string]string{"error": "size must be between 16 and 512"})
+			return
+		}
+		size = n
+	}
+	data := keyImage(size)
+
+	w.Header().Set("Cache-Control", "no-store")
+	if r.URL.Query().Get("format") == "base64" {
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, "data:image/png;base64,"+base64.StdEncoding.EncodeToString(data))
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(data)
+}
+
+// handleAPIToggle starts the next session or stops the running one, like a
+// click on the tray icon.
+func handleAPIToggle(w http.ResponseWriter, r *http.Request) {
+	handleTrayClick()
+	writeAPIStatus(w, runControlCommand([]string{"status"}))
+}
+
+// handleAPIPauseToggle pauses the running session or resumes the paused one.
+func handleAPIPauseToggle(w http.ResponseWriter, r *http.Request) {
+	mu.Lock()
+	command := "pause"
+	if engine.Paused() {
+		command = "resume"
+	}
+	mu.Unlock()
+	writeAPIStatus(w, runControlCommand([]string{command}))
+}
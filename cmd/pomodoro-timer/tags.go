@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/lutischan-ferenc/systray"
+)
+
+const maxTagMenuItems = 10
+
+var (
+	mTags     *systray.MenuItem   // Submenu showing the current tag
+	mTagNone  *systray.MenuItem   // Menu item for untagged sessions
+	mTagItems []*systray.MenuItem // Menu items for the configured tags
+	tagKeys   []string            // Tags currently shown in mTagItems
+)
+
+// normalizeTag strips the leading '#' and surrounding spaces from a tag.
+func normalizeTag(tag string) string {
+	return strings.TrimPrefix(strings.TrimSpace(tag), "#")
+}
+
+// addTagMenu adds the session tag submenu to the system tray.
+func addTagMenu() {
+	mTags = systray.AddMenuItem(tr("Tag"), tr("Tag the current and following sessions"))
+	mTagEdit := mTags.AddSubMenuItem(tr("Edit Tags..."), tr("Edit the list of tags"))
+	mTagEdit.Click(func() {
+		openTagsEditor()
+	})
+	mTagNone = mTags.AddSubMenuItemCheckbox(tr("No Tag"), tr("Do not tag sessions"), false)
+	mTagNone.Click(func() {
+		selectTag("")
+	})
+
+	tagKeys = make([]string, maxTagMenuItems)
+	for i := 0; i < maxTagMenuItems; i++ {
+		slot := i
+		item := mTags.AddSubMenuItemCheckbox("", tr("Tag sessions with this tag"), false)
+		item.Click(func() {
+			if tagKeys[slot] != "" {
+				selectTag(tagKeys[slot])
+			}
+		})
+		item.Hide()
+		mTagItems = append(mTagItems, item)
+	}
+	updateTagMenu()
+}
+
+// updateTagMenu refreshes the tag submenu from the current settings.
+func updateTagMenu() {
+	if mTags == nil {
+		return
+	}
+	current := settings.CurrentTag
+	if current == "" {
+		mTags.SetTitle(tr("Tag: none"))
+		mTagNone.Check()
+	} else {
+		mTags.SetTitle(fmt.Sprintf(tr("Tag: #%s"), current))
+		mTagNone.Uncheck()
+	}
+
+	for i, item := range mTagItems {
+		if i >= len(settings.Tags) {
+			tagKeys[i] = ""
+			item.Hide()
+			continue
+		}
+		tag := normalizeTag(settings.Tags[i])
+		tagKeys[i] = tag
+		item.SetTitle("#" + tag)
+		if tag == current {
+			item.Check()
+		} else {
+			item.Uncheck()
+		}
+		item.Show()
+	}
+}
+
+// selectTag makes the given tag the current one and applies it to the running session.
+func selectTag(tag string) {
+	mu.Lock()
+	settings.CurrentTag = tag
+	if currentSession != nil {
+		currentSession.Tag = tag
+	}
+	saveSettings()
+	mu.Unlock()
+	updateTagMenu()
+}
+
+// openTagsEditor opens the tag list in the default text editor, one tag per line.
+func openTagsEditor() {
+	var sb strings.Builder
+	sb.WriteString("# One tag per line, e.g. #coding\n")
+	for _, tag := range currentSettings().Tags {
+		fmt.Fprintf(&sb, "#%s\n", normalizeTag(tag))
+	}
+
+	data, err := editInEditor("pomodoro_tags_*.txt", []byte(sb.String()))
+	if err != nil {
+		slog.Error("Failed to edit tags", "err", err)
+		return
+	}
+
+	var newTags []string
+	seen := map[string]bool{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "# ") {
+			continue
+		}
+		tag := normalizeTag(line)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		newTags = append(newTags, tag)
+	}
+
+	mu.Lock()
+	settings.Tags = newTags
+	if settings.CurrentTag != "" && !seen[settings.CurrentTag] {
+		settings.CurrentTag = ""
+	}
+	saveSettings()
+	mu.Unlock()
+	updateTagMenu()
+}
@@ -0,0 +1,26 @@
+package main
+
+// Taskbar progress states.
+const (
+	taskbarIdle     = iota // No progress bar
+	taskbarPomodoro        // Green progress bar
+	taskbarBreak           // Yellow progress bar
+)
+
+// updateTaskbarProgress shows the progress of the running session on the
+// taskbar, if enabled. The caller must hold mu.
+func updateTaskbarProgress() {
+	if launch.headless {
+		return
+	}
+	state := taskbarIdle
+	fraction := 0.0
+	if settings.TaskbarProgress && engine.Running() && engine.Duration() > 0 {
+		state = taskbarBreak
+		if engine.InPomodoro() {
+			state = taskbarPomodoro
+		}
+		fraction = 1 - float64(engine.Remaining())/float64(engine.Duration())
+	}
+	setTaskbarProgress(state, fraction)
+}
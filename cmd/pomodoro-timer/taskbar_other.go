@@ -0,0 +1,7 @@
+//go:build !windows
+
+package main
+
+// setTaskbarProgress shows session progress on the taskbar.
+// Taskbar progress is only supported on Windows.
+func setTaskbarProgress(state int, fraction float64) {}
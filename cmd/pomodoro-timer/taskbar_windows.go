@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	user32 = windows.NewLazySystemDLL("user32.dll")
+	ole32  = windows.NewLazySystemDLL("ole32.dll")
+
+	procRegisterClassExW       = user32.NewProc("RegisterClassExW")
+	procCreateWindowExW        = user32.NewProc("CreateWindowExW")
+	procDefWindowProcW         = user32.NewProc("DefWindowProcW")
+	procShowWindow             = user32.NewProc("ShowWindow")
+	procGetMessageW            = user32.NewProc("GetMessageW")
+	procTranslateMessage       = user32.NewProc("TranslateMessage")
+	procDispatchMessageW       = user32.NewProc("DispatchMessageW")
+	procPostMessageW           = user32.NewProc("PostMessageW")
+	procRegisterWindowMessageW = user32.NewProc("RegisterWindowMessageW")
+	procCoCreateInstance       = ole32.NewProc("CoCreateInstance")
+
+	clsidTaskbarList = windows.GUID{Data1: 0x56FDF344, Data2: 0xFD6D, Data3: 0x11D0, Data4: [8]byte{0x95, 0x8A, 0x00, 0x60, 0x97, 0xC9, 0xA0, 0x90}}
+	iidTaskbarList3  = windows.GUID{Data1: 0xEA1AFB91, Data2: 0x9E28, Data3: 0x4B86, Data4: [8]byte{0x90, 0xE9, 0x9E, 0x9F, 0x8A, 0x5E, 0xEF, 0xAF}}
+
+	taskbarButtonCreatedMessage uint32 // Sent when the taskbar button is (re)created, e.g. after Explorer restarts
+)
+
+// Window messages, commands and ITaskbarList3 constants.
+const (
+	wmClose            = 0x0010
+	wmSysCommand       = 0x0112
+	wmApp              = 0x8000
+	scRestore          = 0xF120
+	scMaximize         = 0xF030
+	swHide             = 0
+	swShowMinNoActive  = 7
+	wsOverlappedWindow = 0x00CF0000
+	wsExAppWindow      = 0x00040000
+	clsctxInproc       = 0x1
+
+	tbpfNormal = 0x2 // Green
+	tbpfPaused = 0x8 // Yellow
+
+	// ITaskbarList3 vtable indexes
+	vtblRelease          = 2
+	vtblHrInit           = 3
+	vtblSetProgressValue = 9
+	vtblSetProgressState = 10
+)
+
+// progressTotal is the resolution of the taskbar progress bar.
+const progressTotal = 1000
+
+var (
+	taskbarMu        sync.Mutex
+	taskbarState     int     // Requested state, guarded by taskbarMu
+	taskbarFraction  float64 // Requested progress, guarded by taskbarMu
+	taskbarDismissed bool    // The button was closed during this session, guarded by taskbarMu
+	taskbarHwnd      uintptr // Window owning the taskbar button, 0 until created
+	taskbarOnce      sync.Once
+
+	taskbarList  *comObject // ITaskbarList3, only used on the window thread
+	taskbarShown bool       // The window is visible, only used on the window thread
+)
+
+// comObject is a raw COM interface pointer.
+type comObject struct {
+	vtbl *[16]uintptr
+}
+
+// call calls the method at the vtable index.
+func (o *comObject) call(index int, args ...uintptr) uintptr {
+	ret, _, _ := syscall.SyscallN(o.vtbl[index], append([]uintptr{uintptr(unsafe.Pointer(o))}, args...)...)
+	return ret
+}
+
+// ulonglong splits a 64-bit value into call arguments for the platform.
+func ulonglong(v uint64) []uintptr {
+	if unsafe.Sizeof(uintptr(0)) == 4 {
+		return []uintptr{uintptr(v), uintptr(v >> 32)}
+	}
+	return []uintptr{uintptr(v)}
+}
+
+// setTaskbarProgress shows session progress on the button of a minimized
+// window, as a tray icon has no taskbar button of its own. The window is
+// created on first use and hidden while no session runs.
+func setTaskbarProgress(state int, fraction float64) {
+	taskbarMu.Lock()
+	changed := state != taskbarState || int(fraction*progressTotal) != int(taskbarFraction*progressTotal)
+	taskbarState, taskbarFraction = state, fraction
+	if state == taskbarIdle {
+		taskbarDismissed = false
+	}
+	hwnd := taskbarHwnd
+	taskbarMu.Unlock()
+
+	if state == taskbarIdle && hwnd == 0 {
+		return
+	}
+	taskbarOnce.Do(func() {
+		go runTaskbarWindow()
+	})
+	if changed && hwnd != 0 {
+		procPostMessageW.Call(hwnd, wmApp, 0, 0)
+	}
+}
+
+// runTaskbarWindow creates the taskbar window and runs its message loop.
+// The COM object lives on the same thread.
+func runTaskbarWindow() {
+	runtime.LockOSThread()
+	if err := windows.CoInitializeEx(0, windows.COINIT_APARTMENTTHREADED); err != nil {
+		slog.Error("Failed to initialize COM", "err", err)
+		return
+	}
+	defer windows.CoUninitialize()
+
+	var list *comObject
+	ret, _, _ := procCoCreateInstance.Call(uintptr(unsafe.Pointer(&clsidTaskbarList)), 0, clsctxInproc,
+		uintptr(unsafe.Pointer(&iidTaskbarList3)), uintptr(unsafe.Pointer(&list)))
+	if ret != 0 || list == nil {
+		slog.Error("Failed to create taskbar list", "hresult", fmt.Sprintf("0x%x", ret))
+		return
+	}
+	defer list.call(vtblRelease)
+	list.call(vtblHrInit)
+	taskbarList = list
+
+	name, _ := windows.UTF16PtrFromString("Pomodoro Timer")
+	className, _ := windows.UTF16PtrFromString("PomodoroTimerTaskbar")
+	buttonCreated, _ := windows.UTF16PtrFromString("TaskbarButtonCreated")
+	msg, _, _ := procRegisterWindowMessageW.Call(uintptr(unsafe.Pointer(buttonCreated)))
+	taskbarButtonCreatedMessage = uint32(msg)
+
+	wc := struct {
+		Size       uint32
+		Style      uint32
+		WndProc    uintptr
+		ClsExtra   int32
+		WndExtra   int32
+		Instance   windows.Handle
+		Icon       windows.Handle
+		Cursor     windows.Handle
+		Background windows.Handle
+		MenuName   *uint16
+		ClassName  *uint16
+		IconSm     windows.Handle
+	}{WndProc: windows.NewCallback(taskbarWndProc), ClassName: className}
+	wc.Size = uint32(unsafe.Sizeof(wc))
+	if ret, _, err := procRegisterClassExW.Call(uintptr(unsafe.Pointer(&wc))); ret == 0 {
+		slog.Error("Failed to register taskbar window class", "err", err)
+		return
+	}
+	hwnd, _, err := procCreateWindowExW.Call(wsExAppWindow, uintptr(unsafe.Pointer(className)), uintptr(unsafe.Pointer(name)),
+		wsOverlappedWindow, 0, 0, 0, 0, 0, 0, 0, 0)
+	if hwnd == 0 {
+		slog.Error("Failed to create taskbar window", "err", err)
+		return
+	}
+	taskbarMu.Lock()
+	taskbarHwnd = hwnd
+	taskbarMu.Unlock()
+	applyTaskbarProgress(hwnd)
+
+	var m struct {
+		Hwnd    uintptr
+		Message uint32
+		WParam  uintptr
+		LParam  uintptr
+		Time    uint32
+		Pt      struct{ X, Y int32 }
+	}
+	for {
+		ret, _, _ := procGetMessageW.Call(uintptr(unsafe.Pointer(&m)), 0, 0, 0)
+		if int32(ret) <= 0 {
+			return
+		}
+		procTranslateMessage.Call(uintptr(unsafe.Pointer(&m)))
+		procDispatchMessageW.Call(uintptr(unsafe.Pointer(&m)))
+	}
+}
+
+// taskbarWndProc handles the messages of the taskbar window. The window stays
+// minimized; closing it hides the progress until the next session.
+func taskbarWndProc(hwnd, msg, wParam, lParam uintptr) uintptr {
+	switch {
+	case msg == wmApp || (taskbarButtonCreatedMessage != 0 && msg == uintptr(taskbarButtonCreatedMessage)):
+		applyTaskbarProgress(hwnd)
+		return 0
+	case msg == wmSysCommand && (wParam&0xFFF0 == scRestore || wParam&0xFFF0 == scMaximize):
+		return 0
+	case msg == wmClose:
+		taskbarMu.Lock()
+		taskbarDismissed = true
+		taskbarMu.Unlock()
+		applyTaskbarProgress(hwnd)
+		return 0
+	}
+	ret, _, _ := procDefWindowProcW.Call(hwnd, msg, wParam, lParam)
+	return ret
+}
+
+// applyTaskbarProgress shows the requested state on the taskbar button.
+// It runs on the window thread.
+func applyTaskbarProgress(hwnd uintptr) {
+	taskbarMu.Lock()
+	state, fraction, dismissed := taskbarState, taskbarFraction, taskbarDismissed
+	taskbarMu.Unlock()
+
+	visible := state != taskbarIdle && !dismissed
+	if visible != taskbarShown {
+		if visible {
+			procShowWindow.Call(hwnd, swShowMinNoActive)
+		} else {
+			procShowWindow.Call(hwnd, swHide)
+		}
+		taskbarShown = visible
+	}
+	if !visible || taskbarList == nil {
+		return
+	}
+
+	flag := uintptr(tbpfNormal)
+	if state == taskbarBreak {
+		flag = tbpfPaused
+	}
+	taskbarList.call(vtblSetProgressState, hwnd, flag)
+	args := append([]uintptr{hwnd}, ulonglong(uint64(fraction*progressTotal))...)
+	args = append(args, ulonglong(progressTotal)...)
+	taskbarList.call(vtblSetProgressValue, args...)
+}
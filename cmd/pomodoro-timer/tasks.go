@@ -0,0 +1,287 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lutischan-ferenc/systray"
+)
+
+// Task is a piece of work that Pomodoros are spent on.
+type Task struct {
+	Name      string `json:"name"`            // Name of the task
+	Estimate  int    `json:"estimate"`        // Estimated number of Pomodoros, 0 if not estimated
+	Completed int    `json:"completed"`       // Number of Pomodoros completed on the task
+	Title     string `json:"title,omitempty"` // Title of the GitHub issue or pull request the name links to
+}
+
+// TaskList stores the tasks and the one currently worked on.
+type TaskList struct {
+	Current string `json:"current"` // Name of the selected task, empty if none
+	Tasks   []Task `json:"tasks"`
+}
+
+const maxTaskMenuItems = 10
+
+var (
+	tasks   TaskList   // Stores the task list
+	tasksMu sync.Mutex // Mutex for the task list
+
+	mTasks     *systray.MenuItem   // Submenu showing the current task
+	mTaskNone  *systray.MenuItem   // Menu item for working without a task
+	mTaskItems []*systray.MenuItem // Menu items for the tasks
+)
+
+// getTasksPath returns the path to the task list file.
+func getTasksPath() string {
+	return getDataFilePath("tasks.json")
+}
+
+// loadTasks loads the task list from a file.
+func loadTasks() {
+	tasksMu.Lock()
+	defer tasksMu.Unlock()
+
+	data, err := ioutil.ReadFile(getTasksPath())
+	if err == nil {
+		err = json.Unmarshal(data, &tasks)
+		if err != nil {
+			slog.Error("Failed to load tasks", "err", err)
+		}
+	}
+}
+
+// saveTasks saves the task list to a file. The caller must hold tasksMu.
+func saveTasks() {
+	data, err := json.MarshalIndent(tasks, "", "  ")
+	if err != nil {
+		slog.Error("Failed to save tasks", "err", err)
+		return
+	}
+	err = ioutil.WriteFile(getTasksPath(), data, 0644)
+	if err != nil {
+		slog.Error("Failed to write tasks file", "err", err)
+	}
+}
+
+// findTask returns the task with the given name. The caller must hold tasksMu.
+func findTask(name string) *Task {
+	for i := range tasks.Tasks {
+		if tasks.Tasks[i].Name == name {
+			return &tasks.Tasks[i]
+		}
+	}
+	return nil
+}
+
+// progress returns the task progress, e.g. "2/4", or "2" if the task has no estimate.
+func (t Task) progress() string {
+	if t.Estimate > 0 {
+		return fmt.Sprintf("%d/%d", t.Completed, t.Estimate)
+	}
+	return strconv.Itoa(t.Completed)
+}
+
+// overEstimate reports whether more Pomodoros were spent on the task than estimated.
+func (t Task) overEstimate() bool {
+	return t.Estimate > 0 && t.Completed > t.Estimate
+}
+
+// currentTaskName returns the name of the selected task.
+func currentTaskName() string {
+	tasksMu.Lock()
+	defer tasksMu.Unlock()
+	return tasks.Current
+}
+
+// taskProgressText returns the tooltip suffix describing the progress of a
+// task, with a note when the estimate is exceeded.
+func taskProgressText(name string) string {
+	if name == "" {
+		return ""
+	}
+	tasksMu.Lock()
+	defer tasksMu.Unlock()
+
+	task := findTask(name)
+	if task == nil {
+		return ""
+	}
+	text := fmt.Sprintf(" - %s (%s)", task.Name, task.progress())
+	if task.overEstimate() {
+		text += tr(", over estimate")
+	}
+	return text
+}
+
+// tasksPomodoroCompleted records a completed Pomodoro on its task, with a
+// notification when the estimate is exceeded.
+func tasksPomodoroCompleted(s sessionInfo) {
+	if s.record.Task == "" {
+		return
+	}
+	tasksMu.Lock()
+	task := findTask(s.record.Task)
+	if task == nil {
+		tasksMu.Unlock()
+		return
+	}
+	task.Completed++
+	if task.overEstimate() {
+		go sendNotification(tr("Task over estimate"),
+			fmt.Sprintf(tr("%s took %d Pomodoros, estimated %d"), task.Name, task.Completed, task.Estimate))
+	}
+	saveTasks()
+	tasksMu.Unlock()
+
+	updateTaskMenu()
+}
+
+// addTaskMenu adds the task submenu to the system tray.
+func addTaskMenu() {
+	mTasks = systray.AddMenuItem(tr("Task"), tr("Select the task to work on"))
+	mTaskEdit := mTasks.AddSubMenuItem(tr("Edit Tasks..."), tr("Edit the task list and estimates"))
+	mTaskEdit.Click(func() {
+		openTasksEditor()
+	})
+	mTaskGitHub := mTasks.AddSubMenuItem(tr("Work on GitHub Issue..."), tr("Paste the URL of a GitHub issue or pull request"))
+	mTaskGitHub.Click(func() {
+		promptGitHubIssue()
+	})
+	mTaskNone = mTasks.AddSubMenuItemCheckbox(tr("No Task"), tr("Work without a task"), false)
+	mTaskNone.Click(func() {
+		selectTask("")
+	})
+
+	for i := 0; i < maxTaskMenuItems; i++ {
+		slot := i
+		item := mTasks.AddSubMenuItemCheckbox("", tr("Work on this task"), false)
+		item.Click(func() {
+			tasksMu.Lock()
+			name := ""
+			if slot < len(tasks.Tasks) {
+				name = tasks.Tasks[slot].Name
+			}
+			tasksMu.Unlock()
+			if name != "" {
+				selectTask(name)
+			}
+		})
+		item.Hide()
+		mTaskItems = append(mTaskItems, item)
+	}
+	updateTaskMenu()
+}
+
+// updateTaskMenu refreshes the task submenu from the task list.
+func updateTaskMenu() {
+	if mTasks == nil {
+		return
+	}
+	tasksMu.Lock()
+	defer tasksMu.Unlock()
+
+	if task := findTask(tasks.Current); task != nil {
+		mTasks.SetTitle(fmt.Sprintf(tr("Task: %s (%s)"), taskLabel(*task), task.progress()))
+		mTaskNone.Uncheck()
+	} else {
+		mTasks.SetTitle(tr("Task: none"))
+		mTaskNone.Check()
+	}
+
+	for i, item := range mTaskItems {
+		if i >= len(tasks.Tasks) {
+			item.Hide()
+			continue
+		}
+		task := tasks.Tasks[i]
+		title := fmt.Sprintf("%s (%s)", taskLabel(task), task.progress())
+		if task.overEstimate() {
+			title += tr(" - over estimate")
+		}
+		item.SetTitle(title)
+		if task.Name == tasks.Current {
+			item.Check()
+		} else {
+			item.Uncheck()
+		}
+		item.Show()
+	}
+}
+
+// selectTask makes the named task the current one. An empty name clears the selection.
+func selectTask(name string) {
+	tasksMu.Lock()
+	tasks.Current = name
+	saveTasks()
+	tasksMu.Unlock()
+	updateTaskMenu()
+}
+
+// openTasksEditor opens the task list in the default text editor, one
+// "name: estimate" line per task.
+func openTasksEditor() {
+	tasksMu.Lock()
+	var sb strings.Builder
+	sb.WriteString("# One task per line, optionally followed by the estimated Pomodoros, e.g.\n")
+	sb.WriteString("# write report: 4\n")
+	for _, task := range tasks.Tasks {
+		if task.Estimate > 0 {
+			fmt.Fprintf(&sb, "%s: %d\n", task.Name, task.Estimate)
+		} else {
+			fmt.Fprintf(&sb, "%s\n", task.Name)
+		}
+	}
+	tasksMu.Unlock()
+
+	data, err := editInEditor("pomodoro_tasks_*.txt", []byte(sb.String()))
+	if err != nil {
+		slog.Error("Failed to edit tasks", "err", err)
+		return
+	}
+
+	tasksMu.Lock()
+	var newTasks []Task
+	for _, line := range strings.Split(string(data), "\n") {
+		task, ok := parseTaskLine(line)
+		if !ok {
+			continue
+		}
+		if old := findTask(task.Name); old != nil {
+			task.Completed = old.Completed
+		}
+		newTasks = append(newTasks, task)
+	}
+	tasks.Tasks = newTasks
+	if findTask(tasks.Current) == nil {
+		tasks.Current = ""
+	}
+	saveTasks()
+	tasksMu.Unlock()
+	updateTaskMenu()
+}
+
+// parseTaskLine parses a "name: estimate" line of the task editor.
+func parseTaskLine(line string) (Task, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return Task{}, false
+	}
+
+	task := Task{Name: line}
+	if i := strings.LastIndex(line, ":"); i >= 0 {
+		if estimate, err := strconv.Atoi(strings.TrimSpace(line[i+1:])); err == nil && estimate >= 0 {
+			task.Name = strings.TrimSpace(line[:i])
+			task.Estimate = estimate
+		}
+	}
+	if task.Name == "" {
+		return Task{}, false
+	}
+	return task, true
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lutischan-ferenc/systray"
+	"golang.org/x/oauth2"
+)
+
+// TeamsSettings configures the Microsoft Teams presence integration.
+type TeamsSettings struct {
+	ClientID string `json:"client_id"` // Application (client) ID of an Azure app registration with the Presence.ReadWrite permission
+	Tenant   string `json:"tenant"`    // Directory (tenant) ID, or "common"
+}
+
+const graphAPIURL = "https://graph.microsoft.com/v1.0"
+
+var (
+	teamsToken   *oauth2.Token // Token of the connected account, nil if not connected
+	teamsTokenMu sync.Mutex    // Mutex for teamsToken
+
+	teamsQueue = newCallQueue("Teams") // Graph calls, so clearing the presence never overtakes setting it
+
+	mTeams *systray.MenuItem // Menu item for connecting or disconnecting Microsoft Teams
+)
+
+// getTeamsTokenPath returns the path to the stored Microsoft Teams token.
+func getTeamsTokenPath() string {
+	return getCacheFilePath("teams_token.json")
+}
+
+// teamsConfig returns the OAuth configuration for the Microsoft identity platform.
+func teamsConfig() *oauth2.Config {
+	tenant := settings.Teams.Tenant
+	if tenant == "" {
+		tenant = "common"
+	}
+	base := "https://login.microsoftonline.com/" + tenant + "/oauth2/v2.0"
+	return &oauth2.Config{
+		ClientID: settings.Teams.ClientID,
+		Scopes:   []string{"Presence.ReadWrite", "offline_access"},
+		Endpoint: oauth2.Endpoint{
+			AuthURL:       base + "/authorize",
+			TokenURL:      base + "/token",
+			DeviceAuthURL: base + "/devicecode",
+			AuthStyle:     oauth2.AuthStyleInParams,
+		},
+	}
+}
+
+// loadTeamsToken loads the stored Microsoft Teams token.
+func loadTeamsToken() {
+	data, err := ioutil.ReadFile(getTeamsTokenPath())
+	if err != nil {
+		return
+	}
+	var token oauth2.Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		slog.Error("Failed to load Microsoft Teams token", "err", err)
+		return
+	}
+	teamsTokenMu.Lock()
+	teamsToken = &token
+	teamsTokenMu.Unlock()
+}
+
+// saveTeamsToken stores the Microsoft Teams token, or removes it if token is nil.
+func saveTeamsToken(token *oauth2.Token) {
+	teamsTokenMu.Lock()
+	teamsToken = token
+	teamsTokenMu.Unlock()
+
+	if token == nil {
+		os.Remove(getTeamsTokenPath())
+		return
+	}
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		slog.Error("Failed to save Microsoft Teams token", "err", err)
+		return
+	}
+	if err := ioutil.WriteFile(getTeamsTokenPath(), data, 0600); err != nil {
+		slog.Error("Failed to write Microsoft Teams token", "err", err)
+	}
+}
+
+// addTeamsMenu adds the Microsoft Teams connect/disconnect menu item.
+func addTeamsMenu() {
+	loadTeamsToken()
+	mTeams = systray.AddMenuItem("", tr("Set Microsoft Teams to Do Not Disturb during Pomodoros"))
+	mTeams.Click(func() {
+		teamsTokenMu.Lock()
+		connected := teamsToken != nil
+		teamsTokenMu.Unlock()
+		if connected {
+			saveTeamsToken(nil)
+			updateTeamsMenu()
+		} else {
+			go connectTeams()
+		}
+	})
+	updateTeamsMenu()
+}
+
+// updateTeamsMenu updates the Microsoft Teams menu item to the connection state.
+func updateTeamsMenu() {
+	if mTeams == nil {
+		return
+	}
+	teamsTokenMu.Lock()
+	connected := teamsToken != nil
+	teamsTokenMu.Unlock()
+	if connected {
+		mTeams.SetTitle(tr("Disconnect Microsoft Teams"))
+	} else {
+		mTeams.SetTitle(tr("Connect Microsoft Teams..."))
+	}
+}
+
+// connectTeams signs in to Microsoft Teams with the OAuth device code flow.
+func connectTeams() {
+	if settings.Teams.ClientID == "" {
+		slog.Warn("Microsoft Teams is not configured, set teams.client_id in the settings")
+		sendNotification(tr("Microsoft Teams"), tr("Set teams.client_id in the settings first"))
+		return
+	}
+
+	ctx := context.Background()
+	config := teamsConfig()
+	auth, err := config.DeviceAuth(ctx)
+	if err != nil {
+		slog.Error("Failed to start Microsoft sign-in", "err", err)
+		return
+	}
+
+	instructions := fmt.Sprintf("To connect Microsoft Teams, open %s\nand enter the code: %s\n\nYou can close this file.\n", auth.VerificationURI, auth.UserCode)
+	go editInEditor("pomodoro_teams_sign_in_*.txt", []byte(instructions))
+	openBrowser(auth.VerificationURI)
+
+	token, err := config.DeviceAccessToken(ctx, auth)
+	if err != nil {
+		slog.Error("Microsoft sign-in failed", "err", err)
+		return
+	}
+	saveTeamsToken(token)
+	updateTeamsMenu()
+	sendNotification(tr("Microsoft Teams"), tr("Connected, your presence is set to Do Not Disturb during Pomodoros"))
+}
+
+// teamsClient returns an HTTP client authorized for Microsoft Graph, or nil if not connected.
+// Refreshed tokens are stored for the next start.
+func teamsClient() *http.Client {
+	teamsTokenMu.Lock()
+	token := teamsToken
+	teamsTokenMu.Unlock()
+	if token == nil || settings.Teams.ClientID == "" {
+		return nil
+	}
+
+	ctx := context.Background()
+	source := teamsConfig().TokenSource(ctx, token)
+	fresh, err := source.Token()
+	if err != nil {
+		slog.Error("Failed to refresh Microsoft Teams token", "err", err)
+		return nil
+	}
+	if fresh.AccessToken != token.AccessToken {
+		saveTeamsToken(fresh)
+	}
+	return oauth2.NewClient(ctx, oauth2.StaticTokenSource(fresh))
+}
+
+// teamsFocusStarted sets the Teams presence to Do Not Disturb for the Pomodoro.
+func teamsFocusStarted(duration time.Duration) {
+	teamsQueue.enqueue(func() {
+		client := teamsClient()
+		if client == nil {
+			return
+		}
+		payload := map[string]string{
+			"availability":       "DoNotDisturb",
+			"activity":           "DoNotDisturb",
+			"expirationDuration": fmt.Sprintf("PT%dM", int((duration+time.Minute-1)/time.Minute)),
+		}
+		if err := teamsPost(client, "/me/presence/setUserPreferredPresence", payload); err != nil {
+			slog.Error("Failed to set Microsoft Teams presence", "err", err)
+		}
+	})
+}
+
+// teamsFocusEnded restores the automatic Teams presence.
+func teamsFocusEnded() {
+	teamsQueue.enqueueRestore(func() {
+		client := teamsClient()
+		if client == nil {
+			return
+		}
+		if err := teamsPost(client, "/me/presence/clearUserPreferredPresence", map[string]string{}); err != nil {
+			slog.Error("Failed to restore Microsoft Teams presence", "err", err)
+		}
+	})
+}
+
+// teamsPost POSTs a JSON payload to a Microsoft Graph endpoint.
+func teamsPost(client *http.Client, path string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(graphAPIURL+path, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TelegramSettings configures the Telegram bot integration.
+type TelegramSettings struct {
+	Token  string `json:"token"`   // Bot token from @BotFather
+	ChatID int64  `json:"chat_id"` // Chat to send messages to and accept commands from
+}
+
+const telegramHelp = `Commands:
+/start_pomodoro - start a Pomodoro
+/start_break - start a break
+/stop - stop the running timer
+/status - show the timer status`
+
+var telegramClient = &http.Client{Timeout: 90 * time.Second}
+
+// telegramUpdate is an update returned by the getUpdates method.
+type telegramUpdate struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		Text string `json:"text"`
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+	} `json:"message"`
+}
+
+// startTelegramBot starts polling the Telegram bot for commands if it is configured.
+func startTelegramBot() {
+	if settings.Telegram.Token == "" || settings.Telegram.ChatID == 0 {
+		return
+	}
+	go pollTelegram(settings.Telegram)
+}
+
+// telegramCall calls a Telegram Bot API method and decodes the result.
+func telegramCall(token, method string, payload interface{}, result interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("https://api.telegram.org/bot%s/%s", token, method)
+	resp, err := telegramClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+	if !response.OK {
+		return fmt.Errorf("telegram error: %s", response.Description)
+	}
+	if result != nil {
+		return json.Unmarshal(response.Result, result)
+	}
+	return nil
+}
+
+// sendTelegramMessage sends a message to the configured chat in the background.
+func sendTelegramMessage(text string) {
+	telegram := settings.Telegram
+	if telegram.Token == "" || telegram.ChatID == 0 {
+		return
+	}
+	go func() {
+		payload := map[string]interface{}{"chat_id": telegram.ChatID, "text": text}
+		if err := telegramCall(telegram.Token, "sendMessage", payload, nil); err != nil {
+			slog.Error("Failed to send Telegram message", "err", err)
+		}
+	}()
+}
+
+// telegramSessionEvent reports a session start or end to the Telegram chat.
+func telegramSessionEvent(event string, record *SessionRecord) {
+	var text string
+	switch event {
+	case eventPomodoroStart:
+		text = fmt.Sprintf("🍅 Pomodoro started (%d min)", record.Duration)
+	case eventBreakStart:
+		text = fmt.Sprintf("☕ Break started (%d min)", record.Duration)
+	case eventPomodoroEnd:
+		text = "✅ Pomodoro finished - time for a break"
+	case eventBreakEnd:
+		text = "⏰ Break finished - time to focus"
+	case eventStop:
+		text = "⏹ Timer stopped"
+	default:
+		return
+	}
+	if record != nil && record.Task != "" {
+		text += "\nTask: " + record.Task
+	}
+	sendTelegramMessage(text)
+}
+
+// pollTelegram long-polls the bot for commands sent from the configured chat.
+func pollTelegram(telegram TelegramSettings) {
+	var offset int64
+	for {
+		var updates []telegramUpdate
+		payload := map[string]interface{}{"offset": offset, "timeout": 60, "allowed_updates": []string{"message"}}
+		if err := telegramCall(telegram.Token, "getUpdates", payload, &updates); err != nil {
+			slog.Error("Failed to get Telegram updates", "err", err)
+			time.Sleep(30 * time.Second)
+			continue
+		}
+
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+			if update.Message == nil || update.Message.Chat.ID != telegram.ChatID {
+				continue
+			}
+			handleTelegramCommand(update.Message.Text)
+		}
+	}
+}
+
+// handleTelegramCommand executes a bot command and replies with the timer status.
+func handleTelegramCommand(text string) {
+	command := strings.Fields(text)
+	if len(command) == 0 {
+		return
+	}
+	// Commands in groups may be addressed as /command@BotName
+	name := strings.SplitN(command[0], "@", 2)[0]
+
+	switch name {
+	case "/start_pomodoro":
+		startPomodoro()
+	case "/start_break":
+		startBreak()
+	case "/stop":
+		mu.Lock()
+		if engine.Running() {
+			stopTimer()
+		}
+		mu.Unlock()
+	case "/status":
+	default:
+		sendTelegramMessage(telegramHelp)
+		return
+	}
+
+	mu.Lock()
+	status := timerStatusText()
+	mu.Unlock()
+	sendTelegramMessage(status)
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lutischan-ferenc/systray"
+)
+
+// iconTheme is a built-in color scheme of the tray icon.
+type iconTheme struct {
+	id         string
+	title      string
+	background string // Hex color of the icon background during Pomodoros
+	text       string // Hex color of the remaining time
+	dots       string // Hex color of the Pomodoro count dots
+	breaks     string // Hex color of the icon background during breaks
+	idle       string // Hex color of the icon background while no timer runs
+}
+
+var iconThemes = []iconTheme{
+	{"classic", "Classic", "#8B0000", "#FFFFFF", "#90EE90", "#1B5E20", "#5A5A5A"},
+	{"tomato", "Tomato", "#E5483B", "#FFFFFF", "#2E7D32", "#1976D2", "#757575"},
+	{"dark", "Dark", "#202020", "#FFFFFF", "#E5483B", "#1B3D2A", "#404040"},
+	{"light", "Light", "#F2F2F2", "#202020", "#C62828", "#D8F0DC", "#C8C8C8"},
+	{"high_contrast", "High Contrast", "#000000", "#FFFF00", "#00FFFF", "#002B80", "#404040"},
+}
+
+// iconThemeAuto picks the light or dark theme to contrast with the taskbar.
+const iconThemeAuto = "auto"
+
+// systemThemeCheckInterval is how often the taskbar theme is checked with the auto theme.
+const systemThemeCheckInterval = 10 * time.Second
+
+var (
+	systemDark bool // The taskbar uses a dark theme, guarded by mu
+
+	iconBackgroundColor color.RGBA // Color of the icon background during Pomodoros
+	iconBreakColor      color.RGBA // Color of the icon background during breaks
+	iconIdleColor       color.RGBA // Color of the icon background while no timer runs
+	iconTextColor       color.RGBA // Color of the remaining time on the icon
+	iconDotColor        color.RGBA // Color of the Pomodoro count dots on the icon
+
+	mTheme      *systray.MenuItem   // Submenu for choosing the icon theme
+	mThemeItems []*systray.MenuItem // Menu items for the built-in themes
+	mThemeAuto  *systray.MenuItem   // Menu item for the automatic theme
+)
+
+// parseHexColor parses a "#RRGGBB" or "#RGB" color.
+func parseHexColor(s string) (color.RGBA, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return color.RGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid color %q", s)
+	}
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}, nil
+}
+
+// currentIconTheme returns the selected built-in theme, or the classic one.
+// The auto theme resolves to the light theme on a dark taskbar and the dark
+// theme on a light one. The caller must hold mu.
+func currentIconTheme() iconTheme {
+	id := settings.IconTheme
+	if id == iconThemeAuto {
+		id = "dark"
+		if systemDark {
+			id = "light"
+		}
+	}
+	for _, theme := range iconThemes {
+		if theme.id == id {
+			return theme
+		}
+	}
+	return iconThemes[0]
+}
+
+// applyIconTheme sets the icon colors from the theme and the color overrides
+// in the settings. Invalid colors fall back to the theme. The caller must hold mu.
+func applyIconTheme() {
+	theme := currentIconTheme()
+	resolve := func(custom, fallback string) color.RGBA {
+		if custom != "" {
+			c, err := parseHexColor(custom)
+			if err == nil {
+				return c
+			}
+			slog.Error("Failed to apply icon color", "err", err)
+		}
+		c, _ := parseHexColor(fallback)
+		return c
+	}
+
+	iconBackgroundColor = resolve(settings.IconBackground, theme.background)
+	iconBreakColor = resolve(settings.IconBreakBackground, theme.breaks)
+	iconIdleColor = resolve(settings.IconIdleBackground, theme.idle)
+	iconTextColor = resolve(settings.IconTextColor, theme.text)
+	iconDotColor = resolve(settings.IconDotColor, theme.dots)
+}
+
+// iconPhaseBackground returns the icon background for the state of the timer,
+// the dot color while the icon flashes, or the meeting color in meeting mode.
+// The caller must hold mu.
+func iconPhaseBackground() color.RGBA {
+	switch {
+	case meetingMode.Load():
+		return meetingModeColor
+	case iconFlashOn:
+		return iconDotColor
+	case !settings.IconPhaseColors:
+		return iconBackgroundColor
+	case !engine.Running() || engine.Paused():
+		return iconIdleColor
+	case !engine.InPomodoro():
+		return iconBreakColor
+	default:
+		return iconBackgroundColor
+	}
+}
+
+// redrawIcon redraws the tray icon with the current colors. The caller must hold mu.
+func redrawIcon() {
+	text := "▶"
+	if engine.Running() && oldDisplayText != "" {
+		text = oldDisplayText
+	}
+	setTrayIcon(text, engine.Count())
+}
+
+// selectIconTheme switches the icon to the theme and saves it in the settings.
+func selectIconTheme(id string) {
+	mu.Lock()
+	settings.IconTheme = id
+	saveSettings()
+	if id == iconThemeAuto {
+		systemDark = systemUsesDarkTheme()
+	}
+	applyIconTheme()
+	redrawIcon()
+	mu.Unlock()
+	updateThemeMenu()
+}
+
+// watchSystemTheme re-renders the icon when the taskbar theme changes while
+// the auto theme is selected.
+func watchSystemTheme() {
+	for range time.Tick(systemThemeCheckInterval) {
+		mu.Lock()
+		auto := settings.IconTheme == iconThemeAuto
+		mu.Unlock()
+		if !auto {
+			continue
+		}
+
+		dark := systemUsesDarkTheme()
+		mu.Lock()
+		if dark != systemDark && settings.IconTheme == iconThemeAuto {
+			systemDark = dark
+			applyIconTheme()
+			redrawIcon()
+		}
+		mu.Unlock()
+	}
+}
+
+// addThemeMenu adds the submenu for choosing the icon theme.
+func addThemeMenu() {
+	mTheme = systray.AddMenuItem(tr("Theme"), tr("Colors of the tray icon"))
+	mThemeAuto = mTheme.AddSubMenuItemCheckbox(tr("Automatic"), tr("Contrast with the light or dark taskbar"), false)
+	mThemeAuto.Click(func() {
+		selectIconTheme(iconThemeAuto)
+	})
+	for _, theme := range iconThemes {
+		id := theme.id
+		item := mTheme.AddSubMenuItemCheckbox(tr(theme.title), tr("Use this icon theme"), false)
+		item.Click(func() {
+			selectIconTheme(id)
+		})
+		mThemeItems = append(mThemeItems, item)
+	}
+	updateThemeMenu()
+	go watchSystemTheme()
+}
+
+// updateThemeMenu refreshes the theme submenu from the settings.
+func updateThemeMenu() {
+	if mTheme == nil {
+		return
+	}
+	mu.Lock()
+	selected := settings.IconTheme
+	if selected != iconThemeAuto {
+		selected = currentIconTheme().id
+	}
+	mu.Unlock()
+
+	if selected == iconThemeAuto {
+		mThemeAuto.Check()
+	} else {
+		mThemeAuto.Uncheck()
+	}
+	for i, theme := range iconThemes {
+		if theme.id == selected {
+			mThemeItems[i].Check()
+		} else {
+			mThemeItems[i].Uncheck()
+		}
+	}
+}
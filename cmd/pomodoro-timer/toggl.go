@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// TogglSettings configures the Toggl Track time entries.
+type TogglSettings struct {
+	APIToken    string           `json:"api_token"`    // API token from the Toggl Track profile page
+	WorkspaceID int64            `json:"workspace_id"` // Workspace the entries are created in
+	ProjectID   int64            `json:"project_id"`   // Project of the entries, 0 for none
+	Projects    map[string]int64 `json:"projects"`     // Project of the entries by task or tag, overriding project_id
+	Description string           `json:"description"`  // Description of entries without a task
+	Billable    bool             `json:"billable"`     // Mark the entries as billable
+}
+
+const togglAPIURL = "https://api.track.toggl.com/api/v9"
+
+var (
+	togglClient  = &http.Client{Timeout: 15 * time.Second}
+	togglQueue   = newCallQueue("Toggl") // Toggl calls, so stopping an entry never overtakes starting it
+	togglEntryID int64                   // ID of the running time entry, used by the queue worker only
+)
+
+// togglProject returns the project of a session: the one mapped to its task,
+// then the one mapped to its tag, then the default project.
+func togglProject(toggl TogglSettings, record SessionRecord) int64 {
+	if id, ok := toggl.Projects[record.Task]; ok && record.Task != "" {
+		return id
+	}
+	if id, ok := toggl.Projects[record.Tag]; ok && record.Tag != "" {
+		return id
+	}
+	return toggl.ProjectID
+}
+
+// togglStarted starts a running Toggl time entry for a Pomodoro.
+func togglStarted(record SessionRecord) {
+	toggl := settings.Toggl
+	if toggl.APIToken == "" || toggl.WorkspaceID == 0 {
+		return
+	}
+	description := record.Task
+	if description == "" {
+		description = toggl.Description
+	}
+	tags := []string{"pomodoro"}
+	if record.Tag != "" {
+		tags = append(tags, record.Tag)
+	}
+	entry := map[string]interface{}{
+		"created_with": "pomodoro-timer",
+		"workspace_id": toggl.WorkspaceID,
+		"description":  description,
+		"start":        record.Start.UTC().Format(time.RFC3339),
+		"duration":     -1, // Running
+		"tags":         tags,
+		"billable":     toggl.Billable,
+	}
+	if project := togglProject(toggl, record); project != 0 {
+		entry["project_id"] = project
+	}
+	togglQueue.enqueue(func() {
+		var created struct {
+			ID int64 `json:"id"`
+		}
+		path := fmt.Sprintf("/workspaces/%d/time_entries", toggl.WorkspaceID)
+		if err := togglCall(toggl.APIToken, http.MethodPost, path, entry, &created); err != nil {
+			slog.Error("Failed to start Toggl time entry", "err", err)
+			return
+		}
+		togglEntryID = created.ID
+	})
+}
+
+// togglEnded stops the running Toggl time entry. Entries of stopped
+// Pomodoros are kept, as the time was still spent on the task.
+func togglEnded() {
+	toggl := settings.Toggl
+	if toggl.APIToken == "" || toggl.WorkspaceID == 0 {
+		return
+	}
+	togglQueue.enqueueRestore(func() {
+		id := togglEntryID
+		togglEntryID = 0
+		if id == 0 {
+			return
+		}
+		path := fmt.Sprintf("/workspaces/%d/time_entries/%d/stop", toggl.WorkspaceID, id)
+		if err := togglCall(toggl.APIToken, http.MethodPatch, path, nil, nil); err != nil {
+			slog.Error("Failed to stop Toggl time entry", "err", err)
+		}
+	})
+}
+
+// togglCall calls the Toggl Track API and decodes the JSON reply into result, if not nil.
+func togglCall(token, method, path string, payload, result interface{}) error {
+	var body []byte
+	if payload != nil {
+		var err error
+		if body, err = json.Marshal(payload); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, togglAPIURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(token, "api_token")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := togglClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+	if result != nil {
+		return json.Unmarshal(data, result)
+	}
+	return nil
+}
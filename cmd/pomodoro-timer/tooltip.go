@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// tooltipData is the data of the tooltip_format template.
+type tooltipData struct {
+	Phase        string // "Pomodoro" or "Break", translated
+	Remaining    string // Time left, e.g. 12:34
+	Elapsed      string // Time spent in the session, e.g. 12:26
+	Task         string // Selected task, empty without one
+	TaskProgress string // Pomodoros completed and estimated for the task, e.g. 2/4
+	Tag          string // Tag of the session, without the leading '#'
+	Count        int    // Pomodoros completed in the current cycle
+	TodayCount   int    // Pomodoros completed today
+	Plan         string // Pomodoros completed and planned today, e.g. 3/8, empty without a plan
+}
+
+var (
+	todayDate      string // Day counted by todayPomodoros, guarded by mu
+	todayPomodoros int    // Pomodoros completed on todayDate, guarded by mu
+
+	tooltipFormat   string             // Text tooltipTemplate was parsed from
+	tooltipTemplate *template.Template // Parsed tooltip_format, nil for the built-in tooltip
+)
+
+// initTodayCount counts the Pomodoros already completed today from the history.
+func initTodayCount() {
+	counts, err := completedOnDay(planToday())
+	if err != nil {
+		slog.Error("Failed to load history", "err", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	todayDate = planToday()
+	todayPomodoros = 0
+	for _, count := range counts {
+		todayPomodoros += count
+	}
+}
+
+// todayPomodoroCompleted counts a completed Pomodoro for today.
+func todayPomodoroCompleted(s sessionInfo) {
+	date := s.record.Start.Local().Format(planDateLayout)
+	if date != todayDate {
+		todayDate = date
+		todayPomodoros = 0
+	}
+	todayPomodoros++
+}
+
+// parseTooltipFormat parses a tooltip_format template and checks it against
+// the template data, so that unknown fields are found up front.
+func parseTooltipFormat(format string) (*template.Template, error) {
+	t, err := template.New("tooltip").Parse(format)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.Execute(&strings.Builder{}, tooltipData{}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// validateTooltipFormat replaces a tooltip_format that cannot be used with the
+// built-in tooltip.
+func validateTooltipFormat(s *TimerSettings) []string {
+	if err := checkTooltipFormat(s.TooltipFormat); err != nil {
+		s.TooltipFormat = ""
+		return []string{fmt.Sprintf("tooltip_format: %v; using the built-in tooltip", err)}
+	}
+	return nil
+}
+
+// checkTooltipFormat returns why a tooltip_format cannot be used, or nil if
+// it can or is empty. The settings form checks it before saving, as
+// validateTooltipFormat would drop it on the next load.
+func checkTooltipFormat(format string) error {
+	if format == "" {
+		return nil
+	}
+	_, err := parseTooltipFormat(format)
+	return err
+}
+
+// runningTooltip returns the tooltip of the running session: the
+// tooltip_format template if set, or the time left followed by the task and
+// plan progress. The caller must hold mu.
+func runningTooltip(s sessionInfo) string {
+	if settings.TooltipFormat == "" {
+		return formatMinSec(s.timer.Remaining) + taskProgressText(s.record.Task) + planProgressText()
+	}
+	if settings.TooltipFormat != tooltipFormat {
+		tooltipFormat = settings.TooltipFormat
+		t, err := parseTooltipFormat(tooltipFormat)
+		if err != nil {
+			slog.Error("Failed to parse the tooltip format", "err", err)
+		}
+		tooltipTemplate = t
+	}
+	if tooltipTemplate == nil {
+		return formatMinSec(s.timer.Remaining)
+	}
+
+	data := tooltipData{
+		Phase:      tr("Break"),
+		Remaining:  formatMinSec(s.timer.Remaining),
+		Elapsed:    formatMinSec(s.timer.Duration - s.timer.Remaining),
+		Task:       s.record.Task,
+		Tag:        s.record.Tag,
+		Count:      engine.Count(),
+		TodayCount: todayPomodoros,
+	}
+	if s.pomodoro() {
+		data.Phase = tr("Pomodoro")
+	}
+	if todayDate != planToday() {
+		data.TodayCount = 0
+	}
+	if data.Task != "" {
+		tasksMu.Lock()
+		if task := findTask(data.Task); task != nil {
+			data.TaskProgress = task.progress()
+		}
+		tasksMu.Unlock()
+	}
+	planMu.Lock()
+	if planned, done, ok := planTotals(); ok {
+		data.Plan = fmt.Sprintf("%d/%d", done, planned)
+	}
+	planMu.Unlock()
+
+	var sb strings.Builder
+	if err := tooltipTemplate.Execute(&sb, data); err != nil {
+		slog.Error("Failed to format the tooltip", "err", err)
+		return formatMinSec(s.timer.Remaining)
+	}
+	return sb.String()
+}
+
+// formatMinSec formats a duration as mm:ss.
+func formatMinSec(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
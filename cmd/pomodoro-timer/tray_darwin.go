@@ -0,0 +1,28 @@
+package main
+
+import (
+	"github.com/lutischan-ferenc/systray"
+	"pomodoro-timer/internal/icon"
+)
+
+// trayIconSpecs describes the icons shown in the menu bar: the monochrome
+// template image and the colored fallback. The caller must hold mu.
+func trayIconSpecs(text string, dotCount int) []icon.Spec {
+	return []icon.Spec{
+		iconSpec(icon.TemplatePalette, iconSize, text, dotCount),
+		iconSpec(currentPalette(), iconSize, text, dotCount),
+	}
+}
+
+// setTrayIcon shows the icon as a monochrome template image, which macOS
+// tints to match light and dark menu bars, with the colored icon as fallback.
+// The caller must hold mu.
+func setTrayIcon(text string, dotCount int) {
+	if launch.headless {
+		return
+	}
+	specs := trayIconSpecs(text, dotCount)
+	if trayIconChanged(specs) {
+		systray.SetTemplateIcon(iconRenderer.PNG(specs[0]), iconRenderer.PNG(specs[1]))
+	}
+}
@@ -0,0 +1,24 @@
+//go:build !windows && !darwin
+
+package main
+
+import (
+	"github.com/lutischan-ferenc/systray"
+	"pomodoro-timer/internal/icon"
+)
+
+// trayIconSpecs describes the icon shown in the tray. The caller must hold mu.
+func trayIconSpecs(text string, dotCount int) []icon.Spec {
+	return []icon.Spec{iconSpec(currentPalette(), iconSize, text, dotCount)}
+}
+
+// setTrayIcon shows the icon as PNG. The caller must hold mu.
+func setTrayIcon(text string, dotCount int) {
+	if launch.headless {
+		return
+	}
+	specs := trayIconSpecs(text, dotCount)
+	if trayIconChanged(specs) {
+		systray.SetIcon(iconRenderer.PNG(specs[0]))
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+
+	"github.com/lutischan-ferenc/systray"
+	"pomodoro-timer/internal/icon"
+)
+
+// trayIconSizes are the sizes rendered into the tray icon: 16 pixels at 100%
+// scaling, 20 at 125%, 24 at 150% and 32 at 200%, plus larger ones for
+// high-DPI displays.
+var trayIconSizes = []int{16, 20, 24, 32, 40, 48, 64}
+
+// trayIconSpecs describes the images of the tray icon, one for every size.
+// The caller must hold mu.
+func trayIconSpecs(text string, dotCount int) []icon.Spec {
+	palette := currentPalette()
+	specs := make([]icon.Spec, len(trayIconSizes))
+	for i, size := range trayIconSizes {
+		specs[i] = iconSpec(palette, size, text, dotCount)
+	}
+	return specs
+}
+
+// setTrayIcon shows the icon as a multi-size ICO, so Windows picks an image
+// rendered for the taskbar size instead of scaling one down. The caller must hold mu.
+func setTrayIcon(text string, dotCount int) {
+	if launch.headless {
+		return
+	}
+	specs := trayIconSpecs(text, dotCount)
+	if !trayIconChanged(specs) {
+		return
+	}
+	data := iconRenderer.ICO(specs)
+	systray.SetIcon(data)
+
+	// SetIcon loads the icon from a temporary file named after the MD5 of its
+	// content. Remove it, as a new icon is shown every minute.
+	sum := md5.Sum(data)
+	os.Remove(filepath.Join(os.TempDir(), "systray_temp_icon_"+hex.EncodeToString(sum[:])))
+}
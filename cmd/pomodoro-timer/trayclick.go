@@ -0,0 +1,110 @@
+package main
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/lutischan-ferenc/systray"
+)
+
+// Actions of clicks on the tray icon.
+const (
+	clickStartStop = "start_stop" // Start the next session, or stop the running one
+	clickPause     = "pause"      // Pause or resume the running session, or start the next one
+	clickMenu      = "menu"       // Open the menu (Windows)
+	clickSkip      = "skip"       // End the running session and start the next one
+	clickDashboard = "dashboard"  // Open the web dashboard
+	clickNone      = "none"       // Do nothing
+)
+
+var clickActions = []string{clickStartStop, clickPause, clickMenu, clickSkip, clickDashboard, clickNone}
+
+// doubleClickInterval is the longest time between the clicks of a double
+// click, as used by the tray.
+const doubleClickInterval = 500 * time.Millisecond
+
+var (
+	clickMu           sync.Mutex
+	pendingClick      *time.Timer // Click waiting to tell it from a double click, guarded by clickMu
+	ignoreClicksUntil time.Time   // Clicks ending a double click are not single clicks, guarded by clickMu
+)
+
+// addTrayClickHandlers sets what clicks on the tray icon do.
+func addTrayClickHandlers() {
+	systray.SetOnClick(func(menu systray.IMenu) {
+		trayClicked(menu)
+	})
+	systray.SetOnDClick(func(menu systray.IMenu) {
+		trayDoubleClicked(menu)
+	})
+	watchMiddleClick(func() {
+		runClickAction(settings.MiddleClickAction, nil)
+	})
+}
+
+// trayClicked runs the left click action. With a double click action, it
+// waits until no second click follows. Opening the menu is never delayed, as
+// the menu must be opened while handling the click.
+func trayClicked(menu systray.IMenu) {
+	action := settings.LeftClickAction
+	if settings.DoubleClickAction == clickNone || action == clickMenu {
+		runClickAction(action, menu)
+		return
+	}
+	clickMu.Lock()
+	defer clickMu.Unlock()
+	if pendingClick != nil || time.Now().Before(ignoreClicksUntil) {
+		return
+	}
+	pendingClick = time.AfterFunc(doubleClickInterval, func() {
+		clickMu.Lock()
+		pendingClick = nil
+		clickMu.Unlock()
+		runClickAction(action, nil)
+	})
+}
+
+// trayDoubleClicked cancels the waiting left click and runs the double click action.
+func trayDoubleClicked(menu systray.IMenu) {
+	clickMu.Lock()
+	if pendingClick != nil {
+		pendingClick.Stop()
+		pendingClick = nil
+	}
+	// Windows reports the release of the second click as another click.
+	ignoreClicksUntil = time.Now().Add(doubleClickInterval)
+	clickMu.Unlock()
+	runClickAction(settings.DoubleClickAction, menu)
+}
+
+// runClickAction runs a click action. The menu can only be opened with the
+// menu of the click.
+func runClickAction(action string, menu systray.IMenu) {
+	switch action {
+	case clickStartStop:
+		if confirmTrayStop() {
+			handleTrayClick()
+		}
+	case clickPause:
+		mu.Lock()
+		running := engine.Running()
+		mu.Unlock()
+		if running {
+			togglePause()
+		} else {
+			handleTrayClick()
+		}
+	case clickMenu:
+		if menu == nil {
+			return
+		}
+		if err := menu.ShowMenu(); err != nil {
+			slog.Error("Failed to open the menu", "err", err)
+		}
+	case clickSkip:
+		skipSession()
+	case clickDashboard:
+		openDashboard()
+	}
+}
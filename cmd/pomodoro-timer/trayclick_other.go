@@ -0,0 +1,6 @@
+//go:build !windows
+
+package main
+
+// watchMiddleClick does nothing, as the tray only reports middle clicks on Windows.
+func watchMiddleClick(fn func()) {}
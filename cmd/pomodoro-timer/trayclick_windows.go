@@ -0,0 +1,75 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	procFindWindowExW            = user32.NewProc("FindWindowExW")
+	procGetWindowThreadProcessId = user32.NewProc("GetWindowThreadProcessId")
+	procSetWindowLongPtrW        = user32.NewProc("SetWindowLongPtrW")
+	procCallWindowProcW          = user32.NewProc("CallWindowProcW")
+
+	trayWndProc   uintptr // Window procedure of the tray window before watchMiddleClick
+	onMiddleClick func()  // Called on a middle click on the tray icon
+)
+
+const (
+	gwlpWndProc      = ^uintptr(3) // -4
+	wmUser           = 0x0400
+	wmMButtonUp      = 0x0208
+	trayClassName    = "SystrayClass" // Window class of the systray package
+	trayIconCallback = wmUser + 1     // Message the systray package receives tray icon events with
+)
+
+// watchMiddleClick calls fn on middle clicks on the tray icon. The systray
+// package only reports left and right clicks, so the window procedure of its
+// window is wrapped to also catch the middle button.
+func watchMiddleClick(fn func()) {
+	if launch.headless {
+		return
+	}
+	hwnd := findTrayWindow()
+	if hwnd == 0 {
+		slog.Error("Failed to find the tray window, middle clicks are ignored")
+		return
+	}
+	onMiddleClick = fn
+	previous, _, err := procSetWindowLongPtrW.Call(hwnd, gwlpWndProc, windows.NewCallback(middleClickWndProc))
+	if previous == 0 {
+		slog.Error("Failed to watch middle clicks", "err", err)
+		return
+	}
+	trayWndProc = previous
+}
+
+// findTrayWindow returns the window of the systray package in this process, or 0.
+func findTrayWindow() uintptr {
+	className, _ := windows.UTF16PtrFromString(trayClassName)
+	var hwnd uintptr
+	for {
+		hwnd, _, _ = procFindWindowExW.Call(0, hwnd, uintptr(unsafe.Pointer(className)), 0)
+		if hwnd == 0 {
+			return 0
+		}
+		var pid uint32
+		procGetWindowThreadProcessId.Call(hwnd, uintptr(unsafe.Pointer(&pid)))
+		if int(pid) == os.Getpid() {
+			return hwnd
+		}
+	}
+}
+
+// middleClickWndProc runs the middle click action and passes every message
+// on to the systray package.
+func middleClickWndProc(hwnd, msg, wParam, lParam uintptr) uintptr {
+	if msg == trayIconCallback && lParam == wmMButtonUp && onMiddleClick != nil {
+		go onMiddleClick()
+	}
+	ret, _, _ := procCallWindowProcW.Call(trayWndProc, hwnd, msg, wParam, lParam)
+	return ret
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lutischan-ferenc/systray"
+)
+
+// version is the version of the app, set when building a release with
+// -ldflags "-X main.version=v1.2.3".
+var version = "dev"
+
+const (
+	releasesURL         = "https://api.github.com/repos/lutischan-ferenc/pomodoro-timer/releases/latest"
+	updateCheckInterval = 7 * 24 * time.Hour // How often the background check looks for a new release
+)
+
+// release is a GitHub release of the app.
+type release struct {
+	Tag    string         `json:"tag_name"`
+	URL    string         `json:"html_url"`
+	Assets []releaseAsset `json:"assets"`
+}
+
+// releaseAsset is a file attached to a release.
+type releaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+var (
+	updateClient = &http.Client{Timeout: 5 * time.Minute}
+
+	availableUpdate   *release   // Newer release found by the last check, nil if none
+	availableUpdateMu sync.Mutex // Mutex for availableUpdate
+
+	mUpdate *systray.MenuItem // Menu item for checking for and installing updates
+)
+
+// getUpdateCheckPath returns the path of the file whose time records the last update check.
+func getUpdateCheckPath() string {
+	return getCacheFilePath("update_check")
+}
+
+// addUpdateMenu adds the update menu item to the system tray.
+func addUpdateMenu() {
+	mUpdate = systray.AddMenuItem(tr("Check for Updates..."), tr("Look for a new version of Pomodoro Timer"))
+	mUpdate.Click(func() {
+		availableUpdateMu.Lock()
+		update := availableUpdate
+		availableUpdateMu.Unlock()
+		if update != nil {
+			go installUpdate(update)
+		} else {
+			go checkForUpdates(true)
+		}
+	})
+}
+
+// watchUpdates checks for a new release weekly while check_for_updates is on.
+// Leftovers of the previous update are removed first.
+func watchUpdates() {
+	if exePath, err := os.Executable(); err == nil {
+		os.Remove(exePath + ".old")
+	}
+	for {
+		if settings.CheckForUpdates && version != "dev" {
+			info, err := os.Stat(getUpdateCheckPath())
+			if err != nil || time.Since(info.ModTime()) >= updateCheckInterval {
+				checkForUpdates(false)
+			}
+		}
+		time.Sleep(24 * time.Hour)
+	}
+}
+
+// checkForUpdates looks for a newer release on GitHub and notifies about it.
+// A manual check also reports when there is nothing new or the check failed.
+func checkForUpdates(manual bool) {
+	ioutil.WriteFile(getUpdateCheckPath(), nil, 0644)
+
+	latest, err := latestRelease()
+	if err != nil {
+		slog.Error("Failed to check for updates", "err", err)
+		if manual {
+			sendNotification(tr("Update check failed"), err.Error())
+		}
+		return
+	}
+	if !newerVersion(latest.Tag, version) {
+		if manual {
+			sendNotification(tr("No update available"), fmt.Sprintf(tr("Pomodoro Timer %s is the latest version"), version))
+		}
+		return
+	}
+
+	availableUpdateMu.Lock()
+	availableUpdate = latest
+	availableUpdateMu.Unlock()
+	if mUpdate != nil {
+		mUpdate.SetTitle(fmt.Sprintf(tr("Install Update to %s..."), latest.Tag))
+	}
+	sendNotification(fmt.Sprintf(tr("Pomodoro Timer %s is available"), latest.Tag), tr("Choose \"Install Update\" in the menu to update and restart"))
+}
+
+// latestRelease returns the latest release of the app on GitHub.
+func latestRelease() (*release, error) {
+	req, err := http.NewRequest(http.MethodGet, releasesURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := updateClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub: %s", resp.Status)
+	}
+	var latest release
+	if err := json.NewDecoder(resp.Body).Decode(&latest); err != nil {
+		return nil, err
+	}
+	return &latest, nil
+}
+
+// parseVersion returns the numbers of a version like "v1.2.3".
+func parseVersion(v string) []int {
+	var numbers []int
+	for _, part := range strings.Split(strings.TrimPrefix(v, "v"), ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			break
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers
+}
+
+// newerVersion reports whether version latest is newer than current. A
+// development build is older than any release.
+func newerVersion(latest, current string) bool {
+	l, c := parseVersion(latest), parseVersion(current)
+	for i := 0; i < len(l); i++ {
+		if i >= len(c) || l[i] > c[i] {
+			return true
+		}
+		if l[i] < c[i] {
+			return false
+		}
+	}
+	return false
+}
+
+// platformAsset returns the release asset for the platform, e.g. "windows"
+// and "amd64", matched by the OS and architecture in its name.
+func platformAsset(r *release, goos, goarch string) (releaseAsset, error) {
+	osNames := map[string][]string{
+		"windows": {"windows", "win"},
+		"darwin":  {"darwin", "macos", "mac"},
+		"linux":   {"linux"},
+	}[goos]
+	archNames := map[string][]string{
+		"amd64": {"amd64", "x86_64", "x64"},
+		"arm64": {"arm64", "aarch64"},
+	}[goarch]
+	containsAny := func(name string, words []string) bool {
+		for _, word := range words {
+			if strings.Contains(name, word) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var candidates []releaseAsset
+	for _, asset := range r.Assets {
+		// "darwin" contains "win", so it is renamed before matching Windows builds.
+		name := strings.ReplaceAll(strings.ToLower(asset.Name), "darwin", "macos")
+		if !containsAny(name, osNames) || strings.HasSuffix(name, ".sha256") || strings.HasSuffix(name, ".txt") {
+			continue
+		}
+		if containsAny(name, archNames) {
+			return asset, nil
+		}
+		candidates = append(candidates, asset)
+	}
+	// Releases with a single build per OS do not name the architecture.
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+	return releaseAsset{}, fmt.Errorf("release %s has no download for %s/%s", r.Tag, goos, goarch)
+}
+
+// installUpdate downloads the release, replaces the executable with it and
+// restarts the app. The running executable is renamed to ".old" rather than
+// overwritten, as Windows does not allow that; it is removed on the next start.
+func installUpdate(r *release) {
+	if err := replaceExecutable(r); err != nil {
+		slog.Error("Failed to install update", "err", err)
+		sendNotification(tr("Update failed"), err.Error())
+		openBrowser(r.URL)
+		return
+	}
+	exePath, err := os.Executable()
+	if err != nil {
+		slog.Error("Failed to restart", "err", err)
+		sendNotification(tr("Update installed"), fmt.Sprintf(tr("Restart Pomodoro Timer to use %s"), r.Tag))
+		return
+	}
+
+	// The new instance is started by onExit, after the timer state is saved.
+	var args []string
+	if portableDir != "" {
+		args = append(args, "--portable")
+	}
+	relaunch = exec.Command(exePath, args...)
+	systray.Quit()
+}
+
+// replaceExecutable downloads the release asset for this platform, checks it
+// against its published checksum and puts it in place of the running executable.
+func replaceExecutable(r *release) error {
+	asset, err := platformAsset(r, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+	checksum, err := releaseChecksum(r, asset)
+	if err != nil {
+		return err
+	}
+	data, err := download(asset.URL)
+	if err != nil {
+		return err
+	}
+	if sum := sha256.Sum256(data); !bytes.Equal(sum[:], checksum) {
+		return fmt.Errorf("the download of %s does not match its SHA-256 checksum", asset.Name)
+	}
+	binary, err := extractExecutable(asset.URL, data)
+	if err != nil {
+		return err
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if exePath, err = filepath.EvalSymlinks(exePath); err != nil {
+		return err
+	}
+	newPath, oldPath := exePath+".new", exePath+".old"
+	if err := ioutil.WriteFile(newPath, binary, 0755); err != nil {
+		return err
+	}
+	os.Remove(oldPath)
+	if err := os.Rename(exePath, oldPath); err != nil {
+		os.Remove(newPath)
+		return err
+	}
+	if err := os.Rename(newPath, exePath); err != nil {
+		os.Rename(oldPath, exePath)
+		return err
+	}
+	return nil
+}
+
+// releaseChecksum returns the SHA-256 checksum of the asset, published in
+// the asset of the same name ending in ".sha256" as written by sha256sum.
+// A release without it cannot be installed, as the download cannot be checked.
+func releaseChecksum(r *release, asset releaseAsset) ([]byte, error) {
+	for _, file := range r.Assets {
+		if !strings.EqualFold(file.Name, asset.Name+".sha256") {
+			continue
+		}
+		data, err := download(file.URL)
+		if err != nil {
+			return nil, err
+		}
+		fields := strings.Fields(string(data))
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("%s is empty", file.Name)
+		}
+		checksum, err := hex.DecodeString(fields[0])
+		if err != nil || len(checksum) != sha256.Size {
+			return nil, fmt.Errorf("%s does not contain a SHA-256 checksum", file.Name)
+		}
+		return checksum, nil
+	}
+	return nil, fmt.Errorf("release %s has no checksum for %s", r.Tag, asset.Name)
+}
+
+// download returns the contents of a release asset.
+func download(url string) ([]byte, error) {
+	resp, err := updateClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// extractExecutable returns the executable in a downloaded asset: the asset
+// itself, or the file named like the app in a .zip or .tar.gz archive.
+func extractExecutable(url string, data []byte) ([]byte, error) {
+	isApp := func(name string) bool {
+		return strings.HasPrefix(strings.ToLower(filepath.Base(name)), "pomodoro-timer")
+	}
+	switch {
+	case strings.HasSuffix(url, ".zip"):
+		archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range archive.File {
+			if file.FileInfo().IsDir() || !isApp(file.Name) {
+				continue
+			}
+			f, err := file.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			return io.ReadAll(f)
+		}
+	case strings.HasSuffix(url, ".tar.gz"), strings.HasSuffix(url, ".tgz"):
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		archive := tar.NewReader(gz)
+		for {
+			header, err := archive.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if header.Typeflag == tar.TypeReg && isApp(header.Name) {
+				return io.ReadAll(archive)
+			}
+		}
+	default:
+		return data, nil
+	}
+	return nil, errors.New("the download does not contain the app")
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	for _, test := range []struct {
+		version string
+		want    []int
+	}{
+		{"v1.2.3", []int{1, 2, 3}},
+		{"1.10", []int{1, 10}},
+		{"v2.0.0-beta", []int{2, 0}},
+		{"dev", nil},
+	} {
+		if got := parseVersion(test.version); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseVersion(%q) = %v, want %v", test.version, got, test.want)
+		}
+	}
+}
+
+func TestNewerVersion(t *testing.T) {
+	for _, test := range []struct {
+		latest, current string
+		want            bool
+	}{
+		{"v1.10.0", "v1.9.0", true},
+		{"v1.9.0", "v1.10.0", false},
+		{"v2.0.0", "v1.99.99", true},
+		{"v1.2.3", "v1.2.3", false},
+		{"v1.2.1", "v1.2", true},
+		{"v1.2", "v1.2.0", false},
+		{"v1.0.0", "dev", true},
+		{"dev", "v1.0.0", false},
+	} {
+		if got := newerVersion(test.latest, test.current); got != test.want {
+			t.Errorf("newerVersion(%q, %q) = %v, want %v", test.latest, test.current, got, test.want)
+		}
+	}
+}
+
+func TestPlatformAsset(t *testing.T) {
+	r := &release{Tag: "v1.5.0", Assets: []releaseAsset{
+		{"pomodoro-timer-darwin-amd64.tar.gz", "darwin-amd64"},
+		{"pomodoro-timer-windows-amd64.zip.sha256", "windows.sha256"},
+		{"pomodoro-timer-windows-amd64.zip", "windows"},
+		{"pomodoro-timer-linux-x86_64.tar.gz", "linux-amd64"},
+		{"pomodoro-timer-linux-aarch64.tar.gz", "linux-arm64"},
+		{"checksums.txt", "checksums"},
+	}}
+	for _, test := range []struct {
+		goos, goarch string
+		want         string // URL of the asset, empty for none
+	}{
+		{"windows", "amd64", "windows"},
+		{"linux", "amd64", "linux-amd64"},
+		{"linux", "arm64", "linux-arm64"},
+		{"darwin", "amd64", "darwin-amd64"},
+		{"windows", "arm64", "windows"}, // The only build for the OS
+		{"linux", "386", ""},
+		{"freebsd", "amd64", ""},
+	} {
+		asset, err := platformAsset(r, test.goos, test.goarch)
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("%s/%s: got %s, want an error", test.goos, test.goarch, asset.Name)
+			}
+			continue
+		}
+		if err != nil || asset.URL != test.want {
+			t.Errorf("%s/%s: got %q (%v), want %q", test.goos, test.goarch, asset.URL, err, test.want)
+		}
+	}
+}
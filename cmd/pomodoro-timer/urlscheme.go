@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"pomodoro-timer/pkg/pomodoro"
+)
+
+const urlScheme = "pomodoro" // Scheme of the links handled by the app, e.g. pomodoro://start
+
+// isAppURL reports whether arg is a pomodoro:// link rather than a flag.
+func isAppURL(arg string) bool {
+	return strings.HasPrefix(strings.ToLower(arg), urlScheme+":")
+}
+
+// appLink is a parsed pomodoro:// link.
+type appLink struct {
+	action   string        // start, pomodoro, break, stop, pause, resume or skip
+	duration time.Duration // Length given in minutes, 0 for the default
+	task     string        // Task to start the Pomodoro on, empty for the current one
+	until    string        // Time of day to focus until, empty for none
+}
+
+// handleAppURL runs the action of a link like
+// pomodoro://start?minutes=25&task=report. The actions are start, break,
+// stop, pause, resume and skip; start and break take optional minutes, and
+// start takes a task, which is added to the task list if it is new, and a
+// time of day to focus until.
+func handleAppURL(raw string) error {
+	link, err := parseAppURL(raw)
+	if err != nil {
+		return err
+	}
+	duration := link.duration
+	switch link.action {
+	case "start", sessionPomodoro:
+		if link.task != "" {
+			useTask(link.task)
+		}
+		if link.until != "" {
+			return focusUntil(link.until)
+		}
+		if duration == 0 {
+			duration = pomodoroDuration()
+		}
+		handleTimerClick(pomodoro.Pomodoro, duration)
+	case sessionBreak:
+		if duration == 0 {
+			mu.Lock()
+			duration = nextBreakDuration()
+			mu.Unlock()
+		}
+		handleTimerClick(pomodoro.Break, duration)
+	default: // stop, pause, resume and skip
+		if status := runControlCommand([]string{link.action}); status.Error != "" {
+			return errors.New(status.Error)
+		}
+	}
+	return nil
+}
+
+// parseAppURL parses a pomodoro:// link, see handleAppURL.
+func parseAppURL(raw string) (appLink, error) {
+	u, err := url.Parse(raw)
+	if err != nil || !strings.EqualFold(u.Scheme, urlScheme) {
+		return appLink{}, fmt.Errorf("invalid link %q", raw)
+	}
+	// Browsers pass pomodoro://start/ or pomodoro:start depending on how the link is written.
+	action := u.Host
+	if action == "" {
+		action = u.Opaque + u.Path
+	}
+	query := u.Query()
+	link := appLink{
+		action: strings.ToLower(strings.Trim(action, "/")),
+		task:   query.Get("task"),
+		until:  query.Get("until"),
+	}
+
+	if m := query.Get("minutes"); m != "" {
+		minutes, err := strconv.Atoi(m)
+		if err != nil || minutes < 1 || minutes > 600 {
+			return appLink{}, fmt.Errorf("minutes must be between 1 and 600")
+		}
+		link.duration = time.Duration(minutes) * time.Minute
+	}
+
+	switch link.action {
+	case "start", sessionPomodoro, sessionBreak, "stop", "pause", "resume", "skip":
+		return link, nil
+	}
+	return appLink{}, fmt.Errorf("unknown action %q in link %q", link.action, raw)
+}
+
+// useTask selects the named task, adding it to the task list if it is new.
+func useTask(name string) {
+	tasksMu.Lock()
+	if findTask(name) == nil {
+		tasks.Tasks = append(tasks.Tasks, Task{Name: name})
+	}
+	tasks.Current = name
+	saveTasks()
+	tasksMu.Unlock()
+	updateTaskMenu()
+}
+
+// handleLaunchURL runs the link the app was started with, if any, and
+// reports a failure as a notification since there is no console to print to.
+func handleLaunchURL() {
+	if launch.url == "" {
+		return
+	}
+	if err := handleAppURL(launch.url); err != nil {
+		slog.Error("Failed to open link", "err", err)
+		go sendNotification(tr("Link not opened"), err.Error())
+	}
+}
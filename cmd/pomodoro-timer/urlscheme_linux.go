@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+const urlDesktopFile = "pomodoro-timer-url.desktop" // Desktop entry handling pomodoro:// links
+
+// registerURLScheme installs a hidden desktop entry handling pomodoro://
+// links and makes it the default handler, updating it if the app was moved.
+func registerURLScheme() {
+	exePath, err := os.Executable()
+	if err != nil {
+		slog.Error("Failed to get executable path", "err", err)
+		return
+	}
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	dir = filepath.Join(dir, "applications")
+	path := filepath.Join(dir, urlDesktopFile)
+
+	entry := []byte(fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name=Pomodoro Timer
+Exec="%s" %%u
+NoDisplay=true
+MimeType=x-scheme-handler/%s;
+`, exePath, urlScheme))
+	if current, err := ioutil.ReadFile(path); err == nil && bytes.Equal(current, entry) {
+		return
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		slog.Error("Failed to register the pomodoro:// links", "err", err)
+		return
+	}
+	if err := ioutil.WriteFile(path, entry, 0644); err != nil {
+		slog.Error("Failed to register the pomodoro:// links", "err", err)
+		return
+	}
+	if err := exec.Command("xdg-mime", "default", urlDesktopFile, "x-scheme-handler/"+urlScheme).Run(); err != nil {
+		slog.Error("Failed to register the pomodoro:// links", "err", err)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !windows && !linux
+
+package main
+
+// registerURLScheme does nothing: macOS delivers links to app bundles as
+// Apple Events rather than command-line arguments, which the app does not
+// handle. The links still work when passed as the argument of the app.
+func registerURLScheme() {}
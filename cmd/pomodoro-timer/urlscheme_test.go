@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsAppURL(t *testing.T) {
+	for arg, want := range map[string]bool{
+		"pomodoro://start": true,
+		"Pomodoro:break":   true,
+		"--start":          false,
+		"pomodoros.txt":    false,
+	} {
+		if got := isAppURL(arg); got != want {
+			t.Errorf("isAppURL(%q) = %v, want %v", arg, got, want)
+		}
+	}
+}
+
+func TestParseAppURL(t *testing.T) {
+	for _, test := range []struct {
+		raw  string
+		want appLink
+	}{
+		{"pomodoro://start", appLink{action: "start"}},
+		{"pomodoro://start/?minutes=50&task=Write%20report", appLink{action: "start", duration: 50 * time.Minute, task: "Write report"}},
+		{"pomodoro://pomodoro?until=17:30", appLink{action: "pomodoro", until: "17:30"}},
+		{"pomodoro:break?minutes=10", appLink{action: "break", duration: 10 * time.Minute}},
+		{"POMODORO://Stop/", appLink{action: "stop"}},
+		{"pomodoro://skip", appLink{action: "skip"}},
+	} {
+		got, err := parseAppURL(test.raw)
+		if err != nil || got != test.want {
+			t.Errorf("parseAppURL(%q) = %+v, %v; want %+v", test.raw, got, err, test.want)
+		}
+	}
+}
+
+func TestParseAppURLErrors(t *testing.T) {
+	for _, raw := range []string{
+		"https://start",
+		"pomodoro://dance",
+		"pomodoro://",
+		"pomodoro://start?minutes=0",
+		"pomodoro://start?minutes=601",
+		"pomodoro://break?minutes=ten",
+	} {
+		if link, err := parseAppURL(raw); err == nil {
+			t.Errorf("parseAppURL(%q) = %+v, want an error", raw, link)
+		}
+	}
+}
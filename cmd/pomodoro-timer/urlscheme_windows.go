@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// registerURLScheme registers the app as the handler of pomodoro:// links
+// for the current user, updating the registration if the app was moved.
+func registerURLScheme() {
+	exePath, err := os.Executable()
+	if err != nil {
+		slog.Error("Failed to get executable path", "err", err)
+		return
+	}
+	command := fmt.Sprintf(`"%s" "%%1"`, exePath)
+
+	base := `Software\Classes\` + urlScheme
+	if key, err := registry.OpenKey(registry.CURRENT_USER, base+`\shell\open\command`, registry.QUERY_VALUE); err == nil {
+		current, _, err := key.GetStringValue("")
+		key.Close()
+		if err == nil && current == command {
+			return
+		}
+	}
+
+	for _, value := range []struct{ path, name, data string }{
+		{base, "", "URL:Pomodoro Timer"},
+		{base, "URL Protocol", ""},
+		{base + `\DefaultIcon`, "", exePath + ",0"},
+		{base + `\shell\open\command`, "", command},
+	} {
+		key, _, err := registry.CreateKey(registry.CURRENT_USER, value.path, registry.SET_VALUE)
+		if err != nil {
+			slog.Error("Failed to register the pomodoro:// links", "err", err)
+			return
+		}
+		err = key.SetStringValue(value.name, value.data)
+		key.Close()
+		if err != nil {
+			slog.Error("Failed to register the pomodoro:// links", "err", err)
+			return
+		}
+	}
+}
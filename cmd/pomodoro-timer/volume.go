@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/lutischan-ferenc/systray"
+)
+
+var volumePresets = []int{25, 50, 75, 100}
+
+var (
+	mVolume        *systray.MenuItem   // Submenu showing the master volume
+	mMute          *systray.MenuItem   // Menu item for muting all sounds
+	mVolumePresets []*systray.MenuItem // Menu items for the master volume presets
+)
+
+// volumeGain converts a 0-100 volume into a gain, applying the master volume,
+// mute, the meeting auto-mute and meeting mode.
+func volumeGain(volume int) float64 {
+	if settings.Muted || meetingMuted.Load() || meetingMode.Load() {
+		return 0
+	}
+	return clampVolume(settings.MasterVolume) / 100 * clampVolume(volume) / 100
+}
+
+// clampVolume limits a volume setting to 0-100.
+func clampVolume(volume int) float64 {
+	return math.Max(0, math.Min(100, float64(volume)))
+}
+
+// clockGain returns the gain of the ticking sound.
+func clockGain() float64 {
+	return volumeGain(settings.ClockVolume)
+}
+
+// alarmGain returns the gain of the beeps and end-of-session sounds.
+func alarmGain() float64 {
+	return volumeGain(settings.AlarmVolume)
+}
+
+// addVolumeMenu adds the volume submenu with master volume presets and a mute toggle.
+func addVolumeMenu() {
+	mVolume = systray.AddMenuItem(tr("Volume"), tr("Set the volume of all sounds"))
+	mMute = mVolume.AddSubMenuItemCheckbox(tr("Mute"), tr("Silence all sounds"), settings.Muted)
+	mMute.Click(func() {
+		mu.Lock()
+		settings.Muted = !settings.Muted
+		saveSettings()
+		mu.Unlock()
+		updateVolumeMenu()
+	})
+	for _, preset := range volumePresets {
+		volume := preset
+		item := mVolume.AddSubMenuItemCheckbox(fmt.Sprintf("%d%%", volume), tr("Set the master volume"), false)
+		item.Click(func() {
+			mu.Lock()
+			settings.MasterVolume = volume
+			saveSettings()
+			mu.Unlock()
+			updateVolumeMenu()
+		})
+		mVolumePresets = append(mVolumePresets, item)
+	}
+	updateVolumeMenu()
+}
+
+// updateVolumeMenu refreshes the volume submenu from the current settings.
+func updateVolumeMenu() {
+	if mVolume == nil {
+		return
+	}
+	if settings.Muted {
+		mVolume.SetTitle(tr("Volume: muted"))
+		mMute.Check()
+	} else {
+		mVolume.SetTitle(fmt.Sprintf(tr("Volume: %d%%"), settings.MasterVolume))
+		mMute.Uncheck()
+	}
+	for i, item := range mVolumePresets {
+		if volumePresets[i] == settings.MasterVolume {
+			item.Check()
+		} else {
+			item.Uncheck()
+		}
+	}
+}
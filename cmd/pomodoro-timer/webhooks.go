@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Timer events sent to webhooks.
+const (
+	eventPomodoroStart = "pomodoro_start"
+	eventPomodoroEnd   = "pomodoro_end"
+	eventBreakStart    = "break_start"
+	eventBreakEnd      = "break_end"
+	eventStop          = "stop"
+)
+
+// Webhook is a URL that is POSTed a JSON payload on timer events.
+type Webhook struct {
+	URL    string   `json:"url"`    // URL to POST to
+	Events []string `json:"events"` // Events to send, all events if empty
+}
+
+// webhookPayload is the JSON body POSTed to webhooks.
+type webhookPayload struct {
+	Event         string    `json:"event"`
+	Time          time.Time `json:"time"`
+	SessionType   string    `json:"session_type"`
+	Duration      int       `json:"duration"`          // Planned duration of the session in minutes
+	RemainingTime int       `json:"remaining_seconds"` // Seconds left when the event happened
+	PomodoroCount int       `json:"pomodoro_count"`
+	Task          string    `json:"task,omitempty"`
+	Tag           string    `json:"tag,omitempty"`
+}
+
+var webhookClient = &http.Client{Timeout: 15 * time.Second}
+
+// wants reports whether the webhook is registered for the event.
+func (w Webhook) wants(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// triggerWebhooks POSTs the event to the registered webhooks in the background.
+// The caller must hold mu.
+func triggerWebhooks(event string, record *SessionRecord) {
+	if len(settings.Webhooks) == 0 {
+		return
+	}
+
+	payload := webhookPayload{
+		Event:         event,
+		Time:          time.Now(),
+		RemainingTime: int(engine.Remaining().Seconds()),
+		PomodoroCount: engine.Count(),
+	}
+	if record != nil {
+		payload.SessionType = record.Type
+		payload.Duration = record.Duration
+		payload.Task = record.Task
+		payload.Tag = record.Tag
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("Failed to encode webhook payload", "err", err)
+		return
+	}
+
+	for _, webhook := range settings.Webhooks {
+		if webhook.URL != "" && webhook.wants(event) {
+			go postWebhook(webhook.URL, body)
+		}
+	}
+}
+
+// postWebhook POSTs a JSON body to a webhook URL.
+func postWebhook(url string, body []byte) {
+	resp, err := webhookClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		slog.Error("Failed to call webhook", "err", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		slog.Error("Webhook failed", "url", url, "status", resp.Status)
+	}
+}
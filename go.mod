@@ -3,16 +3,28 @@ module pomodoro-timer
 go 1.24.1
 
 require (
+	github.com/BurntSushi/toml v1.4.0
+	github.com/apognu/gocal v0.9.1
 	github.com/ebitengine/oto/v3 v3.3.2
+	github.com/eclipse/paho.mqtt.golang v1.5.0
+	github.com/fsnotify/fsnotify v1.10.1
+	github.com/godbus/dbus/v5 v5.1.0
 	github.com/hajimehoshi/go-mp3 v0.3.4
+	github.com/jfreymuth/oggvorbis v1.0.5
 	github.com/lutischan-ferenc/systray v1.2.1
 	golang.org/x/image v0.25.0
+	golang.org/x/oauth2 v0.28.0
 	golang.org/x/sys v0.31.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
+	github.com/ChannelMeter/iso8601duration v0.0.0-20150204201828-8da3af7a2a61 // indirect
 	github.com/ebitengine/purego v0.8.2 // indirect
-	github.com/godbus/dbus/v5 v5.1.0 // indirect
+	github.com/gorilla/websocket v1.5.3 // indirect
+	github.com/jfreymuth/vorbis v1.0.2 // indirect
 	github.com/tevino/abool v1.2.0 // indirect
+	golang.org/x/net v0.27.0 // indirect
+	golang.org/x/sync v0.12.0 // indirect
 	golang.org/x/text v0.23.0 // indirect
 )
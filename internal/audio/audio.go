@@ -0,0 +1,66 @@
+// Package audio plays the sounds of the timer: one-off sounds such as beeps
+// and chimes, and a looping background sound. Sounds are 16-bit stereo PCM
+// at the sample rate of the Player, rendered with Melody, decoded with
+// DecodeFile or generated by the caller. The background sound may also be a
+// Source decoded while it plays, such as an MP3Stream.
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// DefaultSampleRate is the sample rate used when the caller has no preference.
+const DefaultSampleRate = 44100
+
+// frameSize is the size of a 16-bit stereo frame in bytes.
+const frameSize = 4
+
+// Sound is 16-bit little-endian stereo PCM.
+type Sound []byte
+
+// Scaled returns a copy of the sound with its amplitude multiplied by scale.
+func (s Sound) Scaled(scale float64) Sound {
+	out := make(Sound, len(s))
+	copy(out, s)
+	scalePCM(out, scale)
+	return out
+}
+
+// Gain returns the current gain of a kind of sound, from 0 for silence to 1.
+// It is queried while the sound plays, so volume changes apply at once.
+type Gain func() float64
+
+// Player plays sounds. Its methods are safe for concurrent use.
+type Player interface {
+	// SampleRate returns the sample rate the sounds must have.
+	SampleRate() int
+	// Play plays the sound once at the alarm volume and returns when it has finished.
+	Play(s Sound)
+	// Loop repeats the sound in the background at the loop volume until
+	// Stop, fading it in and out over fade. It does nothing if a sound is
+	// already looping.
+	Loop(s Source, fade time.Duration)
+	// Stop fades out the looping sound, if any.
+	Stop()
+	// SetVolume sets the gains of the sounds played with Play and Loop.
+	SetVolume(alarm, loop Gain)
+}
+
+// fullVolume is the gain of players whose volume was not set.
+func fullVolume() float64 {
+	return 1
+}
+
+// scalePCM multiplies the 16-bit samples in p by gain, clipping them.
+func scalePCM(p []byte, gain float64) {
+	if gain == 1 {
+		return
+	}
+	for i := 0; i+1 < len(p); i += 2 {
+		v := float64(int16(binary.LittleEndian.Uint16(p[i:]))) * gain
+		v = math.Max(math.MinInt16, math.Min(math.MaxInt16, v))
+		binary.LittleEndian.PutUint16(p[i:], uint16(int16(v)))
+	}
+}
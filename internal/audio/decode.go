@@ -0,0 +1,190 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/hajimehoshi/go-mp3"
+	"github.com/jfreymuth/oggvorbis"
+)
+
+// WAV sample formats.
+const (
+	wavFormatPCM        = 1
+	wavFormatFloat      = 3
+	wavFormatExtensible = 0xFFFE
+)
+
+// DecodeFile decodes an MP3, WAV or Ogg Vorbis file into a sound at the
+// given sample rate.
+func DecodeFile(path string, sampleRate int) (Sound, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var pcm []byte
+	var rate int
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp3":
+		pcm, rate, err = DecodeMP3(data)
+	case ".wav":
+		pcm, rate, err = decodeWAV(data)
+	case ".ogg", ".oga":
+		pcm, rate, err = decodeOGG(data)
+	default:
+		return nil, fmt.Errorf("unsupported audio format: %s", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resamplePCM(pcm, rate, sampleRate), nil
+}
+
+// DecodeMP3 decodes MP3 data into a sound, returning its sample rate.
+func DecodeMP3(data []byte) (Sound, int, error) {
+	decoder, err := mp3.NewDecoder(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, err
+	}
+	pcm, err := ioutil.ReadAll(decoder)
+	if err != nil {
+		return nil, 0, err
+	}
+	return pcm, decoder.SampleRate(), nil
+}
+
+// resamplePCM converts 16-bit stereo PCM between sample rates using linear interpolation.
+func resamplePCM(pcm []byte, from, to int) []byte {
+	if from == to || from <= 0 || to <= 0 {
+		return pcm
+	}
+
+	inFrames := len(pcm) / frameSize
+	if inFrames == 0 {
+		return pcm
+	}
+	outFrames := int(int64(inFrames) * int64(to) / int64(from))
+	out := make([]byte, outFrames*frameSize)
+	sample := func(frame, ch int) float64 {
+		if frame >= inFrames {
+			frame = inFrames - 1
+		}
+		return float64(int16(binary.LittleEndian.Uint16(pcm[frame*frameSize+ch*2:])))
+	}
+
+	for i := 0; i < outFrames; i++ {
+		pos := float64(i) * float64(from) / float64(to)
+		frame := int(pos)
+		frac := pos - float64(frame)
+		for ch := 0; ch < 2; ch++ {
+			v := sample(frame, ch)*(1-frac) + sample(frame+1, ch)*frac
+			binary.LittleEndian.PutUint16(out[i*frameSize+ch*2:], uint16(int16(v)))
+		}
+	}
+	return out
+}
+
+// decodeWAV decodes RIFF/WAVE data with 8, 16, 24 or 32-bit integer or 32-bit
+// float samples into 16-bit stereo PCM.
+func decodeWAV(data []byte) ([]byte, int, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, 0, errors.New("not a WAV file")
+	}
+
+	var format, channels, bitsPerSample int
+	var sampleRate int
+	var samples []byte
+	for pos := 12; pos+8 <= len(data); {
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		pos += 8
+		if size > len(data)-pos {
+			size = len(data) - pos
+		}
+		chunk := data[pos : pos+size]
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, 0, errors.New("invalid WAV format chunk")
+			}
+			format = int(binary.LittleEndian.Uint16(chunk[0:]))
+			channels = int(binary.LittleEndian.Uint16(chunk[2:]))
+			sampleRate = int(binary.LittleEndian.Uint32(chunk[4:]))
+			bitsPerSample = int(binary.LittleEndian.Uint16(chunk[14:]))
+			if format == wavFormatExtensible && size >= 26 {
+				format = int(binary.LittleEndian.Uint16(chunk[24:]))
+			}
+		case "data":
+			samples = chunk
+		}
+		pos += size + size%2 // Chunks are padded to an even size
+	}
+
+	if samples == nil || channels == 0 {
+		return nil, 0, errors.New("WAV file has no audio data")
+	}
+	bytesPerSample := bitsPerSample / 8
+	switch {
+	case format == wavFormatPCM && bytesPerSample >= 1 && bytesPerSample <= 4:
+	case format == wavFormatFloat && bytesPerSample == 4:
+	default:
+		return nil, 0, fmt.Errorf("unsupported WAV format %d with %d bits per sample", format, bitsPerSample)
+	}
+
+	values := make([]float32, len(samples)/bytesPerSample)
+	for i := range values {
+		b := samples[i*bytesPerSample:]
+		switch {
+		case format == wavFormatFloat:
+			values[i] = math.Float32frombits(binary.LittleEndian.Uint32(b))
+		case bytesPerSample == 1:
+			values[i] = (float32(b[0]) - 128) / 128 // 8-bit samples are unsigned
+		case bytesPerSample == 2:
+			values[i] = float32(int16(binary.LittleEndian.Uint16(b))) / (1 << 15)
+		case bytesPerSample == 3:
+			values[i] = float32(int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24)) / (1 << 31)
+		case bytesPerSample == 4:
+			values[i] = float32(int32(binary.LittleEndian.Uint32(b))) / (1 << 31)
+		}
+	}
+	return floatToStereoPCM(values, channels), sampleRate, nil
+}
+
+// decodeOGG decodes Ogg Vorbis data into 16-bit stereo PCM.
+func decodeOGG(data []byte) ([]byte, int, error) {
+	values, format, err := oggvorbis.ReadAll(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, err
+	}
+	return floatToStereoPCM(values, format.Channels), format.SampleRate, nil
+}
+
+// floatToStereoPCM converts interleaved float samples in the range -1..1 into
+// 16-bit stereo PCM. Mono is copied to both channels, and only the first two
+// channels of multichannel audio are kept.
+func floatToStereoPCM(values []float32, channels int) []byte {
+	if channels <= 0 {
+		return nil
+	}
+	frames := len(values) / channels
+	pcm := make([]byte, frames*4)
+	for i := 0; i < frames; i++ {
+		left := values[i*channels]
+		right := left
+		if channels > 1 {
+			right = values[i*channels+1]
+		}
+		for ch, v := range [2]float32{left, right} {
+			v = float32(math.Max(-1, math.Min(1, float64(v))))
+			binary.LittleEndian.PutUint16(pcm[i*4+ch*2:], uint16(int16(v*math.MaxInt16)))
+		}
+	}
+	return pcm
+}
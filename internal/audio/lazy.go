@@ -0,0 +1,149 @@
+package audio
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ebitengine/oto/v3"
+)
+
+// lazyOpenWait is how long a sound waits for the sound device to open. A
+// device still opening after it, e.g. while the audio service starts at
+// login, is checked again by the next sound.
+const lazyOpenWait = 2 * time.Second
+
+// lazyPlayer is a Player that opens the sound device on the first sound and
+// stays silent if there is none.
+type lazyPlayer struct {
+	sampleRate int
+	failed     func(error) // Called once if the sound device cannot be used
+
+	mu      sync.Mutex
+	alarm   Gain
+	loop    Gain
+	context *oto.Context  // Nil until the first sound
+	ready   chan struct{} // Closed once the context has opened the device
+	device  *otoPlayer    // Plays on the device once it is open
+	err     error         // Why the device cannot be used, nil while it can
+}
+
+// Lazy returns a Player that opens the sound device when the first sound
+// plays instead of at once, so starting the app never waits for or fails on
+// the device. Without a usable device, or once the device reports an error,
+// it plays nothing, like Silent, and calls failed once with the reason.
+func Lazy(sampleRate int, failed func(error)) Player {
+	if sampleRate <= 0 {
+		sampleRate = DefaultSampleRate
+	}
+	return &lazyPlayer{sampleRate: sampleRate, failed: failed, alarm: fullVolume, loop: fullVolume}
+}
+
+// SampleRate returns the sample rate the player was made with.
+func (p *lazyPlayer) SampleRate() int {
+	return p.sampleRate
+}
+
+// SetVolume sets the gains of the sounds played once and of the looping sound.
+func (p *lazyPlayer) SetVolume(alarm, loop Gain) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.alarm, p.loop = alarm, loop
+	if p.device != nil {
+		p.device.SetVolume(alarm, loop)
+	}
+}
+
+// Play plays the sound once on the device, opening it if needed, and waits
+// until it has finished. It returns at once without a device.
+func (p *lazyPlayer) Play(s Sound) {
+	if device := p.open(); device != nil {
+		device.Play(s)
+	}
+}
+
+// Loop repeats the sound on the device until Stop, opening it if needed.
+func (p *lazyPlayer) Loop(s Source, fade time.Duration) {
+	if device := p.open(); device != nil {
+		device.Loop(s, fade)
+	}
+}
+
+// Stop fades out the looping sound, if the device is open.
+func (p *lazyPlayer) Stop() {
+	p.mu.Lock()
+	device := p.device
+	p.mu.Unlock()
+	if device != nil {
+		device.Stop()
+	}
+}
+
+// open returns the player of the sound device, creating the context on the
+// first call and waiting up to lazyOpenWait for it to open the device. It
+// returns nil while the device is still opening and once it has failed.
+func (p *lazyPlayer) open() *otoPlayer {
+	p.mu.Lock()
+	if p.err != nil {
+		p.mu.Unlock()
+		return nil
+	}
+	if p.device != nil {
+		device := p.device
+		p.mu.Unlock()
+		if err := device.context.Err(); err != nil {
+			p.fail(fmt.Errorf("sound device failed: %v", err))
+			return nil
+		}
+		return device
+	}
+	if p.context == nil {
+		context, ready, err := newContext(p.sampleRate)
+		if err != nil {
+			p.mu.Unlock()
+			p.fail(err)
+			return nil
+		}
+		p.context, p.ready = context, ready
+	}
+	context, ready := p.context, p.ready
+	p.mu.Unlock()
+
+	select {
+	case <-ready:
+	case <-time.After(lazyOpenWait):
+		return nil
+	}
+	if err := context.Err(); err != nil {
+		p.fail(fmt.Errorf("failed to open the sound device: %v", err))
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return nil
+	}
+	if p.device == nil {
+		p.device = &otoPlayer{context: context, sampleRate: p.sampleRate, alarm: p.alarm, loop: p.loop}
+	}
+	return p.device
+}
+
+// fail makes the player silent and reports the error, once.
+func (p *lazyPlayer) fail(err error) {
+	p.mu.Lock()
+	if p.err != nil {
+		p.mu.Unlock()
+		return
+	}
+	p.err = err
+	device := p.device
+	p.mu.Unlock()
+	if device != nil {
+		device.Stop()
+	}
+	if p.failed != nil {
+		p.failed(err)
+	}
+}
@@ -0,0 +1,204 @@
+package audio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ebitengine/oto/v3"
+)
+
+// otoPlayer plays sounds on the sound card through oto.
+type otoPlayer struct {
+	context    *oto.Context
+	sampleRate int
+
+	mu       sync.Mutex
+	alarm    Gain          // Gain of the sounds played once
+	loop     Gain          // Gain of the looping sound
+	loopStop chan struct{} // Closed to fade out the looping sound, nil if none is looping
+}
+
+// New returns a Player playing on the sound card at the given sample rate.
+// It fails if there is no sound device; Silent can be used instead.
+func New(sampleRate int) (Player, error) {
+	if sampleRate <= 0 {
+		sampleRate = DefaultSampleRate
+	}
+	context, ready, err := newContext(sampleRate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wait for the context to be ready
+	<-ready
+	if err := context.Err(); err != nil {
+		return nil, fmt.Errorf("failed to open the sound device: %v", err)
+	}
+	return &otoPlayer{context: context, sampleRate: sampleRate, alarm: fullVolume, loop: fullVolume}, nil
+}
+
+// newContext creates the oto context of 16-bit stereo sound. The context
+// opens the device in the background and is ready once the channel is
+// closed. Oto allows a single context per process, even if creating it failed.
+func newContext(sampleRate int) (*oto.Context, chan struct{}, error) {
+	context, ready, err := oto.NewContext(&oto.NewContextOptions{
+		SampleRate:   sampleRate,
+		ChannelCount: 2,
+		Format:       oto.FormatSignedInt16LE,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create audio context: %v", err)
+	}
+	return context, ready, nil
+}
+
+// SampleRate returns the sample rate of the audio context.
+func (p *otoPlayer) SampleRate() int {
+	return p.sampleRate
+}
+
+// SetVolume sets the gains of the sounds played once and of the looping sound.
+func (p *otoPlayer) SetVolume(alarm, loop Gain) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.alarm, p.loop = alarm, loop
+}
+
+// gains returns the gains of the sounds played once and of the looping sound.
+func (p *otoPlayer) gains() (Gain, Gain) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.alarm, p.loop
+}
+
+// Play plays the sound once and waits until it has finished.
+func (p *otoPlayer) Play(s Sound) {
+	alarm, _ := p.gains()
+	player := p.context.NewPlayer(&volumeReader{r: bytes.NewReader(s), gain: alarm})
+	player.Play()
+	for player.IsPlaying() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	player.Close()
+}
+
+// Loop repeats the sound in the background until Stop. A source that cannot
+// be opened is not played.
+func (p *otoPlayer) Loop(s Source, fade time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.loopStop != nil {
+		return
+	}
+	r, err := s.Open()
+	if err != nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	p.loopStop = stop
+	lr := &loopReader{
+		source:     s,
+		r:          r,
+		fadeFrames: int64(fade.Seconds() * float64(p.sampleRate)),
+		fadeOutAt:  -1,
+	}
+	player := p.context.NewPlayer(&volumeReader{r: lr, gain: p.loop})
+	player.Play()
+
+	// The player is closed once the fade-out has played, so a new
+	// looping sound can start while the old one fades.
+	go func() {
+		<-stop
+		if fade > 0 {
+			lr.fadeOut()
+			time.Sleep(fade)
+		}
+		player.Close()
+	}()
+}
+
+// Stop fades out the looping sound.
+func (p *otoPlayer) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.loopStop != nil {
+		close(p.loopStop)
+		p.loopStop = nil
+	}
+}
+
+// volumeReader scales 16-bit PCM samples read from r by a gain. The gain is
+// queried on every read so volume changes apply to sounds already playing.
+type volumeReader struct {
+	r    io.Reader
+	gain Gain
+}
+
+// Read reads PCM data and scales its samples.
+func (vr *volumeReader) Read(p []byte) (int, error) {
+	n, err := vr.r.Read(p)
+	scalePCM(p[:n], vr.gain())
+	return n, err
+}
+
+// loopReader repeats a source endlessly, opening it again at its end. It
+// fades the sound in over the first fadeFrames frames and, once fadeOut is
+// called, out over the same length.
+type loopReader struct {
+	source     Source
+	r          io.Reader   // Reader of the current repeat
+	fadeFrames int64       // Length of the fades in 16-bit stereo frames, 0 to disable
+	read       int64       // Bytes read so far
+	fadeOutAt  int64       // Byte offset where the fade-out started, -1 before it
+	fadingOut  atomic.Bool // Set by fadeOut, picked up by the next Read
+}
+
+// Read reads the sound, starting over at its end.
+func (lr *loopReader) Read(p []byte) (int, error) {
+	n, err := lr.r.Read(p)
+	if err == io.EOF {
+		r, openErr := lr.source.Open()
+		if openErr != nil {
+			return n, openErr
+		}
+		lr.r = r
+		err = nil
+	}
+	lr.applyFade(p[:n])
+	return n, err
+}
+
+// fadeOut starts fading the sound out. It is safe to call while the reader is being read.
+func (lr *loopReader) fadeOut() {
+	lr.fadingOut.Store(true)
+}
+
+// applyFade ramps the amplitude of the samples in p according to their position.
+func (lr *loopReader) applyFade(p []byte) {
+	start := lr.read
+	lr.read += int64(len(p))
+	if lr.fadeFrames <= 0 {
+		return
+	}
+	if lr.fadingOut.Load() && lr.fadeOutAt < 0 {
+		lr.fadeOutAt = start
+	}
+	if lr.fadeOutAt < 0 && start/frameSize >= lr.fadeFrames {
+		return
+	}
+
+	for i := 0; i+1 < len(p); i += 2 {
+		pos := start + int64(i)
+		gain := math.Min(1, float64(pos/frameSize)/float64(lr.fadeFrames))
+		if lr.fadeOutAt >= 0 {
+			gain = math.Min(gain, math.Max(0, 1-float64((pos-lr.fadeOutAt)/frameSize)/float64(lr.fadeFrames)))
+		}
+		scalePCM(p[i:i+2], gain)
+	}
+}
@@ -0,0 +1,35 @@
+package audio
+
+import "time"
+
+// silentPlayer is a Player that plays nothing, for machines without a sound
+// device and runs where sounds are not wanted.
+type silentPlayer struct {
+	sampleRate int
+}
+
+// Silent returns a Player that plays nothing. Sounds are still rendered at
+// sampleRate, so they can be switched to a real Player.
+func Silent(sampleRate int) Player {
+	if sampleRate <= 0 {
+		sampleRate = DefaultSampleRate
+	}
+	return silentPlayer{sampleRate: sampleRate}
+}
+
+// SampleRate returns the sample rate the player was made with.
+func (p silentPlayer) SampleRate() int {
+	return p.sampleRate
+}
+
+// Play returns at once.
+func (silentPlayer) Play(Sound) {}
+
+// Loop does nothing.
+func (silentPlayer) Loop(Source, time.Duration) {}
+
+// Stop does nothing.
+func (silentPlayer) Stop() {}
+
+// SetVolume does nothing.
+func (silentPlayer) SetVolume(Gain, Gain) {}
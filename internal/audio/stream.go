@@ -0,0 +1,157 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/hajimehoshi/go-mp3"
+)
+
+// Source is a sound that is read while it plays, so a long background track
+// does not have to be held in memory as PCM. A Sound is a Source of itself.
+type Source interface {
+	// Open returns a reader of the sound from its start, as 16-bit stereo
+	// PCM at the sample rate of the Player.
+	Open() (io.Reader, error)
+}
+
+// Open returns a reader of the sound.
+func (s Sound) Open() (io.Reader, error) {
+	return bytes.NewReader(s), nil
+}
+
+// mp3Stream is MP3 data decoded while it plays.
+type mp3Stream struct {
+	data       []byte
+	sampleRate int // Sample rate of the Player
+}
+
+// MP3Stream returns a source decoding the MP3 data while it plays, resampled
+// to sampleRate, instead of decoding it into memory at once. Only the MP3
+// data is kept.
+func MP3Stream(data []byte, sampleRate int) (Source, error) {
+	s := mp3Stream{data: data, sampleRate: sampleRate}
+	if _, err := s.Open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// MP3SampleRate returns the sample rate of MP3 data, reading only its first frame.
+func MP3SampleRate(data []byte) (int, error) {
+	decoder, err := newMP3Decoder(data)
+	if err != nil {
+		return 0, err
+	}
+	return decoder.SampleRate(), nil
+}
+
+// Open starts decoding the MP3 data from its start.
+func (s mp3Stream) Open() (io.Reader, error) {
+	decoder, err := newMP3Decoder(s.data)
+	if err != nil {
+		return nil, err
+	}
+	return newResampleReader(decoder, decoder.SampleRate(), s.sampleRate), nil
+}
+
+// newMP3Decoder returns a decoder of the MP3 data. The reader is not
+// seekable, so the decoder does not scan every frame for the length.
+func newMP3Decoder(data []byte) (*mp3.Decoder, error) {
+	return mp3.NewDecoder(struct{ io.Reader }{bytes.NewReader(data)})
+}
+
+// StreamFile returns a source of an MP3, WAV or Ogg Vorbis file at the given
+// sample rate. MP3 files are decoded while they play; the other formats are
+// decoded into memory like DecodeFile.
+func StreamFile(path string, sampleRate int) (Source, error) {
+	if strings.ToLower(filepath.Ext(path)) != ".mp3" {
+		return DecodeFile(path, sampleRate)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return MP3Stream(data, sampleRate)
+}
+
+// resampleReader converts 16-bit stereo PCM between sample rates using linear
+// interpolation, like resamplePCM, while it is read.
+type resampleReader struct {
+	r       *bufio.Reader
+	step    float64 // Input frames per output frame
+	pos     float64 // Position between the frames a and b, from 0 to 1
+	a, b    [2]float64
+	started bool
+	done    bool // The input has ended
+}
+
+// newResampleReader returns a reader of r resampled from one sample rate to
+// another, or r itself if the rates are equal.
+func newResampleReader(r io.Reader, from, to int) io.Reader {
+	if from == to || from <= 0 || to <= 0 {
+		return r
+	}
+	return &resampleReader{r: bufio.NewReaderSize(r, 16*1024), step: float64(from) / float64(to)}
+}
+
+// readFrame reads the next input frame. It reports false at the end of the input.
+func (rr *resampleReader) readFrame(frame *[2]float64) (bool, error) {
+	var buf [frameSize]byte
+	if _, err := io.ReadFull(rr.r, buf[:]); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return false, nil
+		}
+		return false, err
+	}
+	for ch := 0; ch < 2; ch++ {
+		frame[ch] = float64(int16(binary.LittleEndian.Uint16(buf[ch*2:])))
+	}
+	return true, nil
+}
+
+// Read reads resampled frames, as many as fit into p.
+func (rr *resampleReader) Read(p []byte) (int, error) {
+	if !rr.started {
+		rr.started = true
+		ok, err := rr.readFrame(&rr.a)
+		if err != nil {
+			return 0, err
+		}
+		rr.done = !ok
+		if ok {
+			if ok, err = rr.readFrame(&rr.b); err != nil {
+				return 0, err
+			} else if !ok {
+				rr.b = rr.a
+			}
+		}
+	}
+
+	n := 0
+	for !rr.done && n+frameSize <= len(p) {
+		for ch := 0; ch < 2; ch++ {
+			v := rr.a[ch]*(1-rr.pos) + rr.b[ch]*rr.pos
+			binary.LittleEndian.PutUint16(p[n+ch*2:], uint16(int16(v)))
+		}
+		n += frameSize
+		for rr.pos += rr.step; rr.pos >= 1 && !rr.done; rr.pos-- {
+			rr.a = rr.b
+			ok, err := rr.readFrame(&rr.b)
+			if err != nil {
+				return n, err
+			}
+			rr.done = !ok
+		}
+	}
+	if n == 0 && rr.done {
+		return 0, io.EOF
+	}
+	return n, nil
+}
@@ -0,0 +1,83 @@
+package audio
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+)
+
+// Note is a tone of a melody.
+type Note struct {
+	Freq     float64       // Frequency in Hz, 0 for a rest
+	Duration time.Duration // Length of the note
+}
+
+// Melody renders the notes as a sound of sine waves at the given amplitude,
+// from 0 to 1. Every note fades in and out quickly to avoid clicks.
+func Melody(notes []Note, amplitude float64, sampleRate int) Sound {
+	const fade = 0.005 // Fade length in seconds
+	rate := float64(sampleRate)
+
+	var pcm Sound
+	for _, n := range notes {
+		frames := int(rate * n.Duration.Seconds())
+		buf := make([]byte, frames*frameSize)
+		for i := 0; i < frames && n.Freq > 0; i++ {
+			t := float64(i) / rate
+			envelope := math.Min(1, math.Min(t, n.Duration.Seconds()-t)/fade)
+			v := int16(math.Sin(2*math.Pi*n.Freq*t) * amplitude * envelope * math.MaxInt16)
+			binary.LittleEndian.PutUint16(buf[i*frameSize:], uint16(v))
+			binary.LittleEndian.PutUint16(buf[i*frameSize+2:], uint16(v))
+		}
+		pcm = append(pcm, buf...)
+	}
+	return pcm
+}
+
+// Tone renders a single sine wave, e.g. a beep.
+func Tone(freq float64, duration time.Duration, amplitude float64, sampleRate int) Sound {
+	return Melody([]Note{{freq, duration}}, amplitude, sampleRate)
+}
+
+// binauralBeat is a Source of two sine waves, one per ear, whose frequencies
+// differ by the beat frequency.
+type binauralBeat struct {
+	left, right float64 // Frequencies of the channels in Hz
+	amplitude   float64
+	sampleRate  int
+}
+
+// BinauralBeat returns an endless source playing a carrier tone whose left
+// and right channels are detuned by half the beat frequency each, which is
+// heard as a beat at that frequency through headphones, e.g. 200 Hz with a
+// 10 Hz beat. It is generated while it plays, so it never repeats with a seam.
+func BinauralBeat(carrier, beat, amplitude float64, sampleRate int) Source {
+	return binauralBeat{carrier - beat/2, carrier + beat/2, amplitude, sampleRate}
+}
+
+// Open starts the tones from the zero crossing of both channels.
+func (b binauralBeat) Open() (io.Reader, error) {
+	return &binauralReader{beat: b}, nil
+}
+
+// binauralReader generates the frames of a binaural beat.
+type binauralReader struct {
+	beat   binauralBeat
+	phases [2]float64 // Phases of the channels, from 0 to 1
+}
+
+// Read fills p with as many frames as fit.
+func (r *binauralReader) Read(p []byte) (int, error) {
+	steps := [2]float64{r.beat.left / float64(r.beat.sampleRate), r.beat.right / float64(r.beat.sampleRate)}
+	n := 0
+	for ; n+frameSize <= len(p); n += frameSize {
+		for ch := 0; ch < 2; ch++ {
+			v := int16(math.Sin(2*math.Pi*r.phases[ch]) * r.beat.amplitude * math.MaxInt16)
+			binary.LittleEndian.PutUint16(p[n+ch*2:], uint16(v))
+			// Wrapping the phase keeps it precise however long the tone plays.
+			r.phases[ch] = math.Mod(r.phases[ch]+steps[ch], 1)
+		}
+	}
+	return n, nil
+}
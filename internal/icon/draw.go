@@ -0,0 +1,147 @@
+package icon
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// ringThickness is the width of the progress ring at 64 pixels.
+const ringThickness = 7
+
+// drawDigits draws the remaining time with the Pomodoro count dots below it.
+// The layout is designed for 64 pixels and scaled. The caller must hold r.mu.
+func (r *Renderer) drawDigits(s Spec) *image.RGBA {
+	scale := float64(s.Size) / 64
+	img := newImage(s.Size, s.Palette.Background)
+
+	face := fitFontFace(s.Text, 60*scale, r.fontFace(46*scale), r.fontFace(30*scale), r.fontFace(20*scale))
+	bounds, _ := font.BoundString(face, s.Text)
+	textWidth := (bounds.Max.X - bounds.Min.X).Ceil()
+	textHeight := (bounds.Max.Y - bounds.Min.Y).Ceil()
+
+	x := (s.Size - textWidth) / 2
+	y := (s.Size+textHeight)/2 - int(math.Round(5*scale))
+
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(s.Palette.Text),
+		Face: face,
+		Dot:  fixed.Point26_6{X: fixed.I(x), Y: fixed.I(y)},
+	}
+	d.DrawString(s.Text)
+
+	dotRadius := 6 * scale
+	dotSpacing := 5 * scale
+	startX := 5 * scale
+
+	for i := 0; i < s.Dots; i++ {
+		dotX := startX + float64(i)*(dotRadius*2+dotSpacing)
+		dotY := 56 * scale
+		drawCircle(img, dotX, dotY, dotRadius, s.Palette.Dots)
+	}
+
+	return img
+}
+
+// drawProgress draws a ring, or a pie, showing the remaining fraction of the
+// session, the remaining time in the middle and small Pomodoro count dots
+// below it. The caller must hold r.mu.
+func (r *Renderer) drawProgress(s Spec) *image.RGBA {
+	size, palette, pie := s.Size, s.Palette, s.Style == Pie
+	scale := float64(size) / 64
+	img := newImage(size, palette.Background)
+
+	track := blendColor(palette.Background, palette.Dots, 0.25)
+	fill, dots := palette.Dots, palette.Dots
+	if pie {
+		fill, dots = blendColor(palette.Background, palette.Dots, 0.6), palette.Text
+	}
+
+	center := float64(size) / 2
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dx, dy := float64(x)+0.5-center, float64(y)+0.5-center
+			dist := math.Hypot(dx, dy)
+			if dist > center || (!pie && dist < center-ringThickness*scale) {
+				continue
+			}
+			// Fraction of the full turn, clockwise from 12 o'clock
+			angle := math.Atan2(dx, -dy) / (2 * math.Pi)
+			if angle < 0 {
+				angle++
+			}
+			if angle < s.Progress {
+				img.SetRGBA(x, y, fill)
+			} else if !pie {
+				img.SetRGBA(x, y, track)
+			}
+		}
+	}
+
+	face := fitFontFace(s.Text, (64-2*ringThickness-4)*scale, r.fontFace(30*scale), r.fontFace(20*scale))
+	bounds, _ := font.BoundString(face, s.Text)
+	textWidth := (bounds.Max.X - bounds.Min.X).Ceil()
+	textHeight := (bounds.Max.Y - bounds.Min.Y).Ceil()
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(palette.Text),
+		Face: face,
+		Dot:  fixed.Point26_6{X: fixed.I((size - textWidth) / 2), Y: fixed.I((size+textHeight)/2 - int(math.Round(3*scale)))},
+	}
+	d.DrawString(s.Text)
+
+	dotRadius := 3 * scale
+	dotSpacing := 2 * scale
+	startX := center - (float64(s.Dots)*(dotRadius*2+dotSpacing)-dotSpacing)/2 + dotRadius
+	for i := 0; i < s.Dots; i++ {
+		drawCircle(img, startX+float64(i)*(dotRadius*2+dotSpacing), 49*scale, dotRadius, dots)
+	}
+	return img
+}
+
+// newImage returns an icon image of the given size filled with the background color.
+func newImage(size int, background color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i] = background.R
+		img.Pix[i+1] = background.G
+		img.Pix[i+2] = background.B
+		img.Pix[i+3] = background.A
+	}
+	return img
+}
+
+// fitFontFace returns the first face in which the text fits the width, or the last one.
+func fitFontFace(text string, maxWidth float64, faces ...font.Face) font.Face {
+	for _, face := range faces {
+		bounds, _ := font.BoundString(face, text)
+		if float64((bounds.Max.X - bounds.Min.X).Ceil()) <= maxWidth {
+			return face
+		}
+	}
+	return faces[len(faces)-1]
+}
+
+// drawCircle draws a filled circle centered on (x, y) on the image.
+func drawCircle(img *image.RGBA, x, y, radius float64, col color.RGBA) {
+	for py := int(y - radius); py <= int(y+radius); py++ {
+		for px := int(x - radius); px <= int(x+radius); px++ {
+			dx, dy := float64(px)-x, float64(py)-y
+			if dx*dx+dy*dy <= radius*radius {
+				img.SetRGBA(px, py, col)
+			}
+		}
+	}
+}
+
+// blendColor mixes b into a by the given weight, including the alpha channel.
+func blendColor(a, b color.RGBA, weight float64) color.RGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(float64(x)*(1-weight) + float64(y)*weight)
+	}
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), mix(a.A, b.A)}
+}
@@ -0,0 +1,44 @@
+package icon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/png"
+)
+
+// icoEntry is an image of an ICO file.
+type icoEntry struct {
+	size int    // Width and height in pixels
+	png  []byte // Image encoded as PNG
+}
+
+// encodePNG encodes an icon image as PNG.
+func encodePNG(img image.Image) []byte {
+	var pngBuf bytes.Buffer
+	if err := png.Encode(&pngBuf, img); err != nil {
+		return []byte{0x00}
+	}
+	return pngBuf.Bytes()
+}
+
+// encodeICO encodes square images of up to 256 pixels as an ICO file with
+// PNG-compressed entries.
+func encodeICO(entries []icoEntry) []byte {
+	const headerSize = 6
+	const entrySize = 16
+
+	var header, data bytes.Buffer
+	binary.Write(&header, binary.LittleEndian, [3]uint16{0, 1, uint16(len(entries))}) // Reserved, type (icon), count
+	offset := headerSize + entrySize*len(entries)
+	for _, entry := range entries {
+		size := uint8(entry.size) // 0 means 256
+		binary.Write(&header, binary.LittleEndian, struct {
+			Width, Height, Colors, Reserved uint8
+			Planes, BitCount                uint16
+			Size, Offset                    uint32
+		}{size, size, 0, 0, 1, 32, uint32(len(entry.png)), uint32(offset + data.Len())})
+		data.Write(entry.png)
+	}
+	return append(header.Bytes(), data.Bytes()...)
+}
@@ -0,0 +1,115 @@
+package icon
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Layout of the heatmap in pixels.
+const (
+	heatmapCell   = 11 // Width and height of a day
+	heatmapGap    = 3  // Space between days
+	heatmapMargin = 12 // Space around the map
+	heatmapLeft   = 30 // Width of the weekday labels
+	heatmapTop    = 38 // Height of the title and month labels
+	heatmapBottom = 24 // Height of the legend
+	heatmapWeeks  = 53 // Weeks shown, a year
+	heatmapLevels = 4  // Shades of days with Pomodoros
+)
+
+// HeatmapDateLayout is the layout of the dates keying the counts of Heatmap.
+const HeatmapDateLayout = "2006-01-02"
+
+// Heatmap draws the counts per day of the year up to end as a PNG grid of
+// weeks like the GitHub contribution graph, one column per week from Sunday
+// to Saturday. Counts are keyed by HeatmapDateLayout dates. Days are shaded
+// from the background towards the dot color of the palette, relative to the
+// busiest day; the labels are in the text color.
+func Heatmap(counts map[string]int, end time.Time, palette Palette) []byte {
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, end.Location())
+	start := end.AddDate(0, 0, -int(end.Weekday())-7*(heatmapWeeks-1))
+
+	total, busiest := 0, 0
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		n := counts[day.Format(HeatmapDateLayout)]
+		total += n
+		busiest = max(busiest, n)
+	}
+
+	step := heatmapCell + heatmapGap
+	width := 2*heatmapMargin + heatmapLeft + heatmapWeeks*step - heatmapGap
+	height := 2*heatmapMargin + heatmapTop + 7*step - heatmapGap + heatmapBottom
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fill(img, img.Bounds(), palette.Background)
+
+	shades := make([]color.RGBA, heatmapLevels+1)
+	shades[0] = blendColor(palette.Background, palette.Text, 0.08)
+	for i := 1; i <= heatmapLevels; i++ {
+		shades[i] = blendColor(shades[0], palette.Dots, float64(i)/heatmapLevels)
+	}
+
+	drawLabel(img, heatmapMargin, heatmapMargin+10, fmt.Sprintf("%d Pomodoros in the last year", total), palette.Text)
+	for _, row := range []int{1, 3, 5} {
+		label := time.Weekday(row).String()[:3]
+		drawLabel(img, heatmapMargin, heatmapMargin+heatmapTop+row*step+heatmapCell-1, label, palette.Text)
+	}
+
+	left := heatmapMargin + heatmapLeft
+	top := heatmapMargin + heatmapTop
+	month := time.Month(0)
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		week := int(day.Sub(start).Hours()/24+0.5) / 7
+		x, y := left+week*step, top+int(day.Weekday())*step
+		if day.Weekday() == time.Sunday && day.Month() != month {
+			month = day.Month()
+			if week < heatmapWeeks-2 {
+				drawLabel(img, x, top-6, month.String()[:3], palette.Text)
+			}
+		}
+		level := 0
+		if n := counts[day.Format(HeatmapDateLayout)]; n > 0 {
+			level = int(math.Ceil(float64(n) * heatmapLevels / float64(busiest)))
+		}
+		fill(img, image.Rect(x, y, x+heatmapCell, y+heatmapCell), shades[level])
+	}
+
+	// Legend in the bottom right corner: Less, the shades, More
+	y := height - heatmapMargin - heatmapCell
+	x := width - heatmapMargin - 4*7 - (heatmapLevels+1)*step
+	drawLabel(img, x-4*7-4, y+heatmapCell-1, "Less", palette.Text)
+	for _, shade := range shades {
+		fill(img, image.Rect(x, y, x+heatmapCell, y+heatmapCell), shade)
+		x += step
+	}
+	drawLabel(img, x+2, y+heatmapCell-1, "More", palette.Text)
+
+	return encodePNG(img)
+}
+
+// fill fills a rectangle of the image with a color.
+func fill(img *image.RGBA, r image.Rectangle, col color.RGBA) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetRGBA(x, y, col)
+		}
+	}
+}
+
+// drawLabel draws text in the small bitmap font with its baseline at y. The
+// icon font only has digits.
+func drawLabel(img *image.RGBA, x, y int, text string, col color.RGBA) {
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(col),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.Point26_6{X: fixed.I(x), Y: fixed.I(y)},
+	}
+	d.DrawString(text)
+}
@@ -0,0 +1,169 @@
+// Package icon draws the tray icon of the timer: the remaining time with the
+// Pomodoro count as dots, or a depleting progress ring or pie. Icons are
+// cached as PNG, so the icon shown every second is only drawn and encoded
+// when it changes, and the icons of a session can be rendered ahead of time.
+// The package also draws the heatmap of Pomodoros per day.
+package icon
+
+import (
+	"image"
+	"image/color"
+	"log/slog"
+	"math"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/opentype"
+)
+
+// Style is the way an icon shows the remaining time.
+type Style string
+
+// Icon styles.
+const (
+	Digits Style = "digits" // Remaining time with the Pomodoro count as large dots below it
+	Ring   Style = "ring"   // Remaining time in a depleting ring
+	Pie    Style = "pie"    // Remaining time on a depleting pie
+)
+
+// ProgressSteps is the resolution of the progress shown by the ring and pie.
+// Progress is rounded to it, so the icon only changes every few seconds.
+const ProgressSteps = 240
+
+// maxCached is the number of icons kept; the cache is cleared when it is full.
+const maxCached = 512
+
+// Palette holds the colors an icon is drawn with.
+type Palette struct {
+	Background color.RGBA
+	Text       color.RGBA
+	Dots       color.RGBA
+	Template   bool // Monochrome template image for the macOS menu bar
+}
+
+// TemplatePalette draws black on transparent; macOS tints template images to
+// match the menu bar.
+var TemplatePalette = Palette{Text: color.RGBA{0, 0, 0, 255}, Dots: color.RGBA{0, 0, 0, 255}, Template: true}
+
+// Spec describes an icon. Specs are comparable and identify cached icons.
+type Spec struct {
+	Style    Style
+	Palette  Palette
+	Size     int     // Width and height in pixels
+	Text     string  // Remaining time, or ▶ while stopped
+	Dots     int     // Pomodoros completed in the cycle
+	Progress float64 // Fraction of the session left, for the ring and pie
+}
+
+// normalize rounds the progress to ProgressSteps and drops it for styles
+// that do not show it, so equal icons have equal specs.
+func (s Spec) normalize() Spec {
+	switch s.Style {
+	case Ring, Pie:
+		s.Progress = math.Round(math.Max(0, math.Min(1, s.Progress))*ProgressSteps) / ProgressSteps
+		if s.Style == Pie && s.Palette.Template {
+			s.Style = Ring // A template pie would hide the text, as everything is drawn in black
+		}
+	default:
+		s.Style = Digits
+		s.Progress = 0
+	}
+	return s
+}
+
+// Same reports whether two lists of specs describe the same icons, so an
+// icon can be skipped before it is encoded, e.g. while the ring only
+// changes every few seconds.
+func Same(a, b []Spec) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].normalize() != b[i].normalize() {
+			return false
+		}
+	}
+	return true
+}
+
+// Renderer draws icons and caches them. Its methods are safe for concurrent use.
+type Renderer struct {
+	mu    sync.Mutex
+	font  *opentype.Font
+	faces map[float64]font.Face // Font faces by point size
+	cache map[Spec][]byte       // PNG icons by normalized spec
+}
+
+// New returns a renderer drawing the text in the font, or in a small bitmap
+// font if it is nil.
+func New(f *opentype.Font) *Renderer {
+	return &Renderer{font: f, faces: map[float64]font.Face{}, cache: map[Spec][]byte{}}
+}
+
+// PNG returns the icon encoded as PNG, drawing it unless it is cached.
+func (r *Renderer) PNG(s Spec) []byte {
+	s = s.normalize()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if data, ok := r.cache[s]; ok {
+		return data
+	}
+	if len(r.cache) >= maxCached {
+		r.cache = map[Spec][]byte{}
+	}
+	data := encodePNG(r.draw(s))
+	r.cache[s] = data
+	return data
+}
+
+// ICO returns the icons, which must be square and at most 256 pixels, as a
+// multi-size ICO file.
+func (r *Renderer) ICO(specs []Spec) []byte {
+	entries := make([]icoEntry, len(specs))
+	for i, s := range specs {
+		entries[i] = icoEntry{size: s.Size, png: r.PNG(s)}
+	}
+	return encodeICO(entries)
+}
+
+// Prerender draws the icons into the cache ahead of time, e.g. every minute
+// of a session that is starting. It is meant to run in the background.
+func (r *Renderer) Prerender(specs []Spec) {
+	for _, s := range specs {
+		r.PNG(s)
+	}
+}
+
+// draw draws the icon of a normalized spec. The caller must hold r.mu.
+func (r *Renderer) draw(s Spec) *image.RGBA {
+	switch s.Style {
+	case Ring, Pie:
+		return r.drawProgress(s)
+	default:
+		return r.drawDigits(s)
+	}
+}
+
+// fontFace returns the icon font at the given point size, creating it on
+// first use. The caller must hold r.mu.
+func (r *Renderer) fontFace(points float64) font.Face {
+	points = math.Round(points*2) / 2 // Limit the number of cached faces
+	if face, ok := r.faces[points]; ok {
+		return face
+	}
+	if r.font == nil {
+		return basicfont.Face7x13
+	}
+	face, err := opentype.NewFace(r.font, &opentype.FaceOptions{
+		Size:    points,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		slog.Error("Error creating font face", "err", err)
+		return basicfont.Face7x13
+	}
+	r.faces[points] = face
+	return face
+}
@@ -0,0 +1,44 @@
+package pomodoro
+
+import "time"
+
+// Clock tells the time and makes tickers for an Engine. The system clock is
+// used unless another one is given to New, e.g. a fake one in tests.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks at intervals, like time.Ticker.
+type Ticker interface {
+	C() <-chan time.Time // Channel the ticks are delivered on
+	Stop()
+}
+
+// systemClock is the Clock of the system.
+type systemClock struct{}
+
+// Now returns the current time.
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTicker returns a time.Ticker.
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+// systemTicker is a time.Ticker as a Ticker.
+type systemTicker struct {
+	ticker *time.Ticker
+}
+
+// C returns the channel of the ticker.
+func (t systemTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+// Stop stops the ticker.
+func (t systemTicker) Stop() {
+	t.ticker.Stop()
+}
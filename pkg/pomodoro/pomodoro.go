@@ -0,0 +1,434 @@
+// Package pomodoro implements the timer of the Pomodoro Technique: Pomodoros
+// and breaks counting down one second at a time, pausing, and the cycle of
+// four Pomodoros ending in a long break. It has no user interface, so tray
+// apps, command-line tools and servers can share it.
+package pomodoro
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Kind is the type of a session.
+type Kind string
+
+// Session kinds.
+const (
+	Pomodoro Kind = "pomodoro" // Focused work
+	Break    Kind = "break"    // Short or long break
+)
+
+// CycleLength is the number of Pomodoros followed by a long break.
+const CycleLength = 4
+
+// State is the state of the timer.
+type State int
+
+// Timer states.
+const (
+	Stopped State = iota // No session is running
+	Running              // A session is counting down
+	Paused               // A session is running but not counting down
+)
+
+// String returns the name of the state: "stopped", "running" or "paused".
+func (s State) String() string {
+	switch s {
+	case Running:
+		return "running"
+	case Paused:
+		return "paused"
+	}
+	return "stopped"
+}
+
+// Session is a Pomodoro or break.
+type Session struct {
+	Kind      Kind
+	Start     time.Time
+	Duration  time.Duration // Length of the session, including extensions
+	Remaining time.Duration // Time left
+	Paused    time.Duration // Time the session was paused
+}
+
+// Events are the callbacks of an Engine. They are called from a goroutine of
+// Run with the engine's lock held, so they may call the engine's methods but
+// must not lock it. Nil callbacks are skipped.
+type Events struct {
+	Tick       func(s Session) // A second of the running session passed
+	PausedTick func(s Session) // A second passed while the session was paused
+	Finished   func(s Session) // The session ran out; the engine is already stopped
+}
+
+// command is an input of the state machine of an Engine.
+type command int
+
+// Commands of the state machine.
+const (
+	cmdStart  command = iota // Start a session, replacing the running one
+	cmdStop                  // Stop the running session early
+	cmdPause                 // Pause the running session
+	cmdResume                // Continue the paused session
+	cmdExtend                // Add time to the running session
+	cmdFinish                // The running session ran out
+)
+
+// transitions lists the states each command is accepted in, and the state it
+// leads to. Commands in other states are ignored.
+var transitions = map[command]map[State]State{
+	cmdStart:  {Stopped: Running, Running: Running, Paused: Running},
+	cmdStop:   {Running: Stopped, Paused: Stopped},
+	cmdPause:  {Running: Paused},
+	cmdResume: {Paused: Running},
+	cmdExtend: {Running: Running, Paused: Paused},
+	cmdFinish: {Running: Stopped},
+}
+
+// request is a command sent to the goroutine running the engine, which runs
+// it and closes done.
+type request struct {
+	run  func()
+	done chan struct{}
+}
+
+// Engine is a Pomodoro timer. Its state is owned by the goroutine running Run:
+// the methods send it a request and wait for the reply, and its changes go
+// through the commands of a state machine, see transitions. The ticks are
+// handed to another goroutine of Run, which calls the events with the lock
+// held, see New.
+type Engine struct {
+	Events
+
+	locker   sync.Locker
+	clock    Clock
+	requests chan request  // Requests Run has to answer
+	ticks    chan struct{} // Ticks of the running session waiting for their event
+	done     chan struct{} // Closed when Run returns
+
+	// Only touched by Run
+	state    State
+	kind     Kind // Kind of the running session, or of the last one while stopped
+	count    int  // Pomodoros completed in the cycle, 0 to CycleLength
+	session  Session
+	deadline time.Time // End of the running session, while it is not paused
+	pausedAt time.Time // Start of the pause, while paused
+	ticker   Ticker    // Ticker of the running session, nil while stopped
+}
+
+// New returns a stopped engine whose next session is a Pomodoro. The events
+// are called with locker held, so the state of the app embedding the engine
+// can be kept consistent with them; if locker is nil, the engine has its own
+// mutex. The engine tells the time with clock, or the system clock if nil.
+// The methods wait for Run, so it must be running before they are called.
+func New(locker sync.Locker, clock Clock) *Engine {
+	if locker == nil {
+		locker = &sync.Mutex{}
+	}
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return &Engine{
+		locker:   locker,
+		clock:    clock,
+		requests: make(chan request),
+		ticks:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		kind:     Break,
+	}
+}
+
+// Run answers the requests of the methods and counts the sessions down,
+// calling the events, until ctx is done. A session running then stops
+// counting down, and the methods return zero values; Run must not be called
+// again. It is called without the lock held, usually in a goroutine of its own.
+func (e *Engine) Run(ctx context.Context) {
+	defer close(e.done)
+	go e.deliverTicks(ctx)
+	for {
+		var tick <-chan time.Time
+		if e.ticker != nil {
+			tick = e.ticker.C()
+		}
+		select {
+		case <-ctx.Done():
+			e.stopTicker()
+			return
+		case r := <-e.requests:
+			r.run()
+			close(r.done)
+		case <-tick:
+			select {
+			case e.ticks <- struct{}{}:
+			default: // The last tick is not delivered yet; it reads the time anew
+			}
+		}
+	}
+}
+
+// deliverTicks turns the ticks into events until ctx is done. The tick is
+// applied with the lock held, so the session ending and its event are one
+// step for the app.
+func (e *Engine) deliverTicks(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.ticks:
+		}
+		e.Lock()
+		var event func(Session)
+		var s Session
+		e.do(func() { event, s = e.tick() })
+		if event != nil {
+			event(s)
+		}
+		e.Unlock()
+	}
+}
+
+// do sends f to Run and waits until it has run, or until Run has returned.
+func (e *Engine) do(f func()) {
+	r := request{run: f, done: make(chan struct{})}
+	select {
+	case e.requests <- r:
+		<-r.done
+	case <-e.done:
+	}
+}
+
+// Lock locks the engine.
+func (e *Engine) Lock() {
+	e.locker.Lock()
+}
+
+// Unlock unlocks the engine.
+func (e *Engine) Unlock() {
+	e.locker.Unlock()
+}
+
+// State returns the state of the timer.
+func (e *Engine) State() State {
+	var state State
+	e.do(func() { state = e.state })
+	return state
+}
+
+// Running reports whether a session is running, paused or not.
+func (e *Engine) Running() bool {
+	return e.State() != Stopped
+}
+
+// Paused reports whether the running session is paused.
+func (e *Engine) Paused() bool {
+	return e.State() == Paused
+}
+
+// Kind returns the kind of the running session, or of the last one while stopped.
+func (e *Engine) Kind() Kind {
+	var kind Kind
+	e.do(func() { kind = e.kind })
+	return kind
+}
+
+// SetKind sets the kind of the last session while stopped, which decides
+// the kind of the next one.
+func (e *Engine) SetKind(kind Kind) {
+	e.do(func() {
+		if e.state == Stopped {
+			e.kind = kind
+		}
+	})
+}
+
+// InPomodoro reports whether the running or last session is a Pomodoro.
+func (e *Engine) InPomodoro() bool {
+	return e.Kind() == Pomodoro
+}
+
+// Next returns the kind of the session following the running or last one.
+func (e *Engine) Next() Kind {
+	if e.Kind() == Pomodoro {
+		return Break
+	}
+	return Pomodoro
+}
+
+// Count returns the number of Pomodoros completed in the cycle, from 0 to CycleLength.
+func (e *Engine) Count() int {
+	var count int
+	e.do(func() { count = e.count })
+	return count
+}
+
+// SetCount sets the number of Pomodoros completed in the cycle, e.g. to
+// restore it. Values outside 0 to CycleLength are ignored.
+func (e *Engine) SetCount(count int) {
+	e.do(func() {
+		if count >= 0 && count <= CycleLength {
+			e.count = count
+		}
+	})
+}
+
+// LongBreakNext reports whether the next break is a long one, as the
+// Pomodoros of the cycle are completed.
+func (e *Engine) LongBreakNext() bool {
+	return e.Count() == CycleLength
+}
+
+// Session returns the running session, or the last one while stopped.
+func (e *Engine) Session() Session {
+	var s Session
+	e.do(func() { s = e.session })
+	return s
+}
+
+// Remaining returns the time left of the running session.
+func (e *Engine) Remaining() time.Duration {
+	var remaining time.Duration
+	e.do(func() {
+		if e.state != Stopped {
+			remaining = e.session.Remaining
+		}
+	})
+	return remaining
+}
+
+// Duration returns the length of the running session, including extensions.
+func (e *Engine) Duration() time.Duration {
+	var duration time.Duration
+	e.do(func() {
+		if e.state != Stopped {
+			duration = e.session.Duration
+		}
+	})
+	return duration
+}
+
+// Start starts a session of the given kind and duration. A running session
+// is stopped first, without an event.
+func (e *Engine) Start(kind Kind, duration time.Duration) {
+	e.do(func() { e.start(kind, duration) })
+}
+
+// Stop stops the running session early and reports whether one was running.
+func (e *Engine) Stop() bool {
+	var stopped bool
+	e.do(func() { stopped = e.stop() })
+	return stopped
+}
+
+// Pause pauses the running session.
+func (e *Engine) Pause() {
+	e.do(e.pause)
+}
+
+// Resume continues the paused session with the time it had left.
+func (e *Engine) Resume() {
+	e.do(e.resume)
+}
+
+// Extend adds time to the running session.
+func (e *Engine) Extend(d time.Duration) {
+	e.do(func() { e.extend(d) })
+}
+
+// transition moves the state machine by the command and reports whether
+// the command was accepted in the current state.
+func (e *Engine) transition(cmd command) bool {
+	next, ok := transitions[cmd][e.state]
+	if ok {
+		e.state = next
+	}
+	return ok
+}
+
+// start starts a session, see Start.
+func (e *Engine) start(kind Kind, duration time.Duration) {
+	e.stop()
+	e.transition(cmdStart)
+	now := e.clock.Now()
+	e.kind = kind
+	e.session = Session{Kind: kind, Start: now, Duration: duration, Remaining: duration}
+	e.deadline = now.Add(duration)
+	e.ticker = e.clock.NewTicker(time.Second)
+}
+
+// stop stops the running session, see Stop.
+func (e *Engine) stop() bool {
+	if !e.transition(cmdStop) {
+		return false
+	}
+	e.stopTicker()
+	return true
+}
+
+// pause pauses the running session, see Pause.
+func (e *Engine) pause() {
+	if e.transition(cmdPause) {
+		e.pausedAt = e.clock.Now()
+	}
+}
+
+// resume continues the paused session, see Resume.
+func (e *Engine) resume() {
+	if e.transition(cmdResume) {
+		now := e.clock.Now()
+		e.session.Paused += now.Sub(e.pausedAt).Round(time.Second)
+		e.deadline = now.Add(e.session.Remaining)
+	}
+}
+
+// extend adds time to the running session, see Extend.
+func (e *Engine) extend(d time.Duration) {
+	if e.transition(cmdExtend) {
+		e.session.Remaining += d
+		e.session.Duration += d
+		e.deadline = e.deadline.Add(d)
+	}
+}
+
+// stopTicker stops the ticker of the session that ended.
+func (e *Engine) stopTicker() {
+	if e.ticker != nil {
+		e.ticker.Stop()
+		e.ticker = nil
+	}
+}
+
+// tick updates the time left of the running session and returns the event
+// to call with the session, or nil if there is none. The time left is taken
+// from the deadline rather than counted down, so late ticks, e.g. after the
+// computer slept, do not make the session longer; the seconds in between are
+// skipped.
+func (e *Engine) tick() (func(Session), Session) {
+	now := e.clock.Now()
+	switch e.state {
+	case Stopped:
+		return nil, Session{} // Stopped after the tick was handed over
+	case Paused:
+		s := e.session
+		s.Paused += now.Sub(e.pausedAt).Round(time.Second)
+		return e.PausedTick, s
+	}
+
+	remaining := e.deadline.Sub(now).Round(time.Second)
+	if remaining > 0 {
+		if remaining == e.session.Remaining {
+			return nil, Session{} // Less than a second passed
+		}
+		e.session.Remaining = remaining
+		return e.Tick, e.session
+	}
+	e.session.Remaining = 0
+	e.transition(cmdFinish)
+	e.stopTicker()
+	if e.kind == Pomodoro {
+		e.count++
+		if e.count > CycleLength {
+			e.count = 1
+		}
+	}
+	return e.Finished, e.session
+}
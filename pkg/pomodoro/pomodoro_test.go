@@ -0,0 +1,460 @@
+package pomodoro
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock whose time only moves when advanced.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+// fakeTicker is a Ticker of a fakeClock.
+type fakeTicker struct {
+	c chan time.Time
+}
+
+// Now returns the time of the clock.
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTicker returns a ticker ticking when the clock is advanced.
+func (c *fakeClock) NewTicker(time.Duration) Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTicker{c: make(chan time.Time, 1)}
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+// C returns the channel of the ticker.
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.c
+}
+
+// Stop does nothing: ticks of stopped tickers are dropped, as nobody reads them.
+func (t *fakeTicker) Stop() {}
+
+// advance moves the time forward by d and delivers a tick, dropped like the
+// ticks of time.Ticker if the previous one was not read yet.
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	for _, t := range c.tickers {
+		select {
+		case t.c <- c.now:
+		default:
+		}
+	}
+}
+
+// harness runs an engine on a fake clock and records its events.
+type harness struct {
+	t      *testing.T
+	clock  *fakeClock
+	engine *Engine
+	events chan string
+	last   Session // Session of the last event
+}
+
+// newHarness returns a harness with a stopped engine, running until the test ends.
+func newHarness(t *testing.T) *harness {
+	h := &harness{
+		t:      t,
+		clock:  &fakeClock{now: time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)},
+		events: make(chan string, 100),
+	}
+	h.engine = New(nil, h.clock)
+	record := func(name string) func(Session) {
+		return func(s Session) {
+			h.last = s
+			h.events <- name
+		}
+	}
+	h.engine.Tick = record("tick")
+	h.engine.PausedTick = record("paused")
+	h.engine.Finished = record("finished")
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go h.engine.Run(ctx)
+	return h
+}
+
+// do runs f with the engine locked.
+func (h *harness) do(f func(e *Engine)) {
+	h.engine.Lock()
+	defer h.engine.Unlock()
+	f(h.engine)
+}
+
+// step advances the clock by d and returns the event it caused, or "" if
+// there was none.
+func (h *harness) step(d time.Duration) string {
+	h.t.Helper()
+	h.clock.advance(d)
+	timeout := time.Second
+	if !h.running() {
+		timeout = 50 * time.Millisecond
+	}
+	select {
+	case event := <-h.events:
+		return event
+	case <-time.After(timeout):
+		return ""
+	}
+}
+
+// running reports whether the engine has a session running.
+func (h *harness) running() bool {
+	h.engine.Lock()
+	defer h.engine.Unlock()
+	return h.engine.Running()
+}
+
+// expect steps the clock by d and fails unless the event is want.
+func (h *harness) expect(d time.Duration, want string) {
+	h.t.Helper()
+	if got := h.step(d); got != want {
+		h.t.Fatalf("after %v: got event %q, want %q", d, got, want)
+	}
+}
+
+// finish runs a session of the given kind to its end, one second at a time.
+func (h *harness) finish(kind Kind, duration time.Duration) {
+	h.t.Helper()
+	h.do(func(e *Engine) { e.Start(kind, duration) })
+	for remaining := duration - time.Second; remaining > 0; remaining -= time.Second {
+		h.expect(time.Second, "tick")
+		if h.last.Remaining != remaining {
+			h.t.Fatalf("remaining %v, want %v", h.last.Remaining, remaining)
+		}
+	}
+	h.expect(time.Second, "finished")
+}
+
+func TestNewEngine(t *testing.T) {
+	h := newHarness(t)
+	h.do(func(e *Engine) {
+		if e.State() != Stopped || e.Running() || e.Count() != 0 {
+			t.Errorf("new engine: state %v, count %d", e.State(), e.Count())
+		}
+		if e.Next() != Pomodoro {
+			t.Errorf("first session is a %s, want a Pomodoro", e.Next())
+		}
+		if e.Remaining() != 0 || e.Duration() != 0 {
+			t.Errorf("stopped engine has %v of %v left", e.Remaining(), e.Duration())
+		}
+	})
+}
+
+func TestCycle(t *testing.T) {
+	h := newHarness(t)
+	for i := 1; i <= CycleLength; i++ {
+		h.finish(Pomodoro, 3*time.Second)
+		h.do(func(e *Engine) {
+			if e.Count() != i {
+				t.Fatalf("after Pomodoro %d: count %d", i, e.Count())
+			}
+			if e.State() != Stopped || e.Next() != Break {
+				t.Fatalf("after Pomodoro %d: state %v, next %s", i, e.State(), e.Next())
+			}
+			if e.LongBreakNext() != (i == CycleLength) {
+				t.Fatalf("after Pomodoro %d: long break next %v", i, e.LongBreakNext())
+			}
+		})
+		h.finish(Break, 2*time.Second)
+		h.do(func(e *Engine) {
+			if e.Count() != i || e.Next() != Pomodoro {
+				t.Fatalf("after break %d: count %d, next %s", i, e.Count(), e.Next())
+			}
+		})
+	}
+
+	// The long break leaves the count at the end of the cycle; the next
+	// Pomodoro starts a new one.
+	h.finish(Pomodoro, 2*time.Second)
+	h.do(func(e *Engine) {
+		if e.Count() != 1 || e.LongBreakNext() {
+			t.Errorf("first Pomodoro of the next cycle: count %d", e.Count())
+		}
+	})
+}
+
+func TestStop(t *testing.T) {
+	h := newHarness(t)
+	h.do(func(e *Engine) { e.Start(Pomodoro, 5*time.Second) })
+	h.expect(time.Second, "tick")
+	h.do(func(e *Engine) {
+		if !e.Stop() {
+			t.Error("Stop of a running session returned false")
+		}
+		if e.Stop() {
+			t.Error("Stop of a stopped engine returned true")
+		}
+		if e.State() != Stopped || e.Count() != 0 || e.Remaining() != 0 {
+			t.Errorf("after stop: state %v, count %d, %v left", e.State(), e.Count(), e.Remaining())
+		}
+		if e.Next() != Break {
+			t.Errorf("after a stopped Pomodoro the next session is a %s", e.Next())
+		}
+	})
+	h.expect(10*time.Second, "")
+}
+
+func TestStartReplacesRunningSession(t *testing.T) {
+	h := newHarness(t)
+	h.do(func(e *Engine) { e.Start(Pomodoro, 3*time.Second) })
+	h.expect(time.Second, "tick")
+	h.do(func(e *Engine) { e.Start(Break, 10*time.Second) })
+	h.expect(time.Second, "tick")
+	if h.last.Kind != Break || h.last.Remaining != 9*time.Second {
+		t.Fatalf("tick of the %s with %v left, want the break with 9s", h.last.Kind, h.last.Remaining)
+	}
+	h.expect(5*time.Second, "tick")
+	if h.last.Remaining != 4*time.Second {
+		t.Errorf("%v left, want 4s", h.last.Remaining)
+	}
+	h.do(func(e *Engine) {
+		if e.Count() != 0 {
+			t.Errorf("the replaced Pomodoro was counted")
+		}
+	})
+}
+
+func TestPause(t *testing.T) {
+	h := newHarness(t)
+	h.do(func(e *Engine) { e.Start(Pomodoro, 5*time.Second) })
+	h.expect(time.Second, "tick")
+	h.do(func(e *Engine) {
+		e.Pause()
+		if !e.Paused() || !e.Running() || e.State() != Paused {
+			t.Fatalf("after pause: state %v", e.State())
+		}
+	})
+	for i := 1; i <= 3; i++ {
+		h.expect(time.Second, "paused")
+		if h.last.Remaining != 4*time.Second || h.last.Paused != time.Duration(i)*time.Second {
+			t.Fatalf("paused tick %d: %v left, paused %v", i, h.last.Remaining, h.last.Paused)
+		}
+	}
+	h.do(func(e *Engine) {
+		e.Resume()
+		if e.State() != Running || e.Session().Paused != 3*time.Second {
+			t.Fatalf("after resume: state %v, paused %v", e.State(), e.Session().Paused)
+		}
+	})
+	for remaining := 3 * time.Second; remaining > 0; remaining -= time.Second {
+		h.expect(time.Second, "tick")
+		if h.last.Remaining != remaining {
+			t.Fatalf("%v left, want %v", h.last.Remaining, remaining)
+		}
+	}
+	h.expect(time.Second, "finished")
+	if h.last.Paused != 3*time.Second {
+		t.Errorf("finished session paused %v, want 3s", h.last.Paused)
+	}
+}
+
+func TestPauseAndResumeWhileStopped(t *testing.T) {
+	h := newHarness(t)
+	h.do(func(e *Engine) {
+		e.Pause()
+		e.Resume()
+		e.Extend(time.Minute)
+		if e.State() != Stopped || e.Remaining() != 0 {
+			t.Errorf("stopped engine changed: state %v, %v left", e.State(), e.Remaining())
+		}
+	})
+}
+
+func TestExtend(t *testing.T) {
+	h := newHarness(t)
+	h.do(func(e *Engine) { e.Start(Pomodoro, 2*time.Second) })
+	h.expect(time.Second, "tick")
+	h.do(func(e *Engine) {
+		e.Extend(2 * time.Second)
+		if e.Remaining() != 3*time.Second || e.Duration() != 4*time.Second {
+			t.Fatalf("after extend: %v of %v left", e.Remaining(), e.Duration())
+		}
+	})
+	h.expect(time.Second, "tick")
+	h.expect(time.Second, "tick")
+	h.expect(time.Second, "finished")
+	if h.last.Duration != 4*time.Second {
+		t.Errorf("finished session lasted %v, want 4s", h.last.Duration)
+	}
+}
+
+func TestDrift(t *testing.T) {
+	h := newHarness(t)
+	h.do(func(e *Engine) { e.Start(Pomodoro, time.Minute) })
+
+	// Ticks arriving a little late or early do not add up.
+	for i := 1; i <= 10; i++ {
+		d := time.Second + 100*time.Millisecond
+		if i%2 == 0 {
+			d = time.Second - 100*time.Millisecond
+		}
+		h.expect(d, "tick")
+		if want := time.Minute - time.Duration(i)*time.Second; h.last.Remaining != want {
+			t.Fatalf("tick %d: %v left, want %v", i, h.last.Remaining, want)
+		}
+	}
+
+	// A tick less than a second after the last one is not an event.
+	h.expect(300*time.Millisecond, "")
+
+	// Late ticks, e.g. after the computer slept, skip the missed seconds.
+	h.expect(20*time.Second-300*time.Millisecond, "tick")
+	if h.last.Remaining != 30*time.Second {
+		t.Fatalf("after 20s without ticks: %v left, want 30s", h.last.Remaining)
+	}
+	h.expect(time.Hour, "finished")
+	h.do(func(e *Engine) {
+		if e.Count() != 1 {
+			t.Errorf("Pomodoro finished late was not counted")
+		}
+	})
+}
+
+func TestSetCountAndKind(t *testing.T) {
+	h := newHarness(t)
+	h.do(func(e *Engine) {
+		e.SetCount(3)
+		e.SetCount(CycleLength + 1)
+		e.SetCount(-1)
+		if e.Count() != 3 {
+			t.Errorf("count %d, want 3", e.Count())
+		}
+		e.SetKind(Pomodoro)
+		if e.Next() != Break {
+			t.Errorf("after a Pomodoro the next session is a %s", e.Next())
+		}
+		e.Start(Break, time.Minute)
+		e.SetKind(Pomodoro)
+		if e.Kind() != Break {
+			t.Errorf("SetKind changed the running session to a %s", e.Kind())
+		}
+		e.Stop()
+	})
+}
+
+func TestRunCancel(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)}
+	e := New(nil, clock)
+	ticks := make(chan Session, 10)
+	e.Tick = func(s Session) { ticks <- s }
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		e.Run(ctx)
+		close(done)
+	}()
+
+	e.Lock()
+	e.Start(Pomodoro, time.Minute)
+	e.Unlock()
+	clock.advance(time.Second)
+	select {
+	case <-ticks:
+	case <-time.After(time.Second):
+		t.Fatal("no tick while running")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return when the context was done")
+	}
+	clock.advance(time.Second)
+	select {
+	case s := <-ticks:
+		t.Errorf("tick with %v left after Run returned", s.Remaining)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if e.Running() || e.Remaining() != 0 {
+		t.Errorf("engine reports a session after Run returned")
+	}
+}
+
+func TestEventsCallMethods(t *testing.T) {
+	h := newHarness(t)
+	finished := h.engine.Finished
+	h.engine.Finished = func(s Session) {
+		if s.Kind == Pomodoro {
+			h.engine.Start(h.engine.Next(), 2*time.Second)
+		}
+		finished(s)
+	}
+	h.do(func(e *Engine) { e.Start(Pomodoro, time.Second) })
+	h.expect(time.Second, "finished")
+	h.expect(time.Second, "tick")
+	if h.last.Kind != Break {
+		t.Fatalf("tick of a %s, want the break started by the event", h.last.Kind)
+	}
+	h.expect(time.Second, "finished")
+}
+
+func TestConcurrentMethods(t *testing.T) {
+	h := newHarness(t)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.engine.Start(Pomodoro, time.Minute)
+			h.engine.Pause()
+			h.engine.Extend(time.Second)
+			h.engine.Resume()
+			h.engine.Stop()
+			h.engine.Session()
+		}()
+	}
+	wg.Wait()
+	if h.running() {
+		t.Errorf("a session is running after every one was stopped")
+	}
+}
+
+func TestTransitions(t *testing.T) {
+	h := newHarness(t)
+	h.do(func(e *Engine) {
+		for _, step := range []struct {
+			name string
+			do   func()
+			want State
+		}{
+			{"resume while stopped", e.Resume, Stopped},
+			{"start", func() { e.Start(Break, time.Minute) }, Running},
+			{"resume while running", e.Resume, Running},
+			{"pause", e.Pause, Paused},
+			{"pause while paused", e.Pause, Paused},
+			{"extend while paused", func() { e.Extend(time.Second) }, Paused},
+			{"start while paused", func() { e.Start(Pomodoro, time.Minute) }, Running},
+			{"stop", func() { e.Stop() }, Stopped},
+		} {
+			step.do()
+			if e.State() != step.want {
+				t.Fatalf("after %s: state %v, want %v", step.name, e.State(), step.want)
+			}
+		}
+	})
+}
+
+func TestStateString(t *testing.T) {
+	for state, want := range map[State]string{Stopped: "stopped", Running: "running", Paused: "paused"} {
+		if got := state.String(); got != want {
+			t.Errorf("%d.String() = %q, want %q", state, got, want)
+		}
+	}
+}